@@ -0,0 +1,245 @@
+package gologger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LogfileArchive uploads logfiles once rotation moved on from them, i.e.
+// LogfileName names a new file, e.g. a day later with {date}, replacing the
+// cron job doing it. Uploads run in the background, one at a time; Shutdown
+// waits for them.
+type LogfileArchive struct {
+	Uploader Uploader // e.g. NewS3Uploader
+
+	// Object key template with the placeholders of LogfileName, {date} being
+	// the day of the file's last write, and {file}, its name.
+	// "{hostname}/{file}" if empty.
+	Key string
+
+	Compress   bool          // gzip the file first, appending ".gz" to its name
+	KeepLocal  time.Duration // uploaded files stay on disk this long after their last write, deleted once uploaded if zero, kept if negative
+	MaxRetries int           // of a failed upload, backing off from a second to 5 minutes, 5 if zero
+}
+
+// Uploader stores a file under key, see LogfileArchive.
+type Uploader interface {
+	Upload(ctx context.Context, key string, body io.Reader, size int64) error
+}
+
+// archiveManifest lists the uploaded files of a directory kept for KeepLocal.
+const archiveManifest = ".archived"
+
+// archiveLogfile uploads the logfile name, which rotation closed, in the background.
+func (s *fileSink) archiveLogfile(name string) {
+	l, a := s.l, *s.l.LogfileArchive
+	l.archiving.Add(1)
+	go func() {
+		defer l.archiving.Done()
+		l.archiveMu.Lock()
+		defer l.archiveMu.Unlock()
+
+		err := a.archive(name)
+		if err != nil {
+			// logged from another goroutine, the daemon can't queue to itself
+			l.outputEntry(0, Entry{Level: 'E', Func: "gologger", Message: "Archiving logfile failed",
+				Fields: []Field{{"logfile", name}, {"error", err.Error()}}})
+		}
+	}()
+}
+
+// archiveLeftovers archives the logfiles of earlier runs next to current that
+// weren't uploaded, their process having stopped before rotating them. Only
+// files last written before today count, others may be of a process still
+// running.
+func (s *fileSink) archiveLeftovers(current string, now time.Time) {
+	template := s.l.LogfileName
+	if template == "" {
+		template = defaultLogfileName
+	}
+	template = s.part.expand(template)
+	pattern := logfileName(strings.NewReplacer("{date}", "*", "{pid}", "*").Replace(template), now)
+	dir := filepath.Dir(current)
+	uploaded := readArchiveManifest(dir)
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+
+	for _, glob := range []string{pattern, pattern + ".gz"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, filepath.Base(glob)))
+		for _, name := range matches {
+			if name == current || slices.Contains(uploaded, filepath.Base(name)) {
+				continue
+			}
+			if info, err := os.Stat(name); err == nil && info.Mode().IsRegular() && info.ModTime().Before(today) {
+				s.archiveLogfile(name)
+			}
+		}
+	}
+}
+
+func (a LogfileArchive) archive(name string) error {
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	if a.Compress && !strings.HasSuffix(name, ".gz") {
+		name, err = gzipFile(name)
+		if err != nil {
+			return fmt.Errorf("compressing: %w", err)
+		}
+		info, err = os.Stat(name)
+		if err != nil {
+			return err
+		}
+	}
+
+	template := a.Key
+	if template == "" {
+		template = "{hostname}/{file}"
+	}
+	key := logfileName(strings.ReplaceAll(template, "{file}", filepath.Base(name)), info.ModTime())
+
+	retries := a.MaxRetries
+	if retries == 0 {
+		retries = 5
+	}
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		err = a.upload(name, key, info.Size())
+		if err == nil || attempt >= retries || errors.As(err, &permanentError{}) {
+			break
+		}
+		time.Sleep(backoff)
+		backoff = min(2*backoff, 5*time.Minute)
+	}
+	if err != nil {
+		return fmt.Errorf("uploading %s: %w", key, err)
+	}
+
+	switch {
+	case a.KeepLocal == 0:
+		return os.Remove(name)
+	case a.KeepLocal > 0:
+		return pruneArchived(filepath.Dir(name), name, a.KeepLocal)
+	}
+	return nil
+}
+
+func (a LogfileArchive) upload(name, key string, size int64) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return Permanent(err)
+	}
+	defer f.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	return a.Uploader.Upload(ctx, key, f, size)
+}
+
+// gzipFile compresses name to name.gz, removing name, and returns the new name.
+func gzipFile(name string) (string, error) {
+	in, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	compressed := name + ".gz"
+	out, err := os.OpenFile(compressed, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return "", err
+	}
+	zw := gzip.NewWriter(out)
+	zw.Name, zw.ModTime = filepath.Base(name), info.ModTime()
+	_, err = io.Copy(zw, in)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(compressed)
+		return "", err
+	}
+	// the upload goes by the time of the last entry
+	_ = os.Chtimes(compressed, info.ModTime(), info.ModTime())
+	return compressed, os.Remove(name)
+}
+
+// pruneArchived records uploaded in the manifest of dir and deletes the files
+// listed there whose last write is more than keep ago.
+func pruneArchived(dir, uploaded string, keep time.Duration) error {
+	names := []string{filepath.Base(uploaded)}
+	for _, name := range readArchiveManifest(dir) {
+		if name != names[0] {
+			names = append(names, name)
+		}
+	}
+
+	var kept []byte
+	for _, name := range names {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			continue // deleted by hand
+		}
+		if time.Since(info.ModTime()) > keep && os.Remove(filepath.Join(dir, name)) == nil {
+			continue
+		}
+		kept = append(append(kept, name...), '\n')
+	}
+	tmp := filepath.Join(dir, archiveManifest+".tmp")
+	err := os.WriteFile(tmp, kept, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, archiveManifest))
+}
+
+// readArchiveManifest returns the names of the uploaded files kept in dir.
+func readArchiveManifest(dir string) []string {
+	f, err := os.Open(filepath.Join(dir, archiveManifest))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if name := scanner.Text(); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// waitArchiving waits for running uploads of LogfileArchive, or until ctx is done.
+func (l *Log) waitArchiving(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		l.archiving.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
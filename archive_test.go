@@ -0,0 +1,86 @@
+package gologger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+type memoryUploader map[string][]byte
+
+func (u memoryUploader) Upload(ctx context.Context, key string, body io.Reader, size int64) error {
+	b, err := io.ReadAll(body)
+	u[key] = b
+	return err
+}
+
+func TestLogfileArchive(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app_2024-05-01.log")
+	if err := os.WriteFile(name, []byte("hello\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	uploaded := memoryUploader{}
+	a := LogfileArchive{Uploader: uploaded, Key: "logs/{file}", Compress: true, KeepLocal: time.Hour}
+	if err := a.archive(name); err != nil {
+		t.Fatal(err)
+	}
+
+	b, ok := uploaded["logs/app_2024-05-01.log.gz"]
+	if !ok {
+		t.Fatalf("uploaded %v, want logs/app_2024-05-01.log.gz", uploaded)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := io.ReadAll(zr); string(content) != "hello\n" {
+		t.Errorf("uploaded %q, want %q", content, "hello\n")
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("the uncompressed file is still there: %v", err)
+	}
+	if got := readArchiveManifest(dir); !slices.Equal(got, []string{"app_2024-05-01.log.gz"}) {
+		t.Errorf("manifest lists %q", got)
+	}
+
+	// past KeepLocal, the next upload deletes it
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(name+".gz", old, old); err != nil {
+		t.Fatal(err)
+	}
+	next := filepath.Join(dir, "app_2024-05-02.log")
+	if err := os.WriteFile(next, []byte("again\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a.Compress = false
+	if err := a.archive(next); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name + ".gz"); !os.IsNotExist(err) {
+		t.Errorf("the file past KeepLocal is still there: %v", err)
+	}
+	if got := readArchiveManifest(dir); !slices.Equal(got, []string{"app_2024-05-02.log"}) {
+		t.Errorf("manifest lists %q", got)
+	}
+
+	// without KeepLocal it goes right away
+	a.KeepLocal = 0
+	last := filepath.Join(dir, "app_2024-05-03.log")
+	if err := os.WriteFile(last, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.archive(last); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(last); !os.IsNotExist(err) {
+		t.Errorf("uploaded file is still there: %v", err)
+	}
+}
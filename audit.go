@@ -0,0 +1,189 @@
+package gologger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// AuditLevel is the level of AUDIT entries. It is never filtered or dropped.
+const AuditLevel = 'A'
+
+func init() {
+	customLevels[AuditLevel].Store(&Level{Tag: AuditLevel, Name: "audit", Priority: LOG_NOTICE, Always: true})
+}
+
+// AUDIT records that actor did action to target, with key, value pairs for
+// details. Besides the usual sinks it reaches any AuditSink.
+func (l *Log) AUDIT(actor, action, target string, keyvals ...interface{}) {
+	pc, line := l.caller(1)
+
+	fields := make([]Field, 0, 3+(len(keyvals)+1)/2)
+	fields = append(fields, Field{"actor", actor}, Field{"action", action}, Field{"target", target})
+	fields = append(fields, keyValsToFields(keyvals)...)
+
+	l.outputEntry(pc, Entry{Level: AuditLevel, Line: line, Message: actor + " " + action + " " + target, Fields: fields})
+}
+
+// AuditSink appends AUDIT entries, and only those, to a file as JSON lines
+// chained by SHA-256: each record holds the hash of the one before it, so
+// changing, inserting or removing a record breaks every hash after it.
+// Removing the last records leaves a valid chain though; keep the hash of
+// the last record elsewhere to detect that. VerifyAuditLog checks a file.
+type AuditSink struct {
+	file *os.File
+	prev string // hash of the last record, auditGenesis in a new file
+	buf  []byte
+}
+
+// The prev of the first record in a file.
+const auditGenesis = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// NewAuditSink opens path for appending, continuing the chain of the records already in it.
+func NewAuditSink(path string) (*AuditSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+
+	prev, err := lastAuditHash(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("reading audit log %s: %w", path, err)
+	}
+	return &AuditSink{file: file, prev: prev}, nil
+}
+
+// lastAuditHash reads the hash of the last record from the end of f.
+func lastAuditHash(f *os.File) (string, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := fi.Size()
+	if size == 0 {
+		return auditGenesis, nil
+	}
+
+	const tail = 1 << 20
+	offset := size - tail
+	if offset < 0 {
+		offset = 0
+	}
+	b := make([]byte, size-offset)
+	_, err = f.ReadAt(b, offset)
+	if err != nil {
+		return "", err
+	}
+
+	b = bytes.TrimRight(b, "\n")
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		b = b[i+1:]
+	}
+	var record struct {
+		Hash string `json:"hash"`
+	}
+	err = json.Unmarshal(b, &record)
+	if err != nil || record.Hash == "" {
+		return "", fmt.Errorf("last record has no hash, not continuing the chain")
+	}
+	return record.Hash, nil
+}
+
+func (s *AuditSink) Write(entry Entry) error {
+	if entry.Level != AuditLevel {
+		return nil
+	}
+
+	b := s.buf[:0]
+	b = append(b, `{"ts":"`...)
+	b = entry.Time.UTC().AppendFormat(b, time.RFC3339Nano)
+	b = append(b, '"')
+	if entry.Logger != "" {
+		b = append(b, `,"logger":`...)
+		b = appendJSONString(b, entry.Logger)
+	}
+	for _, f := range entry.Fields {
+		b = append(b, ',')
+		b = appendJSONString(b, f.Key)
+		b = append(b, ':')
+		b = appendJSONValue(b, f.Value)
+	}
+	b = append(b, `,"prev":"`...)
+	b = append(b, s.prev...)
+	b = append(b, `"}`...)
+
+	hash := auditHash(b)
+	b = append(b[:len(b)-1], `,"hash":"`...)
+	b = append(b, hash...)
+	b = append(b, "\"}\n"...)
+	s.buf = b
+
+	// one write per record, O_APPEND keeps it whole
+	_, err := s.file.Write(b)
+	if err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	s.prev = hash
+	return nil
+}
+
+// auditHash hashes a record as written, minus its hash field.
+func auditHash(record []byte) string {
+	sum := sha256.Sum256(record)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *AuditSink) Sync() error {
+	return s.file.Sync()
+}
+
+func (s *AuditSink) Close() error {
+	return s.file.Close()
+}
+
+// VerifyAuditLog checks the hash chain of an audit log written by AuditSink,
+// returning an error naming the first line that was changed, inserted or
+// removed. Records cut off the end go unnoticed, see AuditSink.
+func VerifyAuditLog(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+
+	prev := auditGenesis
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Bytes()
+
+		// the hash is the last field, everything before it is what was hashed
+		i := bytes.LastIndex(line, []byte(`,"hash":"`))
+		if i < 0 || !bytes.HasSuffix(line, []byte(`"}`)) {
+			return fmt.Errorf("audit log line %d: no hash", n)
+		}
+		hash := string(line[i+len(`,"hash":"`) : len(line)-2])
+		record := append(line[:i:i], '}')
+
+		var fields struct {
+			Prev string `json:"prev"`
+		}
+		err := json.Unmarshal(record, &fields)
+		if err != nil {
+			return fmt.Errorf("audit log line %d: %w", n, err)
+		}
+		if fields.Prev != prev {
+			return fmt.Errorf("audit log line %d: chain broken, a record before it was changed or removed", n)
+		}
+		if auditHash(record) != hash {
+			return fmt.Errorf("audit log line %d: record was modified", n)
+		}
+		prev = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading audit log: %w", err)
+	}
+	return nil
+}
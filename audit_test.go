@@ -0,0 +1,83 @@
+package gologger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeAuditRecords(t *testing.T, path string, actors ...string) {
+	t.Helper()
+	s, err := NewAuditSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for _, actor := range actors {
+		err := s.Write(Entry{Time: time.Now(), Level: AuditLevel, Fields: []Field{
+			{"actor", actor}, {"action", "deleted"}, {"target", "invoice 7"},
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeAuditRecords(t, path, "alice", "bob")
+	// reopening continues the chain
+	writeAuditRecords(t, path, "carol", "dave")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyAuditLog(bytes.NewReader(data)); err != nil {
+		t.Fatalf("VerifyAuditLog of an intact log: %v", err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	lines = lines[:len(lines)-1] // after the last newline
+	if len(lines) != 4 {
+		t.Fatalf("%d lines, want 4", len(lines))
+	}
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"modified", []string{lines[0], strings.Replace(lines[1], "bob", "eve", 1), lines[2], lines[3]},
+			"line 2: record was modified"},
+		{"modified with its hash", []string{lines[0], lines[1], lines[2][:strings.LastIndex(lines[2], `"hash"`)] + `"hash":"` + strings.Repeat("0", 64) + "\"}\n", lines[3]},
+			"line 3: record was modified"},
+		{"inserted", []string{lines[0], lines[1], lines[1], lines[2], lines[3]},
+			"line 3: chain broken"},
+		{"removed", []string{lines[0], lines[2], lines[3]},
+			"line 2: chain broken"},
+		{"first removed", []string{lines[1], lines[2], lines[3]},
+			"line 1: chain broken"},
+		{"no hash", []string{lines[0], "{\"ts\":\"2024-01-01T00:00:00Z\"}\n"},
+			"line 2: no hash"},
+	}
+	for _, tt := range tests {
+		err := VerifyAuditLog(strings.NewReader(strings.Join(tt.lines, "")))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: VerifyAuditLog = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestAuditSinkRefusesBrokenTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	err := os.WriteFile(path, []byte("not a record\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAuditSink(path); err == nil {
+		t.Error("NewAuditSink continued a log whose last line has no hash")
+	}
+}
@@ -0,0 +1,190 @@
+package gologger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AutoDebug raises the priority for a while when errors spike, see
+// SetAutoDebug.
+type AutoDebug struct {
+	Errors   int           // entries at error or more severe within Window that trigger it
+	Window   time.Duration // a minute if zero
+	Duration time.Duration // the priority stays raised, two minutes if zero; errors meanwhile extend it
+	Priority Priority      // raised to, LOG_DEBUG if zero
+	Context  time.Duration // of entries below the priority kept in memory and logged on a spike, none if zero
+}
+
+// Entries the Context of AutoDebug keeps at most, however short it is.
+const autoDebugContextMax = 10000
+
+type autoDebugState struct {
+	AutoDebug
+	l *Log
+
+	mu       sync.Mutex
+	errors   []time.Time // of the last Errors errors, a ring
+	next     int
+	context  []Entry // kept for Context, oldest first
+	active   bool
+	restore  Priority  // the priority before the spike
+	until    time.Time // of the system clock, so a frozen Clock can't keep it raised
+	timer    *time.Timer
+	disabled bool
+}
+
+// SetAutoDebug watches the rate of errors and, once a.Errors of them are
+// logged within a.Window, raises the priority to a.Priority for a.Duration
+// before setting back the one it was, e.g.
+//
+//	l.SetAutoDebug(&gologger.AutoDebug{Errors: 20, Window: time.Minute, Duration: 2 * time.Minute, Context: 10 * time.Second})
+//
+// captures debug entries around intermittent failures without logging them
+// all the time. With a Context, the entries of that long before the spike
+// are formatted and kept in memory although they aren't logged, and are
+// logged when it starts, after a warning saying so. The priority is only set
+// back if nothing else changed it meanwhile. A nil a stops it.
+func (l *Log) SetAutoDebug(a *AutoDebug) {
+	l = l.core()
+	var next *autoDebugState
+	if a != nil {
+		s := &autoDebugState{AutoDebug: *a, l: l}
+		if s.Errors < 1 {
+			s.Errors = 1
+		}
+		if s.Window <= 0 {
+			s.Window = time.Minute
+		}
+		if s.Duration <= 0 {
+			s.Duration = 2 * time.Minute
+		}
+		if s.Priority == 0 {
+			s.Priority = LOG_DEBUG
+		}
+		s.errors = make([]time.Time, s.Errors)
+		next = s
+	}
+
+	if previous := l.autoDebug.Swap(next); previous != nil {
+		previous.disable()
+	}
+}
+
+// keep adds entry, not logged, to the context of the next spike.
+func (a *autoDebugState) keep(entry Entry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	i := 0
+	for i < len(a.context) && entry.Time.Sub(a.context[i].Time) > a.Context {
+		i++
+	}
+	if len(a.context)-i >= autoDebugContextMax {
+		i = len(a.context) - autoDebugContextMax + 1
+	}
+	if i > 0 {
+		// move down rather than reslicing, so the array is reused
+		n := copy(a.context, a.context[i:])
+		clear(a.context[n:])
+		a.context = a.context[:n]
+	}
+	a.context = append(a.context, entry)
+}
+
+// observe counts an error logged at now. When it makes a spike, it raises the
+// priority and returns the entries to log before the error: a warning about
+// it and the context.
+func (a *autoDebugState) observe(now time.Time) []Entry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.disabled {
+		return nil
+	}
+
+	a.errors[a.next] = now
+	a.next = (a.next + 1) % len(a.errors)
+	// the slot to be overwritten next is the oldest of the last Errors errors
+	oldest := a.errors[a.next]
+	if oldest.IsZero() || now.Sub(oldest) > a.Window {
+		return nil
+	}
+	if a.active {
+		a.until = time.Now().Add(a.Duration)
+		return nil
+	}
+	current := a.l.CurrentPriority()
+	if current >= a.Priority {
+		return nil
+	}
+
+	a.active, a.restore = true, current
+	a.until = time.Now().Add(a.Duration)
+	a.l.SetPriority(a.Priority)
+	if a.timer == nil {
+		a.timer = time.AfterFunc(a.Duration, a.expire)
+	} else {
+		a.timer.Reset(a.Duration)
+	}
+
+	entries := make([]Entry, 0, 1+len(a.context))
+	entries = append(entries, Entry{
+		Time:  now,
+		Level: 'W',
+		Func:  "gologger",
+		Message: fmt.Sprintf("Error spike of %d errors within %s, logging at %s for %s, preceded by %d entries of the last %s",
+			a.Errors, a.Window, levelName(priorityLevel(a.Priority)), a.Duration, len(a.context), a.Context),
+		Fields: []Field{{"errors", a.Errors}, {"window", a.Window}, {"duration", a.Duration}, {"context", len(a.context)}},
+	})
+	entries = append(entries, a.context...)
+	clear(a.context)
+	a.context = a.context[:0]
+	return entries
+}
+
+// expire sets the priority back once the spike is over.
+func (a *autoDebugState) expire() {
+	a.mu.Lock()
+	if a.disabled || !a.active {
+		a.mu.Unlock()
+		return
+	}
+	if wait := time.Until(a.until); wait > 0 {
+		a.timer.Reset(wait)
+		a.mu.Unlock()
+		return
+	}
+	a.active = false
+	restore := a.restore
+	restored := a.l.CurrentPriority() == a.Priority
+	if restored {
+		a.l.SetPriority(restore)
+	}
+	a.mu.Unlock()
+
+	if restored && !a.l.stopped.Load() {
+		a.l.enqueue(logMsg{entry: Entry{
+			Time:    a.l.now(),
+			Level:   'I',
+			Func:    "gologger",
+			Message: fmt.Sprintf("Error spike over, logging at %s again", levelName(priorityLevel(restore))),
+		}})
+	}
+}
+
+// disable stops a, replaced by SetAutoDebug, setting back the priority of a
+// spike in progress.
+func (a *autoDebugState) disable() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.disabled = true
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	if a.active && a.l.CurrentPriority() == a.Priority {
+		a.l.SetPriority(a.restore)
+	}
+	a.active = false
+	a.context = nil
+}
@@ -0,0 +1,44 @@
+package gologger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAutoDebug(t *testing.T) {
+	l := &Log{}
+	l.SetPriority(LOG_INFO)
+	l.SetAutoDebug(&AutoDebug{Errors: 3, Window: time.Minute, Duration: time.Hour, Context: 10 * time.Second})
+	t.Cleanup(func() { l.SetAutoDebug(nil) })
+	a := l.autoDebug.Load()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	a.keep(Entry{Time: now, Level: 'D', Message: "too old"})
+	a.keep(Entry{Time: now.Add(15 * time.Second), Level: 'D', Message: "context"})
+
+	// 3 errors, but not within a minute
+	for _, offset := range []time.Duration{0, 50 * time.Second, 70 * time.Second} {
+		if entries := a.observe(now.Add(offset)); entries != nil {
+			t.Fatalf("spike after %s", offset)
+		}
+	}
+	if l.CurrentPriority() != LOG_INFO {
+		t.Fatal("priority raised without a spike")
+	}
+
+	entries := a.observe(now.Add(80 * time.Second))
+	if l.CurrentPriority() != LOG_DEBUG {
+		t.Errorf("priority %v during a spike, want debug", l.CurrentPriority())
+	}
+	if len(entries) != 2 || entries[0].Level != 'W' || entries[1].Message != "context" {
+		t.Fatalf("got %+v, want the warning and the context", entries)
+	}
+	if entries := a.observe(now.Add(81 * time.Second)); entries != nil {
+		t.Error("spike reported twice")
+	}
+
+	l.SetAutoDebug(nil)
+	if l.CurrentPriority() != LOG_INFO {
+		t.Errorf("priority %v after stopping, want info", l.CurrentPriority())
+	}
+}
@@ -0,0 +1,370 @@
+package gologger
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AWSCredentials sign the requests of the AWS sinks.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string    // for temporary credentials
+	Expires         time.Time // zero if they don't
+}
+
+// awsCredentialChain finds credentials where the AWS SDKs look for them:
+// the environment, the shared credentials file, a web identity token (EKS),
+// the ECS container endpoint and EC2 instance metadata. They are cached
+// until shortly before they expire.
+type awsCredentialChain struct {
+	client *http.Client
+
+	mu     sync.Mutex
+	cached AWSCredentials
+}
+
+func (c *awsCredentialChain) get() (AWSCredentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached.AccessKeyID != "" && (c.cached.Expires.IsZero() || time.Until(c.cached.Expires) > 5*time.Minute) {
+		return c.cached, nil
+	}
+
+	var errs []error
+	for _, provider := range []func() (AWSCredentials, error){
+		awsEnvCredentials, awsSharedCredentials, c.webIdentityCredentials, c.containerCredentials, c.instanceCredentials,
+	} {
+		creds, err := provider()
+		if err == nil && creds.AccessKeyID != "" {
+			c.cached = creds
+			return creds, nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return AWSCredentials{}, fmt.Errorf("no AWS credentials found: %w", errors.Join(errs...))
+}
+
+func awsEnvCredentials() (AWSCredentials, error) {
+	return AWSCredentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}, nil
+}
+
+// awsSharedCredentials reads the AWS_PROFILE (or default) section of ~/.aws/credentials.
+func awsSharedCredentials() (AWSCredentials, error) {
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return AWSCredentials{}, nil
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return AWSCredentials{}, nil
+	}
+	defer f.Close()
+
+	var creds AWSCredentials
+	var section string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || section != profile {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "aws_access_key_id":
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case "aws_secret_access_key":
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case "aws_session_token":
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return AWSCredentials{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return creds, nil
+}
+
+// webIdentityCredentials exchanges the token of AWS_WEB_IDENTITY_TOKEN_FILE,
+// as mounted by EKS, for role credentials through STS.
+func (c *awsCredentialChain) webIdentityCredentials() (AWSCredentials, error) {
+	tokenFile, role := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), os.Getenv("AWS_ROLE_ARN")
+	if tokenFile == "" || role == "" {
+		return AWSCredentials{}, nil
+	}
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("reading web identity token: %w", err)
+	}
+	session := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if session == "" {
+		session = "gologger-" + filepath.Base(os.Args[0])
+	}
+
+	endpoint := "https://sts.amazonaws.com/"
+	if region := awsRegion(""); region != "" {
+		endpoint = "https://sts." + region + ".amazonaws.com/"
+	}
+	query := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {role},
+		"RoleSessionName":  {session},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	resp, err := c.client.Get(endpoint + "?" + query.Encode())
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("assuming role with web identity: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return AWSCredentials{}, fmt.Errorf("assuming role with web identity: %s: %s", resp.Status, body)
+	}
+
+	var result struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("assuming role with web identity: %w", err)
+	}
+	cr := result.Credentials
+	return AWSCredentials{cr.AccessKeyID, cr.SecretAccessKey, cr.SessionToken, cr.Expiration}, nil
+}
+
+// awsTemporaryCredentials is the JSON of the ECS and EC2 credential endpoints.
+type awsTemporaryCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+func (c *awsCredentialChain) fetchTemporary(req *http.Request) (AWSCredentials, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return AWSCredentials{}, fmt.Errorf("%s", resp.Status)
+	}
+	var tc awsTemporaryCredentials
+	err = json.NewDecoder(resp.Body).Decode(&tc)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	return AWSCredentials{tc.AccessKeyID, tc.SecretAccessKey, tc.Token, tc.Expiration}, nil
+}
+
+// containerCredentials asks the ECS (or EKS Pod Identity) credential endpoint.
+func (c *awsCredentialChain) containerCredentials() (AWSCredentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		endpoint = "http://169.254.170.2" + relative
+	}
+	if endpoint == "" {
+		return AWSCredentials{}, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if file := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); file != "" {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return AWSCredentials{}, fmt.Errorf("reading container authorization token: %w", err)
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	creds, err := c.fetchTemporary(req)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("getting container credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// instanceCredentials asks EC2 instance metadata, IMDSv2, for the role's credentials.
+func (c *awsCredentialChain) instanceCredentials() (AWSCredentials, error) {
+	if os.Getenv("AWS_EC2_METADATA_DISABLED") == "true" {
+		return AWSCredentials{}, nil
+	}
+	const imds = "http://169.254.169.254/latest"
+	client := &http.Client{Timeout: time.Second, Transport: c.client.Transport}
+
+	req, _ := http.NewRequest(http.MethodPut, imds+"/api/token", nil)
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	resp, err := client.Do(req)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("getting instance metadata token: %w", err)
+	}
+	token, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	get := func(path string) (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, imds+path, nil)
+		if err == nil {
+			req.Header.Set("X-aws-ec2-metadata-token", string(token))
+		}
+		return req, err
+	}
+	req, _ = get("/meta-data/iam/security-credentials/")
+	resp, err = client.Do(req)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("getting instance role: %w", err)
+	}
+	role, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return AWSCredentials{}, fmt.Errorf("getting instance role: %s", resp.Status)
+	}
+
+	req, err = get("/meta-data/iam/security-credentials/" + strings.TrimSpace(string(role)))
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	creds, err := c.fetchTemporary(req)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("getting instance credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// awsRegion is region, else the one of the environment.
+func awsRegion(region string) string {
+	if region != "" {
+		return region
+	}
+	if region = os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
+
+// signAWSRequest adds the Signature Version 4 headers for body to req. It
+// signs host, content-type and the x-amz- headers, leaving out the others,
+// which proxies and the transport may change, and an earlier Authorization.
+func signAWSRequest(req *http.Request, body []byte, creds AWSCredentials, region, service string, now time.Time) {
+	signAWSRequestHash(req, sha256Hex(body), creds, region, service, now)
+}
+
+// signAWSRequestHash is signAWSRequest given the SHA-256 of the body, or
+// UNSIGNED-PAYLOAD for a streamed S3 upload.
+func signAWSRequestHash(req *http.Request, payloadHash string, creds AWSCredentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if k == "content-type" || k == "content-md5" || strings.HasPrefix(k, "x-amz-") {
+			headers[k] = strings.Join(strings.Fields(strings.Join(v, ",")), " ")
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,95 @@
+package gologger
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// From the Signature Version 4 test suite published by AWS.
+var awsTestCredentials = AWSCredentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+var awsTestTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func TestSignAWSRequest(t *testing.T) {
+	tests := []struct {
+		name          string
+		method, url   string
+		header        map[string]string
+		body          string
+		signedHeaders string
+		signature     string
+	}{
+		{
+			name: "get-vanilla", method: "GET", url: "https://example.amazonaws.com/",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name: "post-vanilla", method: "POST", url: "https://example.amazonaws.com/",
+			signedHeaders: "host;x-amz-date",
+			signature:     "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name: "get-vanilla-query-order-key-case", method: "GET", url: "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			signedHeaders: "host;x-amz-date",
+			signature:     "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name: "post-x-www-form-urlencoded", method: "POST", url: "https://example.amazonaws.com/",
+			header:        map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:          "Param1=value1",
+			signedHeaders: "content-type;host;x-amz-date",
+			signature:     "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		{
+			// headers the transport or a proxy may change are left out
+			name: "get-vanilla with other headers", method: "GET", url: "https://example.amazonaws.com/",
+			header:        map[string]string{"User-Agent": "gologger", "Accept-Encoding": "gzip"},
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			// signing again ignores the earlier signature
+			name: "get-vanilla signed before", method: "GET", url: "https://example.amazonaws.com/",
+			header:        map[string]string{"Authorization": "AWS4-HMAC-SHA256 Credential=old"},
+			signedHeaders: "host;x-amz-date",
+			signature:     "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range tt.header {
+			req.Header.Set(k, v)
+		}
+		signAWSRequest(req, []byte(tt.body), awsTestCredentials, "us-east-1", "service", awsTestTime)
+
+		want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=" +
+			tt.signedHeaders + ", Signature=" + tt.signature
+		if got := req.Header.Get("Authorization"); got != want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, got, want)
+		}
+	}
+}
+
+func TestSignAWSRequestSessionToken(t *testing.T) {
+	creds := awsTestCredentials
+	creds.SessionToken = "token"
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	signAWSRequest(req, nil, creds, "us-east-1", "s3", awsTestTime)
+
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "token" {
+		t.Errorf("X-Amz-Security-Token = %q", got)
+	}
+	want := "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,"
+	if got := req.Header.Get("Authorization"); !strings.Contains(got, want) {
+		t.Errorf("Authorization = %s, want %s", got, want)
+	}
+}
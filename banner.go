@@ -0,0 +1,95 @@
+package gologger
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// BannerEnv names the environment variables Banner records when set, a
+// trailing * matching any suffix, e.g. "APP_*". Values of variables whose
+// name contains one of SensitiveKeys are masked.
+var BannerEnv = []string{"GOMAXPROCS", "GOGC", "GOMEMLIMIT", "GODEBUG", "TZ"}
+
+// Banner logs at info level the record every service starts with: app,
+// version, Go version, main module and VCS revision from the build info, OS,
+// CPUs, PID, host and the variables of BannerEnv, followed by extra.
+func (l *Log) Banner(appName, version string, extra Fields) {
+	if !l.enabled(LOG_INFO) {
+		return
+	}
+	pc, line := l.caller(1)
+
+	fields := []Field{
+		{"app", appName},
+		{"version", version},
+		{"go_version", runtime.Version()},
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path != "" {
+			fields = append(fields, Field{"module", info.Main.Path})
+		}
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision", "vcs.time", "vcs.modified":
+				fields = append(fields, Field{strings.Replace(s.Key, ".", "_", 1), s.Value})
+			}
+		}
+	}
+	fields = append(fields,
+		Field{"os", runtime.GOOS + "/" + runtime.GOARCH},
+		Field{"cpus", runtime.NumCPU()},
+		Field{"pid", pid},
+		Field{"host", hostname},
+	)
+	if env := bannerEnv(); len(env) > 0 {
+		fields = append(fields, Field{"env", env})
+	}
+	// with no placeholders these are just the fields, by key
+	_, rest := renderTemplate("", extra)
+	fields = append(fields, rest...)
+
+	l.outputEntry(pc, Entry{
+		Level:    'I',
+		Line:     line,
+		Message:  "starting " + appName + " " + version,
+		Template: "starting {app} {version}",
+		Fields:   fields,
+	})
+}
+
+// bannerEnv returns the variables of BannerEnv that are set, sensitive ones masked.
+func bannerEnv() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if !bannerEnvSelected(name) {
+			continue
+		}
+		if sensitiveEnv(name) {
+			value = "[REDACTED]"
+		}
+		env[name] = value
+	}
+	return env
+}
+
+func bannerEnvSelected(name string) bool {
+	for _, pattern := range BannerEnv {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(name, prefix) || name == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func sensitiveEnv(name string) bool {
+	name = strings.ToLower(name)
+	for _, key := range SensitiveKeys {
+		if strings.Contains(name, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,328 @@
+package gologger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BatchOptions controls how sinks for remote services group entries into
+// requests and retry failed ones. The zero value works.
+type BatchOptions struct {
+	Size       int           // entries per request, 500 if zero
+	Interval   time.Duration // longest an entry waits for its batch to fill, a second if zero
+	MaxRetries int           // retries of a failed request, 5 if zero, negative for none
+	MinBackoff time.Duration // wait before the first retry, doubled after every attempt, 100ms if zero
+	MaxBackoff time.Duration // upper bound of the wait, 10s if zero
+
+	// Entries held while the service can't keep up. Beyond it the oldest are
+	// dropped instead of blocking the daemon, 0 for no limit. Flush then
+	// doesn't wait for the service either, only FTL, PANIC and Close do.
+	MaxQueued int
+
+	// Batches still failing after their retries go to segment files in
+	// SpoolDir instead of being dropped, and so do later ones until the
+	// service is back. They are sent from there in order, also after a
+	// restart, at least once.
+	SpoolDir      string
+	SpoolMaxBytes int64 // the oldest spooled entries are dropped beyond it, 1 GB if zero
+}
+
+func (o BatchOptions) withDefaults() BatchOptions {
+	if o.Size <= 0 {
+		o.Size = 500
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Second
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = 5
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = 100 * time.Millisecond
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 10 * time.Second
+	}
+	return o
+}
+
+// permanentError marks a failure retrying won't fix, e.g. a rejected request.
+type permanentError struct {
+	error
+}
+
+func (e permanentError) Unwrap() error {
+	return e.error
+}
+
+// Permanent wraps err so a batch sink gives up on the batch instead of retrying it.
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+// NewBatchSink returns a sink collecting entries into batches that push sends
+// from a goroutine of its own, retried as opts says. Flush waits for everything
+// written before it to be pushed, unless opts.MaxQueued is set. The building block of the Loki, Elasticsearch
+// and Fluentd sinks, for services gologger doesn't ship a sink for.
+func NewBatchSink(opts BatchOptions, push func(entries []Entry) error) Sink {
+	return newBatcher(opts, push)
+}
+
+// batcher collects entries for a sink and pushes them from its own goroutine,
+// so a slow or unreachable service doesn't hold up the daemon.
+type batcher struct {
+	opts  BatchOptions
+	push  func(entries []Entry) error
+	spool *spool // with SpoolDir
+
+	mu      sync.Mutex
+	pending []Entry
+	dropped int   // entries discarded because of MaxQueued since the last report
+	err     error // failure of a background push, returned by the next Write or Flush
+
+	requests chan batchRequest
+	done     chan struct{}
+}
+
+type batchRequest struct {
+	entries []Entry
+	result  chan error // nil for batches nobody waits on
+}
+
+func newBatcher(opts BatchOptions, push func(entries []Entry) error) *batcher {
+	b := &batcher{
+		opts:     opts.withDefaults(),
+		push:     push,
+		requests: make(chan batchRequest, 4),
+		done:     make(chan struct{}),
+	}
+	if b.opts.SpoolDir != "" {
+		b.spool, b.err = openSpool(b.opts.SpoolDir, 0, b.opts.SpoolMaxBytes)
+	}
+	go b.run()
+	return b
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case req, ok := <-b.requests:
+			if !ok {
+				return
+			}
+			err := b.send(req.entries)
+			if req.result != nil {
+				req.result <- err
+			} else {
+				b.failed(err)
+			}
+		case <-ticker.C:
+			b.failed(b.send(b.take()))
+			if b.spool != nil && !b.spool.empty() {
+				b.failed(b.resend())
+			}
+		}
+	}
+}
+
+func (b *batcher) take() []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries := b.pending
+	b.pending = nil
+	return entries
+}
+
+func (b *batcher) failed(err error) {
+	if err == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.err = errors.Join(b.err, err)
+}
+
+func (b *batcher) takeErr() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.err
+	b.err = nil
+	if b.dropped > 0 {
+		err = errors.Join(err, fmt.Errorf("queue full, dropped %d entries", b.dropped))
+		b.dropped = 0
+	}
+	return err
+}
+
+// send pushes entries in batches of at most Size.
+func (b *batcher) send(entries []Entry) error {
+	var errs []error
+	for len(entries) > 0 {
+		n := min(len(entries), b.opts.Size)
+		errs = append(errs, b.sendBatch(entries[:n]))
+		entries = entries[n:]
+	}
+	return errors.Join(errs...)
+}
+
+// sendBatch pushes entries, retrying with exponential backoff, and spools
+// them if that fails.
+func (b *batcher) sendBatch(entries []Entry) error {
+	if b.spool != nil && !b.spool.empty() {
+		// behind the spooled ones, to keep the order
+		err := b.spool.append(entries)
+		if err != nil {
+			return fmt.Errorf("sending %d entries: %w", len(entries), err)
+		}
+		return b.resend()
+	}
+
+	backoff := b.opts.MinBackoff
+	for attempt := 0; ; attempt++ {
+		err := b.push(entries)
+		if err == nil {
+			return nil
+		}
+		permanent := errors.As(err, &permanentError{})
+		if permanent || attempt >= b.opts.MaxRetries {
+			err = fmt.Errorf("sending %d entries: %w", len(entries), err)
+			if b.spool != nil && !permanent {
+				if serr := b.spool.append(entries); serr != nil {
+					return errors.Join(err, serr)
+				}
+				return b.spool.takeErr()
+			}
+			return err
+		}
+		time.Sleep(backoff)
+		backoff = min(2*backoff, b.opts.MaxBackoff)
+	}
+}
+
+// resend pushes the spooled entries until the service fails again, without
+// retries, the next attempt being the next tick or batch. Only rejected
+// entries are reported, the others are safe in the spool.
+func (b *batcher) resend() error {
+	var errs []error
+	for {
+		entries, pos := b.spool.next(b.opts.Size)
+		if len(entries) == 0 {
+			b.spool.commit(pos)
+			break
+		}
+		err := b.push(entries)
+		if err != nil && !errors.As(err, &permanentError{}) {
+			break
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sending %d spooled entries: %w", len(entries), err))
+		}
+		b.spool.commit(pos)
+	}
+	errs = append(errs, b.spool.takeErr())
+	return errors.Join(errs...)
+}
+
+func (b *batcher) Write(entry Entry) error {
+	b.mu.Lock()
+	b.pending = append(b.pending, entry)
+	if limit := b.opts.MaxQueued; limit > 0 && len(b.pending) > limit {
+		n := len(b.pending) - limit
+		b.pending = append(b.pending[:0], b.pending[n:]...)
+		b.dropped += n
+	}
+	full := len(b.pending) >= b.opts.Size
+	b.mu.Unlock()
+
+	if full {
+		b.sendPending()
+	}
+	return b.takeErr()
+}
+
+// sendPending hands the pending entries to the pushing goroutine. With
+// MaxQueued they stay pending while it is busy, otherwise this waits for it.
+func (b *batcher) sendPending() {
+	if b.opts.MaxQueued <= 0 {
+		b.requests <- batchRequest{entries: b.take()}
+		return
+	}
+	b.handOff()
+}
+
+// handOff hands the pending entries to the pushing goroutine unless it is
+// busy, in which case they go with the next batch or tick.
+func (b *batcher) handOff() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.pending) == 0 {
+		return
+	}
+	select {
+	case b.requests <- batchRequest{entries: b.pending}:
+		b.pending = nil
+	default:
+	}
+}
+
+// startFlush is the periodic flush, it doesn't wait for the service.
+func (b *batcher) startFlush() error {
+	b.handOff()
+	return b.takeErr()
+}
+
+// Flush pushes the pending entries and waits for every batch before them,
+// unless MaxQueued bounds how much may wait.
+func (b *batcher) Flush() error {
+	if b.opts.MaxQueued > 0 {
+		return b.startFlush()
+	}
+	return b.Sync()
+}
+
+// Sync pushes the pending entries and waits for every batch before them.
+func (b *batcher) Sync() error {
+	result := make(chan error, 1)
+	b.requests <- batchRequest{entries: b.take(), result: result}
+	return errors.Join(<-result, b.takeErr())
+}
+
+func (b *batcher) Close() error {
+	err := b.Sync()
+	close(b.requests)
+	<-b.done
+	if b.spool != nil {
+		err = errors.Join(err, b.spool.Close())
+	}
+	return err
+}
+
+// defaultHTTPClient is what the HTTP based sinks use without a Client, so a
+// hung service fails a push rather than stalling the sink forever.
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// checkHTTPResponse turns a non-2xx response into an error, permanent unless
+// the status suggests trying again later. It consumes and closes the body.
+func checkHTTPResponse(resp *http.Response) error {
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err := fmt.Errorf("%s: %s", resp.Status, body)
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return err
+	}
+	return permanentError{err}
+}
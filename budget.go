@@ -0,0 +1,277 @@
+package gologger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Budget caps the bytes a logger writes per Interval, see SetBudget.
+type Budget struct {
+	Bytes    int64         // of messages and fields per Interval, approximately
+	Interval time.Duration // a minute if zero
+}
+
+// budgets maps logger names to the state of their Budget, copied on write.
+type budgets map[string]*budgetState
+
+type budgetState struct {
+	Budget
+
+	mu        sync.Mutex
+	start     time.Time // of the current interval
+	used      int64
+	shed      map[byte]int64 // entries per level in the current interval
+	shedBytes int64
+	wildcard  bool // added by the "*" budget
+}
+
+// budgetShare is the share of its budget a logger may have used for an entry
+// at a level to still go out, so the least important are shed first.
+func budgetShare(level byte) float64 {
+	switch p := levelPriority(level); {
+	case p <= LOG_ERR:
+		return 1
+	case p <= LOG_WARNING:
+		return 0.9
+	case p <= LOG_INFO:
+		return 0.75
+	default:
+		return 0.5
+	}
+}
+
+// SetBudget caps what the logger named logger writes, "" being the root logger
+// and "*" every logger without a budget of its own, each on its own. Once a
+// logger used half its budget in an interval, debug and trace entries are
+// shed, at 75% info, at 90% warnings and at 100% errors; fatal and Always
+// entries never are. When an interval with shedding ends, a warning says how
+// much, at the latest a second after it ended. A nil b removes the budget.
+func (l *Log) SetBudget(logger string, b *Budget) {
+	l = l.core()
+	l.budgetMu.Lock()
+	defer l.budgetMu.Unlock()
+
+	next := budgets{}
+	if current := l.budgets.Load(); current != nil {
+		for k, v := range *current {
+			if k != logger && !(logger == "*" && v.wildcard) {
+				next[k] = v
+			}
+		}
+	}
+	if b != nil {
+		budget := *b
+		if budget.Interval <= 0 {
+			budget.Interval = time.Minute
+		}
+		next[logger] = &budgetState{Budget: budget}
+	}
+
+	if len(next) == 0 {
+		l.budgets.Store(nil)
+		if l.stopBudgets != nil {
+			l.stopBudgets()
+			l.stopBudgets = nil
+		}
+		return
+	}
+	l.budgets.Store(&next)
+	l.watchBudgets()
+}
+
+// How often the budgets are checked for intervals that ended without another
+// entry, so what was shed in them is reported anyway.
+const budgetTick = time.Second
+
+// watchBudgets starts the goroutine logging what budgetSummaries returns
+// every budgetTick, unless it runs. l.budgetMu is held.
+func (l *Log) watchBudgets() {
+	if l.stopBudgets != nil {
+		return
+	}
+	quit := make(chan struct{})
+	l.stopBudgets = func() { close(quit) }
+	go func() {
+		ticker := time.NewTicker(budgetTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				for _, summary := range l.budgetSummaries(l.now()) {
+					if !l.stopped.Load() {
+						l.enqueue(logMsg{entry: *summary})
+					}
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+}
+
+// budgetSummaries returns the warnings about the intervals ended by now in
+// which entries were shed, starting the next, and drops the states the "*"
+// budget added for loggers idle for two intervals.
+func (l *Log) budgetSummaries(now time.Time) []*Entry {
+	set := l.budgets.Load()
+	if set == nil {
+		return nil
+	}
+	var summaries []*Entry
+	var idle []string
+	for logger, b := range *set {
+		b.mu.Lock()
+		switch elapsed := now.Sub(b.start); {
+		case elapsed >= b.Interval && b.shedBytes > 0:
+			summaries = append(summaries, b.summary(logger, now))
+			b.start, b.used, b.shedBytes = now, 0, 0
+			clear(b.shed)
+		case elapsed >= 2*b.Interval && b.wildcard:
+			idle = append(idle, logger)
+		}
+		b.mu.Unlock()
+	}
+	if len(idle) > 0 {
+		l.dropBudgetStates(idle)
+	}
+	return summaries
+}
+
+// dropBudgetStates removes the states of loggers added by the "*" budget, to
+// be added again on their next entry.
+func (l *Log) dropBudgetStates(loggers []string) {
+	l.budgetMu.Lock()
+	defer l.budgetMu.Unlock()
+
+	current := l.budgets.Load()
+	if current == nil {
+		return
+	}
+	next := make(budgets, len(*current))
+	for k, v := range *current {
+		next[k] = v
+	}
+	for _, logger := range loggers {
+		if b, ok := next[logger]; ok && b.wildcard {
+			delete(next, logger)
+		}
+	}
+	l.budgets.Store(&next)
+}
+
+// overBudget counts entry against the budget of its logger and reports
+// whether it is to be shed.
+func (l *Log) overBudget(entry *Entry, now time.Time) bool {
+	core := l.core()
+	set := core.budgets.Load()
+	if set == nil {
+		return false
+	}
+	b, ok := (*set)[entry.Logger]
+	if !ok {
+		wildcard, ok := (*set)["*"]
+		if !ok {
+			return false
+		}
+		b = core.loggerBudget(entry.Logger, wildcard.Budget)
+	}
+
+	size := entrySize(entry)
+	b.mu.Lock()
+	var summary *Entry
+	if now.Sub(b.start) >= b.Interval {
+		if b.shedBytes > 0 {
+			summary = b.summary(entry.Logger, now)
+		}
+		b.start, b.used, b.shedBytes = now, 0, 0
+		clear(b.shed)
+	}
+	shed := entry.Level != 'F' && entry.Level != 'P' && float64(b.used+size) > budgetShare(entry.Level)*float64(b.Bytes)
+	if shed {
+		if b.shed == nil {
+			b.shed = make(map[byte]int64)
+		}
+		b.shed[entry.Level]++
+		b.shedBytes += size
+	} else {
+		b.used += size
+	}
+	b.mu.Unlock()
+
+	if summary != nil {
+		core.enqueue(logMsg{entry: *summary})
+	}
+	return shed
+}
+
+// loggerBudget returns the state of the budget logger has by the "*" budget,
+// adding it on first use.
+func (l *Log) loggerBudget(logger string, budget Budget) *budgetState {
+	l.budgetMu.Lock()
+	defer l.budgetMu.Unlock()
+
+	current := l.budgets.Load()
+	if current == nil {
+		// removed meanwhile, don't count
+		return &budgetState{Budget: Budget{Bytes: 1 << 62, Interval: budget.Interval}}
+	}
+	if b, ok := (*current)[logger]; ok {
+		return b
+	}
+	next := make(budgets, len(*current)+1)
+	for k, v := range *current {
+		next[k] = v
+	}
+	b := &budgetState{Budget: budget, wildcard: true}
+	next[logger] = b
+	l.budgets.Store(&next)
+	return b
+}
+
+// summary is the warning about what was shed in the interval ending at now.
+func (b *budgetState) summary(logger string, now time.Time) *Entry {
+	var total int64
+	fields := []Field{{"logger", logger}}
+	for _, level := range []byte{'E', 'W', 'I', 'D', 'T'} {
+		if n := b.shed[level]; n > 0 {
+			fields = append(fields, Field{"shed_" + levelName(level), n})
+			total += n
+		}
+	}
+	for level, n := range b.shed {
+		if StandardLevel(level) != level {
+			total += n
+		}
+	}
+	fields = append(fields, Field{"shed", total}, Field{"shed_bytes", b.shedBytes})
+	name := logger
+	if name == "" {
+		name = "the root logger"
+	}
+	return &Entry{
+		Time:    now,
+		Level:   'W',
+		Logger:  logger,
+		Func:    "gologger",
+		Message: fmt.Sprintf("Log budget of %s exceeded, shed %d entries of %d bytes in the %s from %s", name, total, b.shedBytes, b.Interval, b.start.Format(time.TimeOnly)),
+		Fields:  fields,
+	}
+}
+
+// entrySize estimates what entry takes in a sink, before encoding.
+func entrySize(entry *Entry) int64 {
+	size := int64(48 + len(entry.Logger) + len(entry.Func) + len(entry.Message) + len(entry.Stack))
+	for _, f := range entry.Fields {
+		size += int64(len(f.Key)) + 4
+		switch v := f.Value.(type) {
+		case string:
+			size += int64(len(v))
+		case []byte:
+			size += int64(len(v))
+		default:
+			size += 8
+		}
+	}
+	return size
+}
@@ -0,0 +1,89 @@
+package gologger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOverBudget(t *testing.T) {
+	l := &Log{}
+	l.SetBudget("db", &Budget{Bytes: 1000})
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// 100 bytes each, the debug ones stop at half the budget, the info ones at 75%
+	entry := func(level byte) *Entry {
+		return &Entry{Level: level, Logger: "db", Message: strings.Repeat("x", 100-48-2)}
+	}
+	var kept []byte
+	for _, level := range []byte{'D', 'D', 'D', 'D', 'D', 'D', 'I', 'I', 'I', 'W', 'W', 'E', 'E', 'F'} {
+		if !l.overBudget(entry(level), now) {
+			kept = append(kept, level)
+		}
+	}
+	if got, want := string(kept), "DDDDDIIWWEF"; got != want {
+		t.Errorf("kept %s, want %s", got, want)
+	}
+	if l.overBudget(&Entry{Level: 'D', Logger: "other"}, now) {
+		t.Error("logger without a budget was shed")
+	}
+
+	b := (*l.budgets.Load())["db"]
+	summary := b.summary("db", now.Add(time.Minute))
+	if !strings.Contains(summary.Message, "shed 3 entries of 300 bytes in the 1m0s from 12:00:00") {
+		t.Errorf("summary %q", summary.Message)
+	}
+
+	l.SetBudget("*", &Budget{Bytes: 100})
+	if _, ok := (*l.budgets.Load())["other"]; ok {
+		t.Error("wildcard budget added before use")
+	}
+	l.overBudget(&Entry{Level: 'I', Logger: "other"}, now)
+	if b, ok := (*l.budgets.Load())["other"]; !ok || !b.wildcard || b.Bytes != 100 {
+		t.Error("wildcard budget not added on use")
+	}
+	l.SetBudget("*", nil)
+	if _, ok := (*l.budgets.Load())["other"]; ok {
+		t.Error("wildcard budget kept after removing *")
+	}
+	l.SetBudget("db", nil)
+	if l.budgets.Load() != nil {
+		t.Error("budgets left after removing all")
+	}
+}
+
+func TestBudgetSummaries(t *testing.T) {
+	l := &Log{}
+	l.SetBudget("db", &Budget{Bytes: 100})
+	l.SetBudget("*", &Budget{Bytes: 1000})
+	t.Cleanup(func() {
+		l.SetBudget("db", nil)
+		l.SetBudget("*", nil)
+	})
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// the db logger floods, then goes quiet
+	for i := 0; i < 5; i++ {
+		l.overBudget(&Entry{Level: 'D', Logger: "db", Message: strings.Repeat("x", 50)}, now)
+	}
+	l.overBudget(&Entry{Level: 'I', Logger: "worker"}, now)
+
+	if summaries := l.budgetSummaries(now.Add(30 * time.Second)); len(summaries) != 0 {
+		t.Errorf("got %d summaries before the interval ended", len(summaries))
+	}
+	summaries := l.budgetSummaries(now.Add(time.Minute))
+	if len(summaries) != 1 || summaries[0].Logger != "db" {
+		t.Fatalf("got %+v, want the summary of db", summaries)
+	}
+	if summaries := l.budgetSummaries(now.Add(time.Minute)); len(summaries) != 0 {
+		t.Error("summary reported twice")
+	}
+
+	l.budgetSummaries(now.Add(2 * time.Minute))
+	if _, ok := (*l.budgets.Load())["worker"]; ok {
+		t.Error("idle wildcard budget kept")
+	}
+	if _, ok := (*l.budgets.Load())["db"]; !ok {
+		t.Error("budget of its own dropped")
+	}
+}
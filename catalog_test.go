@@ -0,0 +1,35 @@
+package gologger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMessageCatalog(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"user.login": "Benutzer {user} angemeldet"}`), 0600)
+	os.WriteFile(filepath.Join(dir, "de-CH.json"), []byte(`{"user.logout": "Benutzer {user} abgemeldet"}`), 0600)
+	catalog, err := LoadMessageCatalog(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		locale, id string
+		want       string
+		ok         bool
+	}{
+		{"de", "user.login", "Benutzer {user} angemeldet", true},
+		{"de-CH", "user.logout", "Benutzer {user} abgemeldet", true},
+		{"de-CH", "user.login", "Benutzer {user} angemeldet", true}, // from the language
+		{"de_AT", "user.login", "Benutzer {user} angemeldet", true},
+		{"fr", "user.login", "", false},
+		{"de", "user.unknown", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := catalog.lookup(tt.locale, tt.id); got != tt.want || ok != tt.ok {
+			t.Errorf("lookup(%q, %q) = %q, %v, want %q, %v", tt.locale, tt.id, got, ok, tt.want, tt.ok)
+		}
+	}
+}
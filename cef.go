@@ -0,0 +1,133 @@
+package gologger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CEFEncoder writes ArcSight Common Event Format, for SIEMs taking events
+// straight from the syslog sink (see SyslogEncoder):
+// CEF:0|Vendor|Product|Version|SignatureID|message|severity|rt=... key=value
+type CEFEncoder struct {
+	Vendor, Product, Version string // identify the device in the header
+
+	// Field holding the Signature ID, e.g. "event". The level name if empty
+	// or the entry has no such field.
+	EventClassField string
+
+	// Renames fields to CEF extension keys, e.g. "user": "suser", "ip": "src".
+	FieldMap     map[string]string
+	DropUnmapped bool // leave out fields FieldMap doesn't name
+}
+
+// cefSeverity maps levels onto CEF's 0-10 scale, also used by LEEF's sev.
+func cefSeverity(level byte) int {
+	switch StandardLevel(level) {
+	case 'F', 'P':
+		return 10
+	case 'E':
+		return 7
+	case 'W':
+		return 5
+	case 'I':
+		return 3
+	case 'D':
+		return 1
+	default:
+		return 0
+	}
+}
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+func (e CEFEncoder) Encode(buf []byte, entry Entry) []byte {
+	buf = append(buf, "CEF:0|"...)
+	for _, s := range []string{e.Vendor, e.Product, e.Version, eventClass(entry, e.EventClassField), entry.Message} {
+		buf = append(buf, cefHeaderEscaper.Replace(s)...)
+		buf = append(buf, '|')
+	}
+	buf = strconv.AppendInt(buf, int64(cefSeverity(entry.Level)), 10)
+	buf = append(buf, "|rt="...)
+	buf = strconv.AppendInt(buf, entry.Time.UnixMilli(), 10)
+
+	if entry.Func != "" {
+		buf = append(buf, " sourceServiceName="...)
+		buf = append(buf, cefExtensionEscaper.Replace(entry.Func)...)
+	}
+	for _, f := range entry.Fields {
+		key, ok := mapField(f.Key, e.FieldMap, e.DropUnmapped)
+		if !ok || f.Key == e.EventClassField {
+			continue
+		}
+		buf = append(buf, ' ')
+		buf = append(buf, key...)
+		buf = append(buf, '=')
+		buf = append(buf, cefExtensionEscaper.Replace(fmt.Sprint(f.Value))...)
+	}
+	return append(buf, '\n')
+}
+
+// eventClass is the value of field, or the level name.
+func eventClass(entry Entry, field string) string {
+	if field != "" {
+		for _, f := range entry.Fields {
+			if f.Key == field {
+				return fmt.Sprint(f.Value)
+			}
+		}
+	}
+	return levelName(entry.Level)
+}
+
+func mapField(key string, fieldMap map[string]string, dropUnmapped bool) (string, bool) {
+	if mapped, ok := fieldMap[key]; ok {
+		return mapped, true
+	}
+	return key, !dropUnmapped
+}
+
+// LEEFEncoder writes IBM QRadar's Log Event Extended Format 2.0 with tab
+// separated attributes:
+// LEEF:2.0|Vendor|Product|Version|EventID|x09|devTime=...	sev=...	key=value
+type LEEFEncoder struct {
+	Vendor, Product, Version string
+
+	// Field holding the EventID, the level name if empty or missing.
+	EventIDField string
+
+	// Renames fields to LEEF attributes, e.g. "user": "usrName", "ip": "src".
+	FieldMap     map[string]string
+	DropUnmapped bool
+}
+
+var leefEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ", "|", " ")
+
+func (e LEEFEncoder) Encode(buf []byte, entry Entry) []byte {
+	buf = append(buf, "LEEF:2.0|"...)
+	for _, s := range []string{e.Vendor, e.Product, e.Version, eventClass(entry, e.EventIDField)} {
+		buf = append(buf, leefEscaper.Replace(s)...)
+		buf = append(buf, '|')
+	}
+	buf = append(buf, "x09|devTime="...)
+	buf = strconv.AppendInt(buf, entry.Time.UnixMilli(), 10)
+	buf = append(buf, "\tsev="...)
+	buf = strconv.AppendInt(buf, int64(cefSeverity(entry.Level)), 10)
+	buf = append(buf, "\tmsg="...)
+	buf = append(buf, leefEscaper.Replace(entry.Message)...)
+
+	for _, f := range entry.Fields {
+		key, ok := mapField(f.Key, e.FieldMap, e.DropUnmapped)
+		if !ok || f.Key == e.EventIDField {
+			continue
+		}
+		buf = append(buf, '\t')
+		buf = append(buf, key...)
+		buf = append(buf, '=')
+		buf = append(buf, leefEscaper.Replace(fmt.Sprint(f.Value))...)
+	}
+	return append(buf, '\n')
+}
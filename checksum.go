@@ -0,0 +1,115 @@
+package gologger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The manifest LogfileChecksums keeps next to each logfile, in the file
+// named like it with manifestSuffix appended.
+type logfileManifest struct {
+	File   string    `json:"file"`
+	Size   int64     `json:"size"`
+	Lines  int64     `json:"lines"` // newlines in the first Size bytes
+	SHA256 string    `json:"sha256"`
+	Time   time.Time `json:"time"`
+}
+
+const manifestSuffix = ".sum"
+
+// logfileDigest keeps the checksum of everything in a logfile as the file sink appends to it.
+type logfileDigest struct {
+	hash  hash.Hash
+	size  int64
+	lines int64
+	dirty bool // written to since the manifest was
+}
+
+// newLogfileDigest starts with what the file at name holds already.
+func newLogfileDigest(name string) (*logfileDigest, error) {
+	d := &logfileDigest{hash: sha256.New(), dirty: true}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	_, err = io.Copy(d, f)
+	return d, err
+}
+
+func (d *logfileDigest) Write(b []byte) (int, error) {
+	d.hash.Write(b)
+	d.size += int64(len(b))
+	d.lines += int64(bytes.Count(b, []byte{'\n'}))
+	d.dirty = true
+	return len(b), nil
+}
+
+// writeManifest replaces the manifest of the logfile name, unless nothing changed.
+func (d *logfileDigest) writeManifest(name string) error {
+	if !d.dirty {
+		return nil
+	}
+	b, err := json.Marshal(logfileManifest{
+		File:   filepath.Base(name),
+		Size:   d.size,
+		Lines:  d.lines,
+		SHA256: hex.EncodeToString(d.hash.Sum(nil)),
+		Time:   time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp := name + manifestSuffix + ".tmp"
+	err = os.WriteFile(tmp, append(b, '\n'), 0600)
+	if err == nil {
+		err = os.Rename(tmp, name+manifestSuffix)
+	}
+	if err != nil {
+		return fmt.Errorf("writing logfile manifest: %w", err)
+	}
+	d.dirty = false
+	return nil
+}
+
+// VerifyLogfile checks the logfile at path against the manifest kept with
+// LogfileChecksums, returning an error if it was cut short or changed. The
+// manifest is written on flushes and when the file is closed, so what was
+// appended since, the tail of the current logfile, goes unchecked.
+func VerifyLogfile(path string) error {
+	b, err := os.ReadFile(path + manifestSuffix)
+	if err != nil {
+		return err
+	}
+	var m logfileManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return fmt.Errorf("reading %s: %w", path+manifestSuffix, err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	d := &logfileDigest{hash: sha256.New()}
+	_, err = io.CopyN(d, f, m.Size)
+	if errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s truncated: %d bytes, %d in the manifest", path, d.size, m.Size)
+	} else if err != nil {
+		return err
+	}
+	if hex.EncodeToString(d.hash.Sum(nil)) != m.SHA256 {
+		return fmt.Errorf("%s modified: its checksum doesn't match the manifest", path)
+	}
+	return nil
+}
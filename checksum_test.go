@@ -0,0 +1,60 @@
+package gologger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyLogfile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	l := &Log{LogfileDir: dir, LogfileName: "app.log", LogfileChecksums: true, counters: &counters{}}
+	// a second run appends to the file of the first
+	for run := 0; run < 2; run++ {
+		s := &fileSink{l: l}
+		for i := 0; i < 3; i++ {
+			if err := s.Write(Entry{Time: time.Now(), Level: 'I', Message: "entry"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := VerifyLogfile(path); err != nil {
+		t.Fatalf("VerifyLogfile of an intact logfile: %v", err)
+	}
+	manifest, err := os.ReadFile(path + manifestSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(manifest), `"lines":6`) {
+		t.Errorf("manifest %s, want 6 lines", manifest)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"appended after the manifest", string(data) + "later\n", ""},
+		{"truncated", string(data[:len(data)-10]), "truncated"},
+		{"modified", strings.Replace(string(data), "entry", "entrY", 1), "modified"},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		err := VerifyLogfile(path)
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("%s: VerifyLogfile = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
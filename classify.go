@@ -0,0 +1,56 @@
+package gologger
+
+// Classifier is a Hook rewriting the level of entries matching its rules,
+// e.g. to take known, harmless errors out of the error counts:
+//
+//	c := gologger.NewClassifier()
+//	c.Add(`level==error && msg~="connection reset by peer"`, 'W')
+//	l.AddHook(c)
+//
+// Rewritten entries get their level at the call site as the original_level
+// field. Add rules before adding the hook, it must not change once it is.
+type Classifier struct {
+	rules []classifierRule
+}
+
+type classifierRule struct {
+	match Filter
+	level byte
+}
+
+// NewClassifier returns a Classifier without rules.
+func NewClassifier() *Classifier {
+	return &Classifier{}
+}
+
+// Add rewrites the level of the entries matching expr, a filter expression as
+// for ParseFilter, to level. The first matching rule applies.
+func (c *Classifier) Add(expr string, level byte) error {
+	match, err := ParseFilter(expr)
+	if err != nil {
+		return err
+	}
+	c.AddFilter(match, level)
+	return nil
+}
+
+// AddFilter is Add with a Filter function.
+func (c *Classifier) AddFilter(match Filter, level byte) {
+	c.rules = append(c.rules, classifierRule{match, level})
+}
+
+func (c *Classifier) Before(entry *Entry) bool {
+	for _, r := range c.rules {
+		if !r.match(*entry) {
+			continue
+		}
+		if r.level != entry.Level {
+			entry.Fields = append(entry.Fields[:len(entry.Fields):len(entry.Fields)], Field{"original_level", levelName(entry.Level)})
+			entry.Level = r.level
+		}
+		break
+	}
+	return true
+}
+
+func (c *Classifier) After(entry Entry, err error) {}
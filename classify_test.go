@@ -0,0 +1,36 @@
+package gologger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifier(t *testing.T) {
+	c := NewClassifier()
+	if err := c.Add(`level==error && msg~="connection reset by peer"`, 'W'); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add(`msg~="^health check"`, 'D'); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Add(`level==`, 'D'); err == nil {
+		t.Error("Add accepted an invalid expression")
+	}
+
+	tests := []struct {
+		in, want Entry
+	}{
+		{Entry{Level: 'E', Message: "read: connection reset by peer"},
+			Entry{Level: 'W', Message: "read: connection reset by peer", Fields: []Field{{"original_level", "error"}}}},
+		{Entry{Level: 'I', Message: "health check ok"},
+			Entry{Level: 'D', Message: "health check ok", Fields: []Field{{"original_level", "info"}}}},
+		{Entry{Level: 'E', Message: "disk full"}, Entry{Level: 'E', Message: "disk full"}},
+		{Entry{Level: 'D', Message: "health check ok"}, Entry{Level: 'D', Message: "health check ok"}},
+	}
+	for _, tt := range tests {
+		got := tt.in
+		if !c.Before(&got) || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Before(%+v) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package gologger
+
+import "time"
+
+// Clock tells the time entries are stamped with, see SetClock.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SetClock makes the logger take the time of entries, timers and self tests
+// from c, e.g. a frozen clock so tests can compare output verbatim. Nil goes
+// back to the system clock.
+func (l *Log) SetClock(c Clock) {
+	if c == nil {
+		c = systemClock{}
+	}
+	l.core().clock.Store(&c)
+}
+
+// now is the time of the logger's clock.
+func (l *Log) now() time.Time {
+	if c := l.core().clock.Load(); c != nil {
+		return (*c).Now()
+	}
+	return time.Now()
+}
+
+// SetMonotonic adds the time since the logger started, by the monotonic clock,
+// to every entry as the mono_ns field, in nanoseconds. Unlike the wall time it
+// never jumps with NTP adjustments, so it orders entries of one process
+// reliably.
+func (l *Log) SetMonotonic(on bool) {
+	l.core().monotonic.Store(on)
+}
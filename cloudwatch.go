@@ -0,0 +1,257 @@
+package gologger
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CloudWatchOptions configures NewCloudWatchSink.
+type CloudWatchOptions struct {
+	Region string // AWS_REGION or AWS_DEFAULT_REGION if empty
+
+	// Log stream name template with the placeholders of LogfileName,
+	// "{hostname}-{pid}" if empty. Group and stream are created when missing.
+	LogStream     string
+	RetentionDays int // set on log groups this creates, e.g. 30. Never expire if zero
+
+	Encoder Encoder // renders the messages, JSONEncoder if nil
+
+	// Credentials overrides the chain of the AWS SDKs: the environment, the
+	// shared credentials file, EKS web identity, ECS and EC2 metadata.
+	Credentials func() (AWSCredentials, error)
+	Endpoint    string // e.g. for LocalStack, https://logs.<region>.amazonaws.com if empty
+
+	Client *http.Client // one with a 30s timeout if nil
+	Batch  BatchOptions // Size is capped at the 10000 events of PutLogEvents
+}
+
+// The limits of PutLogEvents.
+const (
+	cloudWatchMaxEvents     = 10000
+	cloudWatchMaxBytes      = 1048576
+	cloudWatchEventOverhead = 26
+	cloudWatchMaxEventSize  = 256*1024 - cloudWatchEventOverhead
+	cloudWatchMaxSpan       = 24 * time.Hour
+)
+
+type cloudWatchSink struct {
+	*batcher
+
+	group, stream string
+	region, url   string
+	opts          CloudWatchOptions
+	credentials   func() (AWSCredentials, error)
+	token         string // sequence token, only still returned by old accounts
+	buf           []byte
+}
+
+// NewCloudWatchSink returns a sink pushing batches of entries to the CloudWatch
+// Logs group logGroup, e.g. from Lambda or ECS without an agent.
+func NewCloudWatchSink(logGroup string, opts *CloudWatchOptions) (Sink, error) {
+	s := &cloudWatchSink{group: logGroup}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if logGroup == "" {
+		return nil, fmt.Errorf("CloudWatch log group is required")
+	}
+	s.region = awsRegion(s.opts.Region)
+	if s.region == "" {
+		return nil, fmt.Errorf("no AWS region, set Region or AWS_REGION")
+	}
+	if s.opts.LogStream == "" {
+		s.opts.LogStream = "{hostname}-{pid}"
+	}
+	s.stream = logfileName(s.opts.LogStream, time.Now())
+	if s.opts.Encoder == nil {
+		s.opts.Encoder = JSONEncoder{}
+	}
+	if s.opts.Client == nil {
+		s.opts.Client = defaultHTTPClient
+	}
+	s.url = s.opts.Endpoint
+	if s.url == "" {
+		s.url = "https://logs." + s.region + ".amazonaws.com/"
+	}
+	s.credentials = s.opts.Credentials
+	if s.credentials == nil {
+		s.credentials = (&awsCredentialChain{client: s.opts.Client}).get
+	}
+
+	batch := s.opts.Batch
+	if batch.Size > cloudWatchMaxEvents {
+		batch.Size = cloudWatchMaxEvents
+	}
+	s.batcher = newBatcher(batch, s.push)
+	return s, nil
+}
+
+type cloudWatchEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+// push sends entries, sorted by time as CloudWatch requires, in as many
+// requests as its limits on count, size and time span make necessary.
+func (s *cloudWatchSink) push(entries []Entry) error {
+	events := make([]cloudWatchEvent, 0, len(entries))
+	for _, entry := range entries {
+		s.buf = s.opts.Encoder.Encode(s.buf[:0], entry)
+		message := truncate(strings.TrimSuffix(string(s.buf), "\n"), cloudWatchMaxEventSize, false)
+		events = append(events, cloudWatchEvent{entry.Time.UnixMilli(), message})
+	}
+	slices.SortStableFunc(events, func(a, b cloudWatchEvent) int {
+		return cmp.Compare(a.Timestamp, b.Timestamp)
+	})
+
+	for len(events) > 0 {
+		n, size := 0, 0
+		for n < len(events) && n < cloudWatchMaxEvents {
+			eventSize := len(events[n].Message) + cloudWatchEventOverhead
+			if size+eventSize > cloudWatchMaxBytes ||
+				time.Duration(events[n].Timestamp-events[0].Timestamp)*time.Millisecond >= cloudWatchMaxSpan {
+				break
+			}
+			size += eventSize
+			n++
+		}
+		err := s.putLogEvents(events[:n])
+		if err != nil {
+			return err
+		}
+		events = events[n:]
+	}
+	return nil
+}
+
+// putLogEvents sends events, creating the group and stream if they don't
+// exist yet and following the sequence token if it is outdated.
+func (s *cloudWatchSink) putLogEvents(events []cloudWatchEvent) error {
+	for attempt := 0; ; attempt++ {
+		req := map[string]interface{}{"logGroupName": s.group, "logStreamName": s.stream, "logEvents": events}
+		if s.token != "" {
+			req["sequenceToken"] = s.token
+		}
+		var resp struct {
+			NextSequenceToken     string `json:"nextSequenceToken"`
+			RejectedLogEventsInfo *struct {
+				TooNewLogEventStartIndex *int `json:"tooNewLogEventStartIndex"`
+				TooOldLogEventEndIndex   *int `json:"tooOldLogEventEndIndex"`
+				ExpiredLogEventEndIndex  *int `json:"expiredLogEventEndIndex"`
+			} `json:"rejectedLogEventsInfo"`
+		}
+		err := s.call("PutLogEvents", req, &resp)
+
+		var apiErr *cloudWatchError
+		switch {
+		case err == nil:
+			s.token = resp.NextSequenceToken
+			if resp.RejectedLogEventsInfo != nil {
+				return permanentError{fmt.Errorf("CloudWatch rejected events too old, too new or expired")}
+			}
+			return nil
+		case attempt >= 2 || !errors.As(err, &apiErr):
+			return err
+		case apiErr.Type == "ResourceNotFoundException":
+			err = s.create()
+			if err != nil {
+				return err
+			}
+		case apiErr.Type == "InvalidSequenceTokenException" || apiErr.Type == "DataAlreadyAcceptedException":
+			s.token = apiErr.ExpectedSequenceToken
+			if apiErr.Type == "DataAlreadyAcceptedException" {
+				return nil
+			}
+		default:
+			return err
+		}
+	}
+}
+
+// create makes the log group, with its retention, and the stream.
+func (s *cloudWatchSink) create() error {
+	var apiErr *cloudWatchError
+	err := s.call("CreateLogGroup", map[string]string{"logGroupName": s.group}, nil)
+	if err == nil && s.opts.RetentionDays > 0 {
+		err = s.call("PutRetentionPolicy", map[string]interface{}{"logGroupName": s.group, "retentionInDays": s.opts.RetentionDays}, nil)
+	}
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.Type == "ResourceAlreadyExistsException") {
+		return fmt.Errorf("creating log group %s: %w", s.group, err)
+	}
+
+	err = s.call("CreateLogStream", map[string]string{"logGroupName": s.group, "logStreamName": s.stream}, nil)
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.Type == "ResourceAlreadyExistsException") {
+		return fmt.Errorf("creating log stream %s: %w", s.stream, err)
+	}
+	s.token = ""
+	return nil
+}
+
+// cloudWatchError is an error response of the CloudWatch Logs API.
+type cloudWatchError struct {
+	Type                  string `json:"__type"`
+	Message               string `json:"message"`
+	ExpectedSequenceToken string `json:"expectedSequenceToken"`
+}
+
+func (e *cloudWatchError) Error() string {
+	return e.Type + ": " + e.Message
+}
+
+// call makes a signed request to the CloudWatch Logs JSON API.
+func (s *cloudWatchSink) call(action string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return permanentError{err}
+	}
+	creds, err := s.credentials()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "Logs_20140328."+action)
+	signAWSRequest(req, body, creds, s.region, "logs", time.Now())
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode == http.StatusOK {
+		if out != nil {
+			return json.Unmarshal(b, out)
+		}
+		return nil
+	}
+	apiErr := &cloudWatchError{}
+	if json.Unmarshal(b, apiErr) != nil || apiErr.Type == "" {
+		apiErr.Type, apiErr.Message = resp.Status, string(b)
+	}
+	// "com.amazonaws.logs#ResourceNotFoundException" in some responses
+	if i := strings.LastIndexByte(apiErr.Type, '#'); i >= 0 {
+		apiErr.Type = apiErr.Type[i+1:]
+	}
+	if resp.StatusCode >= 500 || apiErr.Type == "ThrottlingException" || apiErr.Type == "ServiceUnavailableException" {
+		return apiErr
+	}
+	switch apiErr.Type {
+	case "ResourceNotFoundException", "InvalidSequenceTokenException", "DataAlreadyAcceptedException", "ResourceAlreadyExistsException":
+		return apiErr // handled by the caller
+	}
+	return permanentError{apiErr}
+}
@@ -0,0 +1,80 @@
+// Command gologger-decrypt prints logfiles written with LogfileEncryption.
+//
+//	gologger-decrypt -key key.hex app.log.enc...
+//	gologger-decrypt -identity identity.hex < app.log.enc
+//	gologger-decrypt -keygen
+//
+// Key files hold the 32 byte key in hex, identity files the X25519 private
+// key in hex. -keygen prints a new identity and the public key to give
+// LogfileEncryption.Recipient.
+package main
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/danielwiratman/gologger"
+)
+
+func main() {
+	keyFile := flag.String("key", "", "file with the hex encoded key")
+	identityFile := flag.String("identity", "", "file with the hex encoded X25519 private key")
+	keygen := flag.Bool("keygen", false, "print a new identity and its public key")
+	flag.Parse()
+
+	if *keygen {
+		identity, err := ecdh.X25519().GenerateKey(rand.Reader)
+		check(err)
+		fmt.Printf("identity:   %x\npublic key: %x\n", identity.Bytes(), identity.PublicKey().Bytes())
+		return
+	}
+
+	var key []byte
+	var identity *ecdh.PrivateKey
+	switch {
+	case *keyFile != "":
+		key = readHex(*keyFile)
+	case *identityFile != "":
+		var err error
+		identity, err = ecdh.X25519().NewPrivateKey(readHex(*identityFile))
+		check(err)
+	default:
+		fmt.Fprintln(os.Stderr, "gologger-decrypt: -key or -identity is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if flag.NArg() == 0 {
+		check(gologger.DecryptLogfile(os.Stdout, os.Stdin, key, identity))
+		return
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		check(err)
+		err = gologger.DecryptLogfile(os.Stdout, f, key, identity)
+		f.Close()
+		if err != nil {
+			check(fmt.Errorf("%s: %w", name, err))
+		}
+	}
+}
+
+func readHex(name string) []byte {
+	b, err := os.ReadFile(name)
+	check(err)
+	b, err = hex.DecodeString(strings.TrimSpace(string(b)))
+	check(err)
+	return b
+}
+
+func check(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gologger-decrypt:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,42 @@
+// Command gologger-tui shows logfiles in a terminal viewer, following them
+// like tail -F, with the filters of package gologgertui.
+//
+//	gologger-tui -level info -filter 'logger=="db"' /var/log/app/app.log
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/danielwiratman/gologger"
+	"github.com/danielwiratman/gologger/gologgertui"
+)
+
+func main() {
+	follow := flag.Bool("follow", true, "keep reading what is appended to the files")
+	timeFormat := flag.String("time-format", "", "time layout the files were written with, gologger's default if empty")
+	level := flag.String("level", "", "minimum level to start with, e.g. info")
+	filter := flag.String("filter", "", `filter expression to start with, e.g. level>=warn || logger=="db"`)
+	scrollback := flag.Int("scrollback", 10000, "entries kept to scroll back to")
+	noColor := flag.Bool("no-color", os.Getenv("NO_COLOR") != "", "don't color the levels")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "gologger-tui: no logfiles given")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	src := gologgertui.Files(flag.Args(), &gologger.ReaderOptions{TimeFormat: *timeFormat}, *follow)
+	err := gologgertui.Run(ctx, src, &gologgertui.Options{Scrollback: *scrollback, Level: *level, Filter: *filter, NoColor: *noColor})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
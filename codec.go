@@ -0,0 +1,173 @@
+package gologger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression picks how a network sink compresses what it sends.
+type Compression struct {
+	Codec string // "gzip", "zstd", "snappy" or one of RegisterCodec, none if empty
+	Level int    // of the codec, its default if zero
+}
+
+// ParseCompression parses a codec name with an optional level, e.g. "zstd"
+// or "gzip:9".
+func ParseCompression(s string) (Compression, error) {
+	name, level, found := strings.Cut(strings.TrimSpace(s), ":")
+	c := Compression{Codec: strings.ToLower(name)}
+	if found {
+		n, err := strconv.Atoi(level)
+		if err != nil {
+			return Compression{}, fmt.Errorf("invalid compression level %q", level)
+		}
+		c.Level = n
+	}
+	return c, nil
+}
+
+func (c Compression) String() string {
+	if c.Level == 0 {
+		return c.Codec
+	}
+	return c.Codec + ":" + strconv.Itoa(c.Level)
+}
+
+var (
+	codecsMu sync.Mutex
+	codecs   = map[string]func(w io.Writer, level int) (io.WriteCloser, error){
+		"gzip":   newGzipWriter,
+		"zstd":   newZstdWriter,
+		"snappy": newSnappyWriter,
+	}
+)
+
+// RegisterCodec adds a Compression codec named name, e.g. "br", whose
+// newWriter returns a writer compressing to w at level, the codec's default
+// if zero. The name is what the HTTP sink sends as Content-Encoding. Writers
+// with a Reset(io.Writer) method are reused.
+func RegisterCodec(name string, newWriter func(w io.Writer, level int) (io.WriteCloser, error)) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[strings.ToLower(name)] = newWriter
+}
+
+func newGzipWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+// newZstdWriter takes the levels of the zstd command, 1 to 22.
+func newZstdWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+	if level != 0 {
+		if level < 1 || level > 22 {
+			return nil, fmt.Errorf("invalid zstd level %d, expected 1 to 22", level)
+		}
+		opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	return zstd.NewWriter(w, opts...)
+}
+
+// snappyWriter writes the snappy block format receivers like Loki and
+// Prometheus expect of a "snappy" body, rather than the framed one.
+type snappyWriter struct {
+	w     io.Writer
+	level int
+	buf   bytes.Buffer
+}
+
+// newSnappyWriter takes 1 for fast (the default), 2 for better and 3 for
+// best compression.
+func newSnappyWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level < 0 || level > 3 {
+		return nil, fmt.Errorf("invalid snappy level %d, expected 1 to 3", level)
+	}
+	return &snappyWriter{w: w, level: level}, nil
+}
+
+func (s *snappyWriter) Write(b []byte) (int, error) {
+	return s.buf.Write(b)
+}
+
+func (s *snappyWriter) Reset(w io.Writer) {
+	s.w = w
+	s.buf.Reset()
+}
+
+func (s *snappyWriter) Close() error {
+	var block []byte
+	switch s.level {
+	case 2:
+		block = s2.EncodeSnappyBetter(nil, s.buf.Bytes())
+	case 3:
+		block = s2.EncodeSnappyBest(nil, s.buf.Bytes())
+	default:
+		block = s2.EncodeSnappy(nil, s.buf.Bytes())
+	}
+	s.buf.Reset()
+	_, err := s.w.Write(block)
+	return err
+}
+
+// compressor compresses with a Compression, reusing its writer when the
+// codec allows.
+type compressor struct {
+	c         Compression
+	newWriter func(w io.Writer, level int) (io.WriteCloser, error)
+	w         io.WriteCloser
+}
+
+// newCompressor returns the compressor of c, nil if c is none. Unknown codecs
+// and levels are reported here rather than on the first write.
+func newCompressor(c Compression) (*compressor, error) {
+	c.Codec = strings.ToLower(c.Codec)
+	if c.Codec == "" || c.Codec == "none" {
+		return nil, nil
+	}
+	codecsMu.Lock()
+	newWriter, ok := codecs[c.Codec]
+	var names []string
+	if !ok {
+		for name := range codecs {
+			names = append(names, name)
+		}
+	}
+	codecsMu.Unlock()
+	if !ok {
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown compression codec %q, expected one of %s", c.Codec, strings.Join(names, ", "))
+	}
+
+	w, err := newWriter(io.Discard, c.Level)
+	if err != nil {
+		return nil, fmt.Errorf("compression %s: %w", c, err)
+	}
+	w.Close()
+	return &compressor{c: c, newWriter: newWriter, w: w}, nil
+}
+
+// writer returns a writer compressing to dst, to be closed when done.
+func (z *compressor) writer(dst io.Writer) (io.WriteCloser, error) {
+	if r, ok := z.w.(interface{ Reset(w io.Writer) }); ok {
+		r.Reset(dst)
+		return z.w, nil
+	}
+	w, err := z.newWriter(dst, z.c.Level)
+	if err != nil {
+		return nil, err
+	}
+	z.w = w
+	return w, nil
+}
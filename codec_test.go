@@ -0,0 +1,89 @@
+package gologger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestParseCompression(t *testing.T) {
+	for in, want := range map[string]Compression{
+		"":         {},
+		"zstd":     {Codec: "zstd"},
+		"GZIP:9":   {Codec: "gzip", Level: 9},
+		"snappy:2": {Codec: "snappy", Level: 2},
+	} {
+		got, err := ParseCompression(in)
+		if err != nil || got != want {
+			t.Errorf("ParseCompression(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseCompression("zstd:fast"); err == nil {
+		t.Error("ParseCompression(zstd:fast) succeeded")
+	}
+}
+
+func TestCompressor(t *testing.T) {
+	decode := map[string]func(b []byte) ([]byte, error){
+		"gzip": func(b []byte) ([]byte, error) {
+			r, err := gzip.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			return io.ReadAll(r)
+		},
+		"zstd": func(b []byte) ([]byte, error) {
+			r, err := zstd.NewReader(nil)
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return r.DecodeAll(b, nil)
+		},
+		"snappy": func(b []byte) ([]byte, error) {
+			return s2.Decode(nil, b)
+		},
+	}
+	payload := strings.Repeat(`{"level":"info","message":"request served"}`+"\n", 50)
+
+	for _, c := range []Compression{{Codec: "gzip"}, {Codec: "gzip", Level: 9}, {Codec: "zstd", Level: 19}, {Codec: "snappy", Level: 3}} {
+		z, err := newCompressor(c)
+		if err != nil {
+			t.Fatalf("newCompressor(%v): %v", c, err)
+		}
+		// twice, the second time with the writer reused
+		for i := 0; i < 2; i++ {
+			var buf bytes.Buffer
+			w, err := z.writer(&buf)
+			if err != nil {
+				t.Fatalf("%v: %v", c, err)
+			}
+			io.WriteString(w, payload)
+			if err := w.Close(); err != nil {
+				t.Fatalf("%v: closing: %v", c, err)
+			}
+			if buf.Len() >= len(payload) {
+				t.Errorf("%v: %d bytes compressed to %d", c, len(payload), buf.Len())
+			}
+			got, err := decode[c.Codec](buf.Bytes())
+			if err != nil || string(got) != payload {
+				t.Errorf("%v: decoding gave %d bytes, %v", c, len(got), err)
+			}
+		}
+	}
+
+	if z, err := newCompressor(Compression{}); z != nil || err != nil {
+		t.Errorf("newCompressor of none = %v, %v", z, err)
+	}
+	if _, err := newCompressor(Compression{Codec: "brotli"}); err == nil || !strings.Contains(err.Error(), "gzip, snappy, zstd") {
+		t.Errorf("unknown codec gave %v", err)
+	}
+	if _, err := newCompressor(Compression{Codec: "zstd", Level: 30}); err == nil {
+		t.Error("zstd level 30 was accepted")
+	}
+}
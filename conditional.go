@@ -0,0 +1,122 @@
+package gologger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ERRIf logs err like ERR, and nothing when it is nil, replacing
+//
+//	if err != nil {
+//		l.ERR(err, "closing %s", name)
+//	}
+func (l *Log) ERRIf(err error, prompt string, v ...interface{}) {
+	l.logIf(LOG_ERR, 'E', err, prompt, v)
+}
+
+// WRNIf is ERRIf at warning level.
+func (l *Log) WRNIf(err error, prompt string, v ...interface{}) {
+	l.logIf(LOG_WARNING, 'W', err, prompt, v)
+}
+
+// DBGIf is ERRIf at debug level.
+func (l *Log) DBGIf(err error, prompt string, v ...interface{}) {
+	l.logIf(LOG_DEBUG, 'D', err, prompt, v)
+}
+
+func (l *Log) logIf(priority Priority, level byte, err error, prompt string, v []interface{}) {
+	if err == nil || !l.enabled(priority) {
+		return
+	}
+	message, template, fields := formatMessage(prompt, v)
+	pc, line := l.caller(2)
+	l.outputEntry(pc, Entry{Level: level, Line: line, Message: l.anyErrToString(err, message), Template: template, Fields: fields, Err: err})
+}
+
+// Once returns a logger that logs only the first entry of every call site,
+// e.g. l.Once().WRN("config has no timeout, using 30s") in a loop.
+func (l *Log) Once() *Log {
+	c := l.child(nil)
+	c.every = -1
+	return c
+}
+
+// Every returns a logger that logs the 1st, n+1th, 2n+1th... entry of every
+// call site, to keep an eye on a hot loop without flooding the log.
+func (l *Log) Every(n int) *Log {
+	c := l.child(nil)
+	c.every = int64(max(n, 1))
+	return c
+}
+
+// skipCallSite counts a call from pc and reports whether Once or Every
+// suppress it. Without the caller every entry is logged.
+func (l *Log) skipCallSite(pc uintptr) bool {
+	if l.every == 0 || pc == 0 {
+		return false
+	}
+	v, ok := l.core().callSites.Load(pc)
+	if !ok {
+		v, _ = l.core().callSites.LoadOrStore(pc, new(atomic.Int64))
+	}
+	n := v.(*atomic.Int64).Add(1)
+	if l.every < 0 {
+		return n > 1
+	}
+	return (n-1)%l.every != 0
+}
+
+// cooledKey is what a Cooled logger was created with.
+type cooledKey struct {
+	key string
+	ttl time.Duration
+}
+
+// cooldown is the state of a key of Cooled, shared by all loggers using it.
+type cooldown struct {
+	mu         sync.Mutex
+	until      time.Time // entries before this are demoted
+	suppressed int64     // since the last entry at its own level
+}
+
+// Cooled returns a logger that logs at most one entry per ttl under key at
+// its own level, demoting the rest to debug until the ttl is over, e.g.
+// l.Cooled("db-down", 5*time.Minute).ERR(err, "database unreachable") in a
+// retry loop. The next entry at its own level has the number demoted as the
+// suppressed field. Loggers with the same key share the cooldown.
+func (l *Log) Cooled(key string, ttl time.Duration) *Log {
+	c := l.child(nil)
+	c.cooled = &cooledKey{key, ttl}
+	return c
+}
+
+// cool demotes entry to debug if its key is cooling down, and starts the
+// cooldown otherwise.
+func (l *Log) cool(entry *Entry) {
+	if levelPriority(entry.Level) >= LOG_DEBUG || entry.always || alwaysLogged(entry.Level) {
+		return
+	}
+	core := l.core()
+	v, ok := core.cooldowns.Load(l.cooled.key)
+	if !ok {
+		v, _ = core.cooldowns.LoadOrStore(l.cooled.key, new(cooldown))
+	}
+	c := v.(*cooldown)
+	now := core.now()
+
+	c.mu.Lock()
+	if now.Before(c.until) {
+		c.suppressed++
+		c.mu.Unlock()
+		entry.Level = 'D'
+		return
+	}
+	suppressed := c.suppressed
+	c.suppressed, c.until = 0, now.Add(l.cooled.ttl)
+	c.mu.Unlock()
+
+	if suppressed > 0 {
+		entry.Fields = append(entry.Fields[:len(entry.Fields):len(entry.Fields)], Field{"suppressed", suppressed})
+	}
+}
@@ -0,0 +1,1188 @@
+package gologger
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config declares logger settings in a file or the environment, see Configure
+// and ConfigureFromEnv. Settings left out keep their current value, except
+// Levels, Sampling, Budgets, Schemas and Sinks, which replace what an earlier Apply declared.
+type Config struct {
+	Level  string `json:"level,omitempty"`  // Priority, e.g. "info"
+	Levels string `json:"levels,omitempty"` // overrides as for SetLevels, e.g. "net/*=debug,db=warn"
+
+	LevelSchedule []LevelScheduleConfig `json:"level_schedule,omitempty"` // see SetLevelSchedule, level being the base
+
+	Stdout       *bool  `json:"stdout,omitempty"`
+	StdoutLevels string `json:"stdout_levels,omitempty"` // level tags, e.g. "IWD"
+	Stderr       *bool  `json:"stderr,omitempty"`
+	StderrLevels string `json:"stderr_levels,omitempty"`
+	Pretty       *bool  `json:"pretty,omitempty"`
+	Format       string `json:"format,omitempty"` // of stdout and stderr: "text", "json", "logfmt" or "gcp"
+
+	TimeFormat string `json:"time_format,omitempty"`
+	TimeUTC    *bool  `json:"time_utc,omitempty"`
+	Multiline  string `json:"multiline,omitempty"` // "keep", "escape" or "indent"
+
+	DisableCaller *bool  `json:"disable_caller,omitempty"`
+	CallerPath    string `json:"caller_path,omitempty"` // "func", "short" or "full"
+	GoroutineID   *bool  `json:"goroutine_id,omitempty"`
+	Monotonic     *bool  `json:"monotonic,omitempty"` // see SetMonotonic
+	Mode          string `json:"mode,omitempty"`      // "human" or "machine", see SetMode
+
+	Overflow string `json:"overflow,omitempty"` // "block", "drop_newest" or "drop_oldest"
+	Delivery string `json:"delivery,omitempty"` // "best_effort" or "ordered", see SetDelivery
+	Inline   *bool  `json:"inline,omitempty"`   // see SetInline
+
+	MaxMessageSize *int  `json:"max_message_size,omitempty"`
+	TruncateHash   *bool `json:"truncate_hash,omitempty"`
+
+	Sanitize string `json:"sanitize,omitempty"` // "off", "escape" or "strip", see SetSanitize
+
+	Syslog  *SyslogConfig  `json:"syslog,omitempty"`
+	Logfile *LogfileConfig `json:"logfile,omitempty"`
+
+	// Keyed by level, e.g. "debug"
+	Sampling map[string]SamplingConfig `json:"sampling,omitempty"`
+
+	// Keyed by logger name as for SetBudget, "" or "*"
+	Budgets map[string]BudgetConfig `json:"budgets,omitempty"`
+
+	AutoDebug *AutoDebugConfig `json:"auto_debug,omitempty"` // see SetAutoDebug
+
+	// Keyed by event type, see SetSchema
+	Schemas     map[string]SchemaConfig `json:"schemas,omitempty"`
+	SchemaCheck string                  `json:"schema_check,omitempty"` // "off", "warn" or "panic"
+
+	Filter string `json:"filter,omitempty"` // expression of ParseFilter, e.g. `level>=warn || logger=="db"`
+
+	Sinks []SinkConfig `json:"sinks,omitempty"`
+}
+
+type SyslogConfig struct {
+	Enabled   *bool  `json:"enabled,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+	Network   string `json:"network,omitempty"`  // "" for the local daemon, "udp", "tcp" or "tls"
+	Addr      string `json:"addr,omitempty"`     // host:port of a remote server
+	Facility  string `json:"facility,omitempty"` // e.g. "daemon" or "local0"
+	Levels    string `json:"levels,omitempty"`
+	Multiline string `json:"multiline,omitempty"`
+
+	Structured *bool  `json:"structured,omitempty"` // RFC 5424 structured data, see SyslogStructured
+	SDID       string `json:"sd_id,omitempty"`
+
+	// "text", "cef" or "leef", the last two for SIEMs with the fields below
+	Format   string            `json:"format,omitempty"`
+	Vendor   string            `json:"vendor,omitempty"`
+	Product  string            `json:"product,omitempty"`
+	Version  string            `json:"version,omitempty"`
+	EventID  string            `json:"event_id,omitempty"`  // field holding the signature or event ID
+	FieldMap map[string]string `json:"field_map,omitempty"` // field name to CEF/LEEF key
+	Mapped   bool              `json:"mapped_only,omitempty"`
+}
+
+type LogfileConfig struct {
+	Enabled       *bool  `json:"enabled,omitempty"`
+	Dir           string `json:"dir,omitempty"`
+	Name          string `json:"name,omitempty"`     // template as for LogfileName, it decides the rotation
+	Link          string `json:"link,omitempty"`     // as for LogfileLink
+	Mode          string `json:"mode,omitempty"`     // octal, e.g. "0640"
+	DirMode       string `json:"dir_mode,omitempty"` // octal
+	BufferSize    *int   `json:"buffer_size,omitempty"`
+	FlushInterval string `json:"flush_interval,omitempty"` // e.g. "1s"
+	Levels        string `json:"levels,omitempty"`
+	Format        string `json:"format,omitempty"`    // "text", "json", "logfmt", "gcp" or "msgpack"
+	Checksums     *bool  `json:"checksums,omitempty"` // as for LogfileChecksums
+	MinFree       *int64 `json:"min_free,omitempty"`  // bytes, as for LogfileMinFree
+
+	Sync    *SyncConfig    `json:"sync,omitempty"`
+	Archive *ArchiveConfig `json:"archive,omitempty"` // see LogfileArchive
+}
+
+type ArchiveConfig struct {
+	Type   string `json:"type"`             // "s3", "gcs" or "azure"
+	Bucket string `json:"bucket,omitempty"` // s3 and gcs
+	Region string `json:"region,omitempty"` // s3
+	URL    string `json:"url,omitempty"`    // azure container URL with SAS token, s3 or gcs endpoint
+
+	Key       string `json:"key,omitempty"` // template as for LogfileArchive.Key
+	Compress  bool   `json:"compress,omitempty"`
+	KeepLocal string `json:"keep_local,omitempty"` // e.g. "168h", "-1s" to keep them
+
+	Encryption   string `json:"encryption,omitempty"`    // s3 server-side encryption, "AES256" or "aws:kms", azure encryption scope
+	KMSKey       string `json:"kms_key,omitempty"`       // s3 KMS key ID, gcs KMS key name
+	StorageClass string `json:"storage_class,omitempty"` // s3 and gcs storage class, azure access tier
+}
+
+type SyncConfig struct {
+	EveryWrites int    `json:"every_writes,omitempty"`
+	Interval    string `json:"interval,omitempty"` // e.g. "1s"
+	OnError     bool   `json:"on_error,omitempty"`
+}
+
+type LevelScheduleConfig struct {
+	From  string   `json:"from"` // e.g. "02:00"
+	To    string   `json:"to"`
+	Days  []string `json:"days,omitempty"` // e.g. ["sat", "sun"]
+	Level string   `json:"level"`
+}
+
+type SamplingConfig struct {
+	Initial    int    `json:"initial"`
+	Thereafter int    `json:"thereafter"`
+	Tick       string `json:"tick,omitempty"` // e.g. "1s"
+}
+
+type BudgetConfig struct {
+	Bytes    int64  `json:"bytes"`
+	Interval string `json:"interval,omitempty"` // e.g. "1m"
+}
+
+type AutoDebugConfig struct {
+	Errors   int    `json:"errors"`
+	Window   string `json:"window,omitempty"`   // e.g. "1m"
+	Duration string `json:"duration,omitempty"` // e.g. "2m"
+	Level    string `json:"level,omitempty"`    // e.g. "debug"
+	Context  string `json:"context,omitempty"`  // e.g. "10s"
+}
+
+// SchemaConfig maps field keys to the name of their FieldKind, e.g. "string".
+type SchemaConfig struct {
+	Required map[string]string `json:"required,omitempty"`
+	Optional map[string]string `json:"optional,omitempty"`
+}
+
+// SinkConfig declares a sink added next to the built-in ones.
+type SinkConfig struct {
+	Type    string `json:"type"`              // "file", "gelf", "fluentd", "loki", "elasticsearch", "journald", "http", "cloudwatch", "gcp", "nats", "mqtt" or "sql"
+	Path    string `json:"path,omitempty"`    // file
+	Format  string `json:"format,omitempty"`  // "text", "json", "logfmt", "gcp" or "msgpack", for file and loki. "json", "ndjson" or "msgpack" for http
+	Network string `json:"network,omitempty"` // gelf and fluentd, e.g. "udp" or "tcp"
+	Addr    string `json:"addr,omitempty"`    // gelf, fluentd, nats and mqtt host:port
+	URL     string `json:"url,omitempty"`     // loki push URL, elasticsearch base URL, http endpoint
+	Tag     string `json:"tag,omitempty"`     // fluentd tag, journald identifier
+	Index   string `json:"index,omitempty"`   // elasticsearch
+	Levels  string `json:"levels,omitempty"`  // level tags written to the sink, all if empty
+	Filter  string `json:"filter,omitempty"`  // expression of ParseFilter the entries have to match
+	Dedup   string `json:"dedup,omitempty"`   // window of WithDedup, e.g. "10s"
+
+	Tags       []string `json:"tags,omitempty"`        // only entries with one of these, see Tagged
+	RejectTags []string `json:"reject_tags,omitempty"` // no entries with one of these
+
+	Headers map[string]string `json:"headers,omitempty"` // http
+	Gzip    bool              `json:"gzip,omitempty"`    // http
+	Spool   string            `json:"spool,omitempty"`   // the batch sinks: BatchOptions.SpoolDir
+
+	Compression string `json:"compression,omitempty"` // http and fluentd, e.g. "zstd" or "gzip:9", see ParseCompression
+
+	Group  string `json:"group,omitempty"`  // cloudwatch log group
+	Stream string `json:"stream,omitempty"` // cloudwatch log stream template
+	Region string `json:"region,omitempty"` // cloudwatch
+
+	Project string `json:"project,omitempty"`  // gcp, detected if empty
+	LogName string `json:"log_name,omitempty"` // gcp
+
+	Topic string `json:"topic,omitempty"` // nats subject or mqtt topic template
+	QoS   byte   `json:"qos,omitempty"`   // mqtt
+
+	Driver  string `json:"driver,omitempty"`   // sql, the name a database/sql driver registered, e.g. "sqlite3"
+	DSN     string `json:"dsn,omitempty"`      // sql data source name
+	Table   string `json:"table,omitempty"`    // sql
+	MaxAge  string `json:"max_age,omitempty"`  // sql retention, e.g. "720h"
+	MaxRows int64  `json:"max_rows,omitempty"` // sql retention
+}
+
+var (
+	configFormatsMu sync.Mutex
+	configFormats   = map[string]func(data []byte) (map[string]interface{}, error){}
+)
+
+// RegisterConfigFormat lets Configure read files with extension ext, e.g.
+// ".yaml", decoded by decode into the keys of the JSON form. Importing
+// gologgerconfig registers YAML and TOML.
+func RegisterConfigFormat(ext string, decode func(data []byte) (map[string]interface{}, error)) {
+	configFormatsMu.Lock()
+	defer configFormatsMu.Unlock()
+	configFormats[strings.ToLower(ext)] = decode
+}
+
+// configured is what the last Apply declared, to be undone by the next.
+type configured struct {
+	levels      []levelOverride
+	sampling    map[byte]*Sampling
+	budgets     map[string]*Budget
+	autoDebug   bool
+	schemas     map[string]*Schema
+	filter      bool
+	sinks       []Sink
+	sinkConfigs []SinkConfig // of sinks
+
+	schedule     bool
+	scheduleBase Priority // the priority outside the schedule's windows
+}
+
+// LoadConfig reads a config file, JSON or any format registered with RegisterConfigFormat.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading log config: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".json" {
+		configFormatsMu.Lock()
+		decode, ok := configFormats[ext]
+		configFormatsMu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("log config %s: unsupported format %q, import gologgerconfig for YAML and TOML", path, ext)
+		}
+
+		m, err := decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("log config %s: %w", path, err)
+		}
+		data, err = json.Marshal(m)
+		if err != nil {
+			return nil, fmt.Errorf("log config %s: %w", path, err)
+		}
+	}
+
+	var c Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(&c)
+	if err != nil {
+		return nil, fmt.Errorf("log config %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Configure applies the config file at path to L.
+func Configure(path string) error {
+	c, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	return L.Apply(c)
+}
+
+// ConfigureFromEnv applies the file named by GOLOGGER_CONFIG, if set, then the
+// GOLOGGER_* variables overriding single settings: LEVEL, LEVELS, STDOUT,
+// STDERR, PRETTY, INLINE, DELIVERY, TIME_FORMAT, MODE, SCHEMA_CHECK, SANITIZE,
+// SYSLOG, SYSLOG_ADDR, LOGFILE, LOGFILE_DIR and LOGFILE_NAME.
+func ConfigureFromEnv() error {
+	c := &Config{}
+	if path := os.Getenv("GOLOGGER_CONFIG"); path != "" {
+		var err error
+		c, err = LoadConfig(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.fromEnv()
+	if err != nil {
+		return err
+	}
+	return L.Apply(c)
+}
+
+func (c *Config) fromEnv() error {
+	var errs []error
+	str := func(name string, dst *string) {
+		if v, ok := os.LookupEnv("GOLOGGER_" + name); ok {
+			*dst = v
+		}
+	}
+	boolean := func(name string, dst **bool) {
+		v, ok := os.LookupEnv("GOLOGGER_" + name)
+		if !ok {
+			return
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("GOLOGGER_%s: %w", name, err))
+			return
+		}
+		*dst = &b
+	}
+
+	str("LEVEL", &c.Level)
+	str("LEVELS", &c.Levels)
+	boolean("STDOUT", &c.Stdout)
+	boolean("STDERR", &c.Stderr)
+	boolean("PRETTY", &c.Pretty)
+	boolean("INLINE", &c.Inline)
+	str("DELIVERY", &c.Delivery)
+	str("TIME_FORMAT", &c.TimeFormat)
+	str("MODE", &c.Mode)
+	str("SCHEMA_CHECK", &c.SchemaCheck)
+	str("SANITIZE", &c.Sanitize)
+
+	if c.Syslog == nil {
+		c.Syslog = &SyslogConfig{}
+	}
+	boolean("SYSLOG", &c.Syslog.Enabled)
+	str("SYSLOG_ADDR", &c.Syslog.Addr)
+
+	if c.Logfile == nil {
+		c.Logfile = &LogfileConfig{}
+	}
+	boolean("LOGFILE", &c.Logfile.Enabled)
+	str("LOGFILE_DIR", &c.Logfile.Dir)
+	str("LOGFILE_NAME", &c.Logfile.Name)
+
+	return errors.Join(errs...)
+}
+
+// Apply validates c and applies it to l. Nothing changes if c is invalid.
+func (l *Log) Apply(c *Config) error {
+	l = l.core()
+	l.configMu.Lock()
+	defer l.configMu.Unlock()
+
+	var errs []error
+	fail := func(setting string, err error) {
+		errs = append(errs, fmt.Errorf("%s: %w", setting, err))
+	}
+
+	overrides, err := parseLevelOverrides(c.Levels)
+	if err != nil {
+		fail("levels", err)
+	}
+	var priority Priority
+	if c.Level != "" {
+		priority, err = ParseLevel(c.Level)
+		if err != nil {
+			fail("level", err)
+		}
+	}
+
+	var schedules []LevelSchedule
+	for i, sc := range c.LevelSchedule {
+		schedule, err := sc.schedule()
+		if err != nil {
+			fail(fmt.Sprintf("level_schedule[%d]", i), err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	mode := l.Mode()
+	if c.Mode != "" {
+		mode, err = ParseMode(c.Mode)
+		if err != nil {
+			fail("mode", err)
+		}
+	}
+
+	callerPath := l.CallerPath()
+	switch c.CallerPath {
+	case "":
+	case "func":
+		callerPath = CallerFuncOnly
+	case "short":
+		callerPath = CallerShortPath
+	case "full":
+		callerPath = CallerFullPath
+	default:
+		fail("caller_path", fmt.Errorf("unknown value %q, expected func, short or full", c.CallerPath))
+	}
+
+	delivery := l.Delivery()
+	if c.Delivery != "" {
+		delivery, err = ParseDelivery(c.Delivery)
+		if err != nil {
+			fail("delivery", err)
+		}
+	}
+	overflow := l.OverflowPolicy()
+	switch c.Overflow {
+	case "":
+	case "block":
+		overflow = Block
+	case "drop_newest":
+		overflow = DropNewest
+	case "drop_oldest":
+		overflow = DropOldest
+	default:
+		fail("overflow", fmt.Errorf("unknown policy %q, expected block, drop_newest or drop_oldest", c.Overflow))
+	}
+
+	multiline, err := parseMultiline(c.Multiline, l.Multiline)
+	if err != nil {
+		fail("multiline", err)
+	}
+	syslogMultiline := l.SyslogMultiline
+	if c.Syslog != nil {
+		syslogMultiline, err = parseMultiline(c.Syslog.Multiline, syslogMultiline)
+		if err != nil {
+			fail("syslog.multiline", err)
+		}
+	}
+
+	syslogEncoder := l.SyslogEncoder
+	if c.Syslog != nil && c.Syslog.Format != "" {
+		syslogEncoder, err = c.Syslog.encoder()
+		if err != nil {
+			fail("syslog.format", err)
+		}
+	}
+
+	stdoutEncoder, err := builtinEncoder(c.Format, l.StdoutEncoder)
+	if err != nil {
+		fail("format", err)
+	}
+	logfileEncoder := l.LogfileEncoder
+	if c.Logfile != nil {
+		logfileEncoder, err = builtinEncoder(c.Logfile.Format, logfileEncoder)
+		if err != nil {
+			fail("logfile.format", err)
+		}
+	}
+
+	var facility Priority
+	if c.Syslog != nil && c.Syslog.Facility != "" {
+		facility, err = parseFacility(c.Syslog.Facility)
+		if err != nil {
+			fail("syslog.facility", err)
+		}
+	}
+
+	var logfileMode, logfileDirMode os.FileMode
+	var flushInterval time.Duration
+	var syncPolicy SyncPolicy
+	var archive *LogfileArchive
+	if lf := c.Logfile; lf != nil {
+		logfileMode, err = parseMode(lf.Mode)
+		if err != nil {
+			fail("logfile.mode", err)
+		}
+		logfileDirMode, err = parseMode(lf.DirMode)
+		if err != nil {
+			fail("logfile.dir_mode", err)
+		}
+		if lf.FlushInterval != "" {
+			flushInterval, err = time.ParseDuration(lf.FlushInterval)
+			if err != nil {
+				fail("logfile.flush_interval", err)
+			}
+		}
+		if sc := lf.Sync; sc != nil {
+			syncPolicy = SyncPolicy{EveryWrites: sc.EveryWrites, OnError: sc.OnError}
+			if sc.Interval != "" {
+				syncPolicy.Interval, err = time.ParseDuration(sc.Interval)
+				if err != nil {
+					fail("logfile.sync.interval", err)
+				}
+			}
+		}
+		if lf.Archive != nil {
+			archive, err = lf.Archive.archive()
+			if err != nil {
+				fail("logfile.archive", err)
+			}
+		}
+	}
+
+	sampling := map[byte]*Sampling{}
+	for name, sc := range c.Sampling {
+		p, err := ParseLevel(name)
+		if err != nil {
+			fail("sampling", err)
+			continue
+		}
+		s := &Sampling{Initial: sc.Initial, Thereafter: sc.Thereafter}
+		if sc.Tick != "" {
+			s.Tick, err = time.ParseDuration(sc.Tick)
+			if err != nil {
+				fail("sampling."+name+".tick", err)
+			}
+		}
+		sampling[priorityLevel(p)] = s
+	}
+
+	budgets := map[string]*Budget{}
+	for name, bc := range c.Budgets {
+		if bc.Bytes <= 0 {
+			fail("budgets."+name+".bytes", fmt.Errorf("must be positive, got %d", bc.Bytes))
+		}
+		b := &Budget{Bytes: bc.Bytes}
+		if bc.Interval != "" {
+			b.Interval, err = time.ParseDuration(bc.Interval)
+			if err != nil {
+				fail("budgets."+name+".interval", err)
+			}
+		}
+		budgets[name] = b
+	}
+
+	schemas := map[string]*Schema{}
+	for event, sc := range c.Schemas {
+		kinds := func(setting string, names map[string]string) map[string]FieldKind {
+			kinds := make(map[string]FieldKind, len(names))
+			for key, name := range names {
+				kind, err := ParseFieldKind(name)
+				if err != nil {
+					fail("schemas."+event+"."+setting+"."+key, err)
+				}
+				kinds[key] = kind
+			}
+			return kinds
+		}
+		schemas[event] = &Schema{Required: kinds("required", sc.Required), Optional: kinds("optional", sc.Optional)}
+	}
+	sanitize := l.Sanitize()
+	if c.Sanitize != "" {
+		sanitize, err = ParseSanitize(c.Sanitize)
+		if err != nil {
+			fail("sanitize", err)
+		}
+	}
+
+	schemaCheck := l.SchemaCheck()
+	if c.SchemaCheck != "" {
+		schemaCheck, err = ParseSchemaCheck(c.SchemaCheck)
+		if err != nil {
+			fail("schema_check", err)
+		}
+	}
+
+	var autoDebug *AutoDebug
+	if ac := c.AutoDebug; ac != nil {
+		if ac.Errors <= 0 {
+			fail("auto_debug.errors", fmt.Errorf("must be positive, got %d", ac.Errors))
+		}
+		autoDebug = &AutoDebug{Errors: ac.Errors}
+		duration := func(setting, value string, d *time.Duration) {
+			if value != "" {
+				*d, err = time.ParseDuration(value)
+				if err != nil {
+					fail("auto_debug."+setting, err)
+				}
+			}
+		}
+		duration("window", ac.Window, &autoDebug.Window)
+		duration("duration", ac.Duration, &autoDebug.Duration)
+		duration("context", ac.Context, &autoDebug.Context)
+		if ac.Level != "" {
+			autoDebug.Priority, err = ParseLevel(ac.Level)
+			if err != nil {
+				fail("auto_debug.level", err)
+			}
+		}
+	}
+
+	var filter Filter
+	if c.Filter != "" {
+		filter, err = ParseFilter(c.Filter)
+		if err != nil {
+			fail("filter", err)
+		}
+	}
+
+	// sinks whose config didn't change are kept rather than opened twice,
+	// replaced holds the others of the previous config
+	sinks := make([]Sink, len(c.Sinks))
+	replaced := slices.Clone(l.configured.sinks)
+	for i, sc := range c.Sinks {
+		for j, old := range l.configured.sinkConfigs {
+			if replaced[j] != nil && reflect.DeepEqual(sc, old) {
+				sinks[i], replaced[j] = replaced[j], nil
+				break
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		// a spool directory takes one reader, close the sink being
+		// replaced before the new one opens it
+		for j, old := range l.configured.sinkConfigs {
+			if replaced[j] == nil || old.Spool == "" {
+				continue
+			}
+			for i, sc := range c.Sinks {
+				if sinks[i] == nil && sc.Spool == old.Spool {
+					l.RemoveSink(replaced[j])
+					_ = replaced[j].Close()
+					l.configured.sinks[j], replaced[j] = nil, nil
+					break
+				}
+			}
+		}
+
+		for i, sc := range c.Sinks {
+			if sinks[i] != nil {
+				continue
+			}
+			s, err := sc.newSink()
+			if err != nil {
+				fail(fmt.Sprintf("sinks[%d] (%s)", i, sc.Type), err)
+				continue
+			}
+			sinks[i] = s
+		}
+	}
+
+	if len(errs) > 0 {
+		for _, s := range sinks {
+			if s != nil && !slices.Contains(l.configured.sinks, s) {
+				_ = s.Close()
+			}
+		}
+		return fmt.Errorf("invalid log config: %w", errors.Join(errs...))
+	}
+
+	// first, so the daemon isn't started just for the settings below
+	if c.Inline != nil {
+		l.SetInline(*c.Inline)
+	}
+
+	// the daemon reads most settings, change them between two of its writes
+	done := make(chan struct{})
+	l.send(logMsg{ctrl: func() {
+		defer close(done)
+
+		// the file and syslog sinks keep what they opened until told
+		logfile := [2]string{l.LogfileDir, l.LogfileName}
+		syslog := [5]interface{}{l.SyslogNetwork, l.SyslogAddr, l.SyslogTag, l.SyslogFacility, l.SyslogStructured}
+
+		setBool(&l.SendToStdout, c.Stdout)
+		setLevels(&l.StdoutLevels, c.StdoutLevels)
+		setBool(&l.SendToStderr, c.Stderr)
+		setLevels(&l.StderrLevels, c.StderrLevels)
+		setBool(&l.PrettyConsole, c.Pretty)
+		if c.TimeFormat != "" {
+			l.TimeFormat = c.TimeFormat
+		}
+		setBool(&l.TimeUTC, c.TimeUTC)
+		l.Multiline, l.SyslogMultiline = multiline, syslogMultiline
+		l.SyslogEncoder = syslogEncoder
+		l.StdoutEncoder, l.LogfileEncoder = stdoutEncoder, logfileEncoder
+
+		if sc := c.Syslog; sc != nil {
+			setBool(&l.SendToSyslog, sc.Enabled)
+			if sc.Tag != "" {
+				l.SyslogTag = sc.Tag
+			}
+			if sc.Network != "" {
+				l.SyslogNetwork = sc.Network
+			}
+			if sc.Addr != "" {
+				l.SyslogAddr = sc.Addr
+			}
+			if sc.Facility != "" {
+				l.SyslogFacility = facility
+			}
+			setLevels(&l.SyslogLevels, sc.Levels)
+			setBool(&l.SyslogStructured, sc.Structured)
+			if sc.SDID != "" {
+				l.SyslogSDID = sc.SDID
+			}
+		}
+
+		if lf := c.Logfile; lf != nil {
+			setBool(&l.SendToLogfile, lf.Enabled)
+			if lf.Dir != "" {
+				l.LogfileDir = lf.Dir
+			}
+			if lf.Name != "" {
+				l.LogfileName = lf.Name
+			}
+			if lf.Link != "" {
+				l.LogfileLink = lf.Link
+			}
+			if logfileMode != 0 {
+				l.LogfileMode = logfileMode
+			}
+			if logfileDirMode != 0 {
+				l.LogfileDirMode = logfileDirMode
+			}
+			if lf.BufferSize != nil {
+				l.LogfileBufferSize = *lf.BufferSize
+			}
+			if flushInterval != 0 {
+				l.FlushInterval = flushInterval
+			}
+			setLevels(&l.LogfileLevels, lf.Levels)
+			setBool(&l.LogfileChecksums, lf.Checksums)
+			if lf.MinFree != nil {
+				l.LogfileMinFree = *lf.MinFree
+			}
+			if lf.Sync != nil {
+				l.LogfileSync = syncPolicy
+			}
+			if lf.Archive != nil {
+				l.LogfileArchive = archive
+			}
+		}
+
+		if logfile != [2]string{l.LogfileDir, l.LogfileName} {
+			_ = l.fileSink.(Reopener).Reopen()
+		}
+		if syslog != [5]interface{}{l.SyslogNetwork, l.SyslogAddr, l.SyslogTag, l.SyslogFacility, l.SyslogStructured} {
+			l.syslogSink.(*syslogSink).redial()
+		}
+	}})
+	<-done
+
+	// these are read by the logging goroutines rather than the daemon
+	if c.Level != "" {
+		l.SetPriority(priority)
+	}
+	// the schedule of the previous config goes with it, back to its base
+	// unless this one has a level
+	base := l.CurrentPriority()
+	if c.Level == "" && l.configured.schedule {
+		base = l.configured.scheduleBase
+	}
+	if schedules != nil || l.configured.schedule {
+		_ = l.SetLevelSchedule(base, schedules)
+	}
+	l.configured.schedule, l.configured.scheduleBase = schedules != nil, base
+	if c.DisableCaller != nil {
+		l.SetDisableCaller(*c.DisableCaller)
+	}
+	l.SetCallerPath(callerPath)
+	if c.GoroutineID != nil {
+		l.SetGoroutineID(*c.GoroutineID)
+	}
+	if c.Monotonic != nil {
+		l.SetMonotonic(*c.Monotonic)
+	}
+	l.SetMode(mode)
+	l.SetOverflowPolicy(overflow)
+	l.SetDelivery(delivery)
+	if c.MaxMessageSize != nil || c.TruncateHash != nil {
+		limit, withHash := l.MaxMessageSize(), l.truncateHash.Load()
+		if c.MaxMessageSize != nil {
+			limit = *c.MaxMessageSize
+		}
+		if c.TruncateHash != nil {
+			withHash = *c.TruncateHash
+		}
+		l.SetMaxMessageSize(limit, withHash)
+	}
+	l.SetSanitize(sanitize)
+
+	// overrides set in code stay, those of the previous config go
+	for _, o := range l.configured.levels {
+		l.UnsetLevel(o.pattern)
+	}
+	l.configured.levels = overrides
+	for _, o := range overrides {
+		l.SetLevel(o.pattern, o.priority)
+	}
+
+	for level := range l.configured.sampling {
+		l.SetSampling(level, nil)
+	}
+	l.configured.sampling = sampling
+	for level, s := range sampling {
+		l.SetSampling(level, s)
+	}
+
+	for name := range l.configured.budgets {
+		l.SetBudget(name, nil)
+	}
+	l.configured.budgets = budgets
+	for name, b := range budgets {
+		l.SetBudget(name, b)
+	}
+
+	// like the filter, one set in code stays unless the config has one
+	if autoDebug != nil || l.configured.autoDebug {
+		l.SetAutoDebug(autoDebug)
+	}
+	l.configured.autoDebug = autoDebug != nil
+
+	for event := range l.configured.schemas {
+		l.SetSchema(event, nil)
+	}
+	l.configured.schemas = schemas
+	for event, schema := range schemas {
+		l.SetSchema(event, schema)
+	}
+	l.SetSchemaCheck(schemaCheck)
+
+	// like the levels, a filter set in code stays unless the config has one
+	if filter != nil || l.configured.filter {
+		l.SetFilter(filter)
+	}
+	l.configured.filter = filter != nil
+
+	for _, s := range replaced {
+		if s != nil {
+			l.RemoveSink(s)
+			_ = s.Close()
+		}
+	}
+	for _, s := range sinks {
+		if !slices.Contains(l.configured.sinks, s) {
+			l.AddSink(s)
+		}
+	}
+	l.configured.sinks, l.configured.sinkConfigs = sinks, slices.Clone(c.Sinks)
+	return nil
+}
+
+func (sc LevelScheduleConfig) schedule() (LevelSchedule, error) {
+	s := LevelSchedule{From: sc.From, To: sc.To}
+	if _, err := parseTimeOfDay(sc.From); err != nil {
+		return s, err
+	}
+	if _, err := parseTimeOfDay(sc.To); err != nil {
+		return s, err
+	}
+	for _, name := range sc.Days {
+		day, err := parseWeekday(name)
+		if err != nil {
+			return s, err
+		}
+		s.Days = append(s.Days, day)
+	}
+	var err error
+	s.Level, err = ParseLevel(sc.Level)
+	return s, err
+}
+
+func (ac ArchiveConfig) archive() (*LogfileArchive, error) {
+	a := &LogfileArchive{Key: ac.Key, Compress: ac.Compress}
+	if ac.KeepLocal != "" {
+		var err error
+		a.KeepLocal, err = time.ParseDuration(ac.KeepLocal)
+		if err != nil {
+			return nil, fmt.Errorf("keep_local: %w", err)
+		}
+	}
+	var err error
+	switch ac.Type {
+	case "s3":
+		a.Uploader, err = NewS3Uploader(ac.Bucket, &S3Options{Region: ac.Region, Endpoint: ac.URL,
+			ServerSideEncryption: ac.Encryption, KMSKeyID: ac.KMSKey, StorageClass: ac.StorageClass})
+	case "gcs":
+		a.Uploader, err = NewGCSUploader(ac.Bucket, &GCSOptions{Endpoint: ac.URL, KMSKeyName: ac.KMSKey, StorageClass: ac.StorageClass})
+	case "azure":
+		a.Uploader, err = NewAzureBlobUploader(ac.URL, &AzureBlobOptions{EncryptionScope: ac.Encryption, AccessTier: ac.StorageClass})
+	default:
+		err = fmt.Errorf("unknown type %q, expected s3, gcs or azure", ac.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func setBool(dst *bool, v *bool) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func setLevels(dst *[]byte, tags string) {
+	if tags != "" {
+		*dst = []byte(strings.ToUpper(tags))
+	}
+}
+
+func parseMultiline(s string, current Multiline) (Multiline, error) {
+	switch s {
+	case "":
+		return current, nil
+	case "keep":
+		return MultilineKeep, nil
+	case "escape":
+		return MultilineEscape, nil
+	case "indent":
+		return MultilineIndent, nil
+	default:
+		return 0, fmt.Errorf("unknown value %q, expected keep, escape or indent", s)
+	}
+}
+
+func parseMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid file mode %q, expected octal like 0640", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// priorityLevel is the level tag logging at p, the inverse of levelPriority.
+func priorityLevel(p Priority) byte {
+	switch {
+	case p <= LOG_CRIT:
+		return 'F'
+	case p <= LOG_ERR:
+		return 'E'
+	case p <= LOG_WARNING:
+		return 'W'
+	case p <= LOG_INFO:
+		return 'I'
+	case p <= LOG_DEBUG:
+		return 'D'
+	default:
+		return 'T'
+	}
+}
+
+var facilities = map[string]Priority{
+	"kern": LOG_KERN, "user": LOG_USER, "mail": LOG_MAIL, "daemon": LOG_DAEMON,
+	"auth": LOG_AUTH, "syslog": LOG_SYSLOG, "lpr": LOG_LPR, "news": LOG_NEWS,
+	"uucp": LOG_UUCP, "cron": LOG_CRON, "authpriv": LOG_AUTHPRIV, "ftp": LOG_FTP,
+	"local0": LOG_LOCAL0, "local1": LOG_LOCAL1, "local2": LOG_LOCAL2, "local3": LOG_LOCAL3,
+	"local4": LOG_LOCAL4, "local5": LOG_LOCAL5, "local6": LOG_LOCAL6, "local7": LOG_LOCAL7,
+}
+
+func parseFacility(s string) (Priority, error) {
+	f, ok := facilities[strings.ToLower(s)]
+	if !ok {
+		return 0, fmt.Errorf("unknown syslog facility %q", s)
+	}
+	return f, nil
+}
+
+func (sc SyslogConfig) encoder() (Encoder, error) {
+	switch sc.Format {
+	case "text":
+		return nil, nil
+	case "cef":
+		return CEFEncoder{Vendor: sc.Vendor, Product: sc.Product, Version: sc.Version,
+			EventClassField: sc.EventID, FieldMap: sc.FieldMap, DropUnmapped: sc.Mapped}, nil
+	case "leef":
+		return LEEFEncoder{Vendor: sc.Vendor, Product: sc.Product, Version: sc.Version,
+			EventIDField: sc.EventID, FieldMap: sc.FieldMap, DropUnmapped: sc.Mapped}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected text, cef or leef", sc.Format)
+	}
+}
+
+// builtinEncoder is the encoder of a built-in sink for format, nil for the
+// text format, current if format is empty.
+func builtinEncoder(format string, current Encoder) (Encoder, error) {
+	switch format {
+	case "":
+		return current, nil
+	case "text":
+		return nil, nil
+	}
+	return SinkConfig{Format: format}.encoder()
+}
+
+func (sc SinkConfig) encoder() (Encoder, error) {
+	switch sc.Format {
+	case "", "text":
+		return TextEncoder{}, nil
+	case "json", "ndjson":
+		return JSONEncoder{}, nil
+	case "logfmt":
+		return LogfmtEncoder{}, nil
+	case "gcp":
+		return GCPEncoder{ProjectID: sc.Project}, nil
+	case "msgpack":
+		return MsgpackEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected text, json, logfmt, gcp or msgpack", sc.Format)
+	}
+}
+
+func (sc SinkConfig) newSink() (Sink, error) {
+	encoder, err := sc.encoder()
+	if err != nil {
+		return nil, err
+	}
+
+	batch := BatchOptions{SpoolDir: sc.Spool}
+	compression, err := ParseCompression(sc.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Sink
+	switch sc.Type {
+	case "file":
+		if sc.Path == "" {
+			return nil, fmt.Errorf("path is required")
+		}
+		f, err := os.OpenFile(sc.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+		s = NewWriterSink(f, encoder)
+	case "gelf":
+		s, err = NewGELFSink(sc.Network, sc.Addr, nil)
+	case "fluentd":
+		s, err = NewFluentdSink(sc.Network, sc.Addr, &FluentdOptions{Tag: sc.Tag, Compression: compression, Batch: batch})
+	case "loki":
+		opts := &LokiOptions{Batch: batch}
+		if sc.Format != "" {
+			opts.Encoder = encoder
+		}
+		s, err = NewLokiSink(sc.URL, opts)
+	case "elasticsearch":
+		s, err = NewElasticsearchSink(sc.URL, &ElasticsearchOptions{Index: sc.Index, Batch: batch})
+	case "journald":
+		s, err = NewJournaldSink(sc.Tag)
+	case "http":
+		s, err = NewHTTPSink(sc.URL, &HTTPOptions{Format: sc.Format, Headers: sc.Headers, Gzip: sc.Gzip, Compression: compression, Batch: batch})
+	case "cloudwatch":
+		opts := &CloudWatchOptions{Region: sc.Region, LogStream: sc.Stream, Endpoint: sc.URL, Batch: batch}
+		if sc.Format != "" {
+			opts.Encoder = encoder
+		}
+		s, err = NewCloudWatchSink(sc.Group, opts)
+	case "nats":
+		opts := &NATSOptions{Subject: sc.Topic, Batch: batch}
+		if sc.Format != "" {
+			opts.Encoder = encoder
+		}
+		s, err = NewNATSSink(sc.Addr, opts)
+	case "mqtt":
+		opts := &MQTTOptions{Topic: sc.Topic, QoS: sc.QoS, Batch: batch}
+		if sc.Format != "" {
+			opts.Encoder = encoder
+		}
+		s, err = NewMQTTSink(sc.Addr, opts)
+	case "sql":
+		s, err = sc.newSQLSink(batch)
+	case "gcp":
+		s, err = NewGCPSink(&GCPOptions{ProjectID: sc.Project, LogName: sc.LogName, Endpoint: sc.URL, Batch: batch})
+	default:
+		return nil, fmt.Errorf("unknown sink type %q", sc.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if sc.Levels != "" {
+		s = &LeveledSink{Sink: s, Levels: []byte(strings.ToUpper(sc.Levels))}
+	}
+	if sc.Filter != "" {
+		f, err := ParseFilter(sc.Filter)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s = WithFilter(s, f)
+	}
+	if len(sc.Tags) > 0 {
+		s = AcceptTags(s, sc.Tags...)
+	}
+	if len(sc.RejectTags) > 0 {
+		s = RejectTags(s, sc.RejectTags...)
+	}
+	if sc.Dedup != "" {
+		window, err := time.ParseDuration(sc.Dedup)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("dedup: %w", err)
+		}
+		s = WithDedup(s, window)
+	}
+	return s, nil
+}
+
+func (sc SinkConfig) newSQLSink(batch BatchOptions) (Sink, error) {
+	opts := &SQLOptions{Table: sc.Table, Dialect: sqlDialect(sc.Driver), MaxRows: sc.MaxRows, Batch: batch}
+	if sc.MaxAge != "" {
+		var err error
+		opts.MaxAge, err = time.ParseDuration(sc.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("max_age: %w", err)
+		}
+	}
+	db, err := sql.Open(sc.Driver, sc.DSN)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewSQLSink(db, opts)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	s.(*sqlSink).closeDB = true
+	return s, nil
+}
+
+// WatchConfig applies the config file at path whenever it changes, checked
+// every interval (2s if zero), and whenever one of sigs arrives, e.g.
+// syscall.SIGHUP. An invalid file is logged and the running config kept.
+// Call the returned function to stop.
+func (l *Log) WatchConfig(path string, interval time.Duration, sigs ...os.Signal) (stop func()) {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	c := make(chan os.Signal, 1)
+	if len(sigs) > 0 {
+		signal.Notify(c, sigs...)
+	}
+	quit := make(chan struct{})
+
+	modified := func() (time.Time, int64) {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return fi.ModTime(), fi.Size()
+	}
+	reload := func() {
+		cfg, err := LoadConfig(path)
+		if err == nil {
+			err = l.Apply(cfg)
+		}
+		if err != nil {
+			l.ERR(err, "Error reloading log config, keeping the current one")
+			return
+		}
+		l.INF("Reloaded log config %s", path)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		mtime, size := modified()
+		for {
+			select {
+			case <-ticker.C:
+				m, s := modified()
+				if m.Equal(mtime) && s == size {
+					continue
+				}
+				mtime, size = m, s
+				if s >= 0 {
+					reload()
+				}
+			case <-c:
+				mtime, size = modified()
+				reload()
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(quit)
+		})
+	}
+}
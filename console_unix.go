@@ -0,0 +1,20 @@
+//go:build !windows
+
+package gologger
+
+import "os"
+
+// enableColor reports whether f is a terminal, which all render the
+// ConsoleEncoder's escape sequences.
+func enableColor(f *os.File) bool {
+	return isTerminal(f)
+}
+
+// isTerminal reports whether f is a character device, i.e. not redirected to a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
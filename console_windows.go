@@ -0,0 +1,24 @@
+package gologger
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableColor reports whether f is a console that renders the ConsoleEncoder's
+// escape sequences, turning on their processing, off by default before
+// Windows Terminal. Consoles too old for it get no colors. Text needs no
+// help: os.File writes to a console as UTF-16, so non-ASCII messages render
+// whatever the code page.
+func enableColor(f *os.File) bool {
+	handle := windows.Handle(f.Fd())
+	var mode uint32
+	if windows.GetConsoleMode(handle, &mode) != nil {
+		return false // redirected to a file or pipe
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+		return true
+	}
+	return windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING) == nil
+}
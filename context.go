@@ -0,0 +1,99 @@
+package gologger
+
+import (
+	"context"
+	"sort"
+)
+
+type loggerCtxKey struct{}
+type fieldsCtxKey struct{}
+
+// NewContext returns a copy of ctx carrying l, to be picked up again by FromContext.
+func NewContext(ctx context.Context, l *Log) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, l)
+}
+
+// ContextWithFields returns a copy of ctx with key, value pairs that get attached
+// to every message logged through FromContext(ctx) or WithContext(ctx).
+func ContextWithFields(ctx context.Context, keyvals ...interface{}) context.Context {
+	var fields []Field
+	if parent, ok := ctx.Value(fieldsCtxKey{}).([]Field); ok {
+		fields = append(fields, parent...)
+	}
+	fields = append(fields, keyValsToFields(keyvals)...)
+	return context.WithValue(ctx, fieldsCtxKey{}, fields)
+}
+
+// FromContext returns the logger stored in ctx (L if there is none) with the
+// request-scoped fields of ctx attached.
+func FromContext(ctx context.Context) *Log {
+	l, ok := ctx.Value(loggerCtxKey{}).(*Log)
+	if !ok || l == nil {
+		l = L
+	}
+	return l.WithContext(ctx)
+}
+
+// WithContext returns a child logger with the request-scoped fields of ctx attached.
+func (l *Log) WithContext(ctx context.Context) *Log {
+	fields := l.contextFields(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.child(fields)
+}
+
+func (l *Log) contextFields(ctx context.Context) []Field {
+	var fields []Field
+	if id := CorrelationID(ctx); id != "" {
+		fields = append(fields, Field{"correlation_id", id})
+	}
+
+	// values stored under keys the application already uses, e.g. a request ID from its middleware
+	keys := l.core().ContextKeys
+	if len(keys) > 0 {
+		var extracted []Field
+		for key, name := range keys {
+			if v := ctx.Value(key); v != nil {
+				extracted = append(extracted, Field{name, v})
+			}
+		}
+		sort.Slice(extracted, func(i, j int) bool { return extracted[i].Key < extracted[j].Key })
+		fields = append(fields, extracted...)
+	}
+
+	if ctxFields, ok := ctx.Value(fieldsCtxKey{}).([]Field); ok {
+		fields = append(fields, ctxFields...)
+	}
+
+	for _, extract := range l.core().contextExtractors() {
+		fields = append(fields, extract(ctx)...)
+	}
+
+	// a logger taken from the same context may already carry them
+	deduped := fields[:0]
+	for _, f := range fields {
+		if !hasField(l.fields, f.Key) {
+			deduped = append(deduped, f)
+		}
+	}
+	return deduped
+}
+
+// AddContextExtractor registers fn to derive fields from the contexts passed to
+// WithContext and FromContext, e.g. the IDs of the active trace span.
+func (l *Log) AddContextExtractor(fn func(ctx context.Context) []Field) {
+	l = l.core()
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+
+	extractors := make([]func(ctx context.Context) []Field, 0, len(l.extractors)+1)
+	extractors = append(extractors, l.extractors...)
+	l.extractors = append(extractors, fn)
+}
+
+func (l *Log) contextExtractors() []func(ctx context.Context) []Field {
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+	return l.extractors
+}
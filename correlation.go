@@ -0,0 +1,108 @@
+package gologger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// The headers correlation IDs travel in between services.
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceparentHeader = "traceparent" // W3C Trace Context, its trace ID serves as one
+)
+
+type correlationCtxKey struct{}
+
+// NewCorrelationID returns a random ID of 32 hex digits, the form of a W3C
+// trace ID, so it can be sent on in a traceparent header as well.
+func NewCorrelationID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ContextWithCorrelationID returns a copy of ctx carrying id, which every entry
+// logged through FromContext(ctx) or WithContext(ctx) gets as correlation_id,
+// without passing it down every function that logs.
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationCtxKey{}, id)
+}
+
+// CorrelationID returns the ID stored by ContextWithCorrelationID, empty if there is none.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationCtxKey{}).(string)
+	return id
+}
+
+// CorrelationIDFromHeader returns the ID of the X-Request-ID header, else the
+// trace ID of the traceparent header, empty if neither has one.
+func CorrelationIDFromHeader(h http.Header) string {
+	if id := strings.TrimSpace(h.Get(RequestIDHeader)); id != "" {
+		return id
+	}
+	return traceparentID(h.Get(TraceparentHeader))
+}
+
+// InjectCorrelationID sets the X-Request-ID header to the correlation ID of ctx,
+// for a call to another service, and a traceparent header if there is none and
+// the ID is a trace ID, e.g. one of NewCorrelationID.
+func InjectCorrelationID(ctx context.Context, h http.Header) {
+	id := CorrelationID(ctx)
+	if id == "" {
+		return
+	}
+	h.Set(RequestIDHeader, id)
+	if h.Get(TraceparentHeader) == "" && isTraceID(id) {
+		var span [8]byte
+		_, _ = rand.Read(span[:])
+		h.Set(TraceparentHeader, "00-"+id+"-"+hex.EncodeToString(span[:])+"-01")
+	}
+}
+
+// CorrelationTransport returns a RoundTripper injecting the correlation ID of
+// each request's context into its headers, http.DefaultTransport if base is nil.
+func CorrelationTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return correlationTransport{base}
+}
+
+type correlationTransport struct {
+	base http.RoundTripper
+}
+
+func (t correlationTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if CorrelationID(r.Context()) != "" {
+		// a RoundTripper must not modify the request
+		r = r.Clone(r.Context())
+		InjectCorrelationID(r.Context(), r.Header)
+	}
+	return t.base.RoundTrip(r)
+}
+
+// traceparentID returns the trace ID of a traceparent header value,
+// "00-<trace id>-<parent id>-<flags>", empty if it is invalid.
+func traceparentID(v string) string {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || !isTraceID(parts[1]) {
+		return ""
+	}
+	return parts[1]
+}
+
+// isTraceID reports whether id is 32 lowercase hex digits, not all zero.
+func isTraceID(id string) bool {
+	if len(id) != 32 || strings.Count(id, "0") == 32 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if c := id[i]; !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
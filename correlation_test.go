@@ -0,0 +1,62 @@
+package gologger
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestCorrelationIDFromHeader(t *testing.T) {
+	tests := []struct {
+		requestID, traceparent, want string
+	}{
+		{"", "", ""},
+		{"abc-123", "", "abc-123"},
+		{"abc-123", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "abc-123"},
+		{"", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", ""},
+		{"", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ""},
+		{"", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", ""},
+		{"", "garbage", ""},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.requestID != "" {
+			h.Set(RequestIDHeader, tt.requestID)
+		}
+		if tt.traceparent != "" {
+			h.Set(TraceparentHeader, tt.traceparent)
+		}
+		if got := CorrelationIDFromHeader(h); got != tt.want {
+			t.Errorf("CorrelationIDFromHeader(%q, %q) = %q, want %q", tt.requestID, tt.traceparent, got, tt.want)
+		}
+	}
+}
+
+func TestInjectCorrelationID(t *testing.T) {
+	id := NewCorrelationID()
+	if !isTraceID(id) {
+		t.Fatalf("NewCorrelationID() = %q, not a trace ID", id)
+	}
+
+	h := http.Header{}
+	InjectCorrelationID(context.Background(), h)
+	if len(h) != 0 {
+		t.Errorf("without an ID got headers %v", h)
+	}
+
+	InjectCorrelationID(ContextWithCorrelationID(context.Background(), id), h)
+	if got := h.Get(RequestIDHeader); got != id {
+		t.Errorf("X-Request-ID = %q, want %q", got, id)
+	}
+	h.Del(RequestIDHeader)
+	if got := CorrelationIDFromHeader(h); got != id {
+		t.Errorf("traceparent %q carries %q, want %q", h.Get(TraceparentHeader), got, id)
+	}
+
+	h = http.Header{}
+	InjectCorrelationID(ContextWithCorrelationID(context.Background(), "req-1"), h)
+	if h.Get(TraceparentHeader) != "" {
+		t.Errorf("traceparent set for an ID that is no trace ID: %q", h.Get(TraceparentHeader))
+	}
+}
@@ -0,0 +1,74 @@
+package gologger
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// DebugLogsPath is where RegisterDebugHandlers mounts DebugLogsHandler, next
+// to net/http/pprof's /debug/pprof.
+const DebugLogsPath = "/debug/logs"
+
+// Entries DebugLogsHandler shows without an n parameter.
+const debugLogsDefault = 100
+
+// RegisterDebugHandlers mounts DebugLogsHandler at /debug/logs and
+// HTTPLogStream at /debug/logs/stream on mux, http.DefaultServeMux if nil, as
+// importing net/http/pprof does. Unless KeepRecent was called already, it
+// keeps the last 1000 entries at the current Priority or more severe for
+// them, so nothing below it is formatted just for the handlers; call
+// KeepRecent first for more. Entries are served as a Redactor or Scrubber
+// hook masks them. Serve mux to localhost or behind authentication only.
+func (l *Log) RegisterDebugHandlers(mux *http.ServeMux) {
+	l = l.core()
+	if mux == nil {
+		mux = http.DefaultServeMux
+	}
+	if l.recent.Load() == nil {
+		l.KeepRecent(1000, l.CurrentPriority())
+	}
+	mux.Handle(DebugLogsPath, l.DebugLogsHandler())
+	mux.Handle(DebugLogsPath+"/stream", l.HTTPLogStream())
+}
+
+// DebugLogsHandler serves the last entries kept by KeepRecent as plain text,
+// newest last, for a quick look with a browser or curl. The query narrows
+// them down as for HTTPLogStream: level=warn for that severity and more,
+// filter= an expression of ParseFilter, n= at most that many entries, 100 if
+// not given.
+func (l *Log) DebugLogsHandler() http.Handler {
+	l = l.core()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		filter, err := logStreamFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		buffer := l.recent.Load()
+		if buffer == nil {
+			fmt.Fprintln(w, "# no entries kept, see KeepRecent")
+			return
+		}
+		entries := l.recentEntries(r, filter)
+		if r.FormValue("n") == "" && len(entries) > debugLogsDefault {
+			entries = entries[len(entries)-debugLogsDefault:]
+		}
+
+		buf := fmt.Appendf(nil, "# %d entries, of up to %d kept at %s or more severe, newest last\n",
+			len(entries), len(buffer.entries), priorityName(buffer.priority))
+		encoder := TextEncoder{TimeFormat: "2006-01-02 15:04:05.000", UTC: l.TimeUTC}
+		for _, entry := range entries {
+			buf = encoder.Encode(buf, entry)
+		}
+		w.Write(buf)
+	})
+}
@@ -0,0 +1,71 @@
+package gologger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugLogsHandler(t *testing.T) {
+	l, _ := newTestLog(t)
+	get := func(query string) string {
+		w := httptest.NewRecorder()
+		l.DebugLogsHandler().ServeHTTP(w, httptest.NewRequest("GET", DebugLogsPath+query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", query, w.Code)
+		}
+		return w.Body.String()
+	}
+
+	if got := get(""); !strings.Contains(got, "no entries kept") {
+		t.Errorf("without KeepRecent got %q", got)
+	}
+
+	l.KeepRecent(10, LOG_DEBUG)
+	l.DBG("d1")
+	l.INF("i1")
+	l.Named("db").WRN("w1")
+	l.AddHook(NewRedactor())
+	l.INF("token Bearer abc.def.ghi")
+
+	for _, tt := range []struct {
+		query      string
+		want, skip []string
+	}{
+		{"", []string{"d1", "i1", "w1", "[REDACTED]"}, []string{"abc.def.ghi"}},
+		{"?level=info", []string{"i1", "w1"}, []string{"d1"}},
+		{"?n=1", []string{"[REDACTED]"}, []string{"w1"}},
+		{`?filter=logger=="db"`, []string{"w1"}, []string{"d1", "i1"}},
+	} {
+		got := get(tt.query)
+		for _, s := range tt.want {
+			if !strings.Contains(got, s) {
+				t.Errorf("%s: %q missing from %q", tt.query, s, got)
+			}
+		}
+		for _, s := range tt.skip {
+			if strings.Contains(got, s) {
+				t.Errorf("%s: %q in %q", tt.query, s, got)
+			}
+		}
+	}
+
+	w := httptest.NewRecorder()
+	l.DebugLogsHandler().ServeHTTP(w, httptest.NewRequest("GET", DebugLogsPath+"?level=loud", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("invalid level: status %d, want 400", w.Code)
+	}
+}
+
+func TestRegisterDebugHandlersKeepsPriority(t *testing.T) {
+	l, _ := newTestLog(t)
+	l.SetPriority(LOG_INFO)
+	l.RegisterDebugHandlers(http.NewServeMux())
+	if r := l.recent.Load(); r == nil || r.priority != LOG_INFO {
+		t.Fatal("recent entries not kept at the priority")
+	}
+	if l.Enabled('D') {
+		t.Error("debug entries enabled by the debug handlers")
+	}
+}
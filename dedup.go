@@ -0,0 +1,119 @@
+package gologger
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// WithDedup returns a sink collapsing repeats of an entry written to s within
+// window, like syslogd: the first is written, the others are counted and
+// reported by one "last message repeated N times" entry, with the count in
+// the repeated field, once a different entry comes or the window is over.
+// Entries are the same when their level, logger and message are.
+func WithDedup(s Sink, window time.Duration) Sink {
+	return &dedupSink{Sink: s, window: window}
+}
+
+type dedupSink struct {
+	Sink
+	window time.Duration
+
+	last    Entry // the entry being repeated
+	since   time.Time
+	repeats int
+	out     []Entry
+}
+
+func (s *dedupSink) Write(entry Entry) error {
+	s.out = s.dedup(s.out[:0], entry)
+	return s.write(s.out)
+}
+
+func (s *dedupSink) WriteBatch(entries []Entry) error {
+	s.out = s.out[:0]
+	for _, entry := range entries {
+		s.out = s.dedup(s.out, entry)
+	}
+	return s.write(s.out)
+}
+
+// dedup appends what to write of entry to out.
+func (s *dedupSink) dedup(out []Entry, entry Entry) []Entry {
+	if !s.since.IsZero() && entry.Level == s.last.Level && entry.Logger == s.last.Logger &&
+		entry.Message == s.last.Message && entry.Time.Sub(s.since) < s.window {
+		s.last = entry
+		s.repeats++
+		return out
+	}
+	out = s.appendRepeats(out)
+	s.last, s.since = entry, entry.Time
+	return append(out, entry)
+}
+
+// appendRepeats appends the summary of the repeats counted so far.
+func (s *dedupSink) appendRepeats(out []Entry) []Entry {
+	if s.repeats == 0 {
+		return out
+	}
+	summary := s.last
+	summary.Message = "last message repeated " + strconv.Itoa(s.repeats) + " times"
+	summary.Fields = []Field{{"repeated", s.repeats}}
+	summary.Stack, summary.Err = "", nil
+	s.repeats = 0
+	return append(out, summary)
+}
+
+func (s *dedupSink) write(entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if bw, ok := s.Sink.(BatchWriter); ok {
+		return bw.WriteBatch(entries)
+	}
+	var errs []error
+	for _, entry := range entries {
+		errs = append(errs, s.Sink.Write(entry))
+	}
+	return errors.Join(errs...)
+}
+
+// flushRepeats writes the summary once the window is over.
+func (s *dedupSink) flushRepeats(force bool) error {
+	if s.repeats == 0 || (!force && time.Since(s.since) < s.window) {
+		return nil
+	}
+	s.since = time.Time{}
+	return s.write(s.appendRepeats(s.out[:0]))
+}
+
+func (s *dedupSink) Flush() error {
+	err := s.flushRepeats(false)
+	if flusher, ok := s.Sink.(Flusher); ok {
+		err = errors.Join(err, flusher.Flush())
+	}
+	return err
+}
+
+func (s *dedupSink) startFlush() error {
+	return errors.Join(s.flushRepeats(false), startFlush(s.Sink))
+}
+
+func (s *dedupSink) Sync() error {
+	err := s.flushRepeats(true)
+	if syncer, ok := s.Sink.(Syncer); ok {
+		err = errors.Join(err, syncer.Sync())
+	}
+	return err
+}
+
+func (s *dedupSink) Reopen() error {
+	if reopener, ok := s.Sink.(Reopener); ok {
+		return reopener.Reopen()
+	}
+	return nil
+}
+
+func (s *dedupSink) Close() error {
+	return errors.Join(s.flushRepeats(true), s.Sink.Close())
+}
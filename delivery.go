@@ -0,0 +1,46 @@
+package gologger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Delivery is the order entries reach the sinks in, see SetDelivery.
+type Delivery int
+
+const (
+	BestEffort Delivery = iota // reordered where it helps throughput (default)
+	Ordered                    // the entries of a goroutine reach every sink in the order logged
+)
+
+// ParseDelivery parses "best_effort" or "ordered".
+func ParseDelivery(s string) (Delivery, error) {
+	switch strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), "-", "_") {
+	case "best_effort":
+		return BestEffort, nil
+	case "ordered":
+		return Ordered, nil
+	default:
+		return 0, fmt.Errorf("unknown delivery %q, expected best_effort or ordered", s)
+	}
+}
+
+// SetDelivery sets the order entries are written to the sinks in. Ordered
+// makes sure each sink's Write sees the entries of a goroutine in the order
+// they were logged, across file rotation and reconnects, as an audit trail
+// needs: errors don't take the priority lane past earlier entries, and
+// SetShards is ignored, every entry going through the one channel. Under
+// BestEffort both apply, and shards may be merged out of order when the
+// daemon falls far behind. Entries of different goroutines are in no
+// particular order either way. What the daemon has queued is written first.
+func (l *Log) SetDelivery(d Delivery) {
+	l = l.core()
+	if Delivery(l.delivery.Swap(int32(d))) != d && l.started.Load() && !l.inline.Load() {
+		l.Flush()
+	}
+}
+
+// Delivery returns what SetDelivery set.
+func (l *Log) Delivery() Delivery {
+	return Delivery(l.core().delivery.Load())
+}
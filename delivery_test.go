@@ -0,0 +1,82 @@
+package gologger
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestParseDelivery(t *testing.T) {
+	for in, want := range map[string]Delivery{
+		"ordered":     Ordered,
+		"best_effort": BestEffort,
+		"Best-Effort": BestEffort,
+	} {
+		got, err := ParseDelivery(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDelivery(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseDelivery("exactly_once"); err == nil {
+		t.Error("ParseDelivery(exactly_once) succeeded")
+	}
+}
+
+// gateSink blocks its first Write until open is closed.
+type gateSink struct {
+	memSink
+	open    chan struct{}
+	waiting chan struct{}
+	once    sync.Once
+}
+
+func newGateSink() *gateSink {
+	return &gateSink{open: make(chan struct{}), waiting: make(chan struct{})}
+}
+
+func (s *gateSink) Write(entry Entry) error {
+	s.once.Do(func() {
+		close(s.waiting)
+		<-s.open
+	})
+	return s.memSink.Write(entry)
+}
+
+func TestOrderedDeliveryCongested(t *testing.T) {
+	l, _ := newTestLog(t)
+	gate := newGateSink()
+	l.AddSink(gate)
+	l.SetShards(4)
+	l.SetDelivery(Ordered)
+
+	l.DBG("0")
+	<-gate.waiting
+	// past half the channel, where errors would take the priority lane
+	for i := 1; i < 900; i++ {
+		if i%10 == 0 {
+			l.ERR(nil, "%d", i)
+		} else {
+			l.DBG("%d", i)
+		}
+	}
+	if len(l.urgent) > 0 {
+		t.Error("Ordered entries took the priority lane")
+	}
+	for _, ch := range *l.shards.Load() {
+		if len(ch) > 0 {
+			t.Fatal("Ordered entries went to the shards")
+		}
+	}
+	close(gate.open)
+	l.Flush()
+
+	messages := gate.messages()
+	if len(messages) != 900 {
+		t.Fatalf("%d entries written, want 900", len(messages))
+	}
+	for i, m := range messages {
+		if m != strconv.Itoa(i) {
+			t.Fatalf("entry %d is %s", i, m)
+		}
+	}
+}
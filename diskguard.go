@@ -0,0 +1,76 @@
+package gologger
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// How often the file sink looks at the free space and its descriptor.
+const fileCheckInterval = 10 * time.Second
+
+// checkHealth reopens the logfile when its descriptor went bad or the file
+// was deleted, and enters or leaves low disk mode by LogfileMinFree.
+func (s *fileSink) checkHealth(now time.Time) {
+	s.checked = now
+
+	if s.file != nil && !s.l.LogfileLock {
+		// with the lock every write checks already
+		_, statErr := s.file.Stat()
+		_, err := os.Stat(s.name)
+		if statErr != nil || os.IsNotExist(err) {
+			s.Reopen()
+			return
+		}
+	}
+
+	if s.part != nil {
+		// the sink splitting the entries watches the disk for all files
+		return
+	}
+	minFree := s.l.LogfileMinFree
+	if minFree <= 0 {
+		s.lowDisk = false
+		return
+	}
+	dir := s.l.LogfileDir
+	if dir == "" {
+		dir = "."
+	}
+	logfileDir := dir
+	free, err := diskFree(dir)
+	for os.IsNotExist(err) && filepath.Dir(dir) != dir {
+		// not created yet, its parent tells the file system
+		dir = filepath.Dir(dir)
+		free, err = diskFree(dir)
+	}
+	if err != nil {
+		return
+	}
+	low := free < uint64(minFree)
+	if low == s.lowDisk {
+		return
+	}
+	s.lowDisk = low
+
+	// logged from another goroutine, the daemon can't queue to itself
+	entry := Entry{Level: 'W', Func: "gologger", Fields: []Field{{"logfile_dir", logfileDir}, {"free_bytes", free}}}
+	if low {
+		entry.Message = "Low disk space, the logfile keeps only warnings and errors"
+	} else {
+		entry.Level = 'I'
+		entry.Message = "Disk space recovered, the logfile keeps every level again"
+	}
+	go s.l.outputEntry(0, entry)
+}
+
+// lowDiskEntries returns the entries low disk mode keeps, at W or more severe.
+func (s *fileSink) lowDiskEntries(entries []Entry) []Entry {
+	s.kept = s.kept[:0]
+	for _, entry := range entries {
+		if levelPriority(entry.Level) <= LOG_WARNING {
+			s.kept = append(s.kept, entry)
+		}
+	}
+	return s.kept
+}
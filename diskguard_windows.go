@@ -0,0 +1,14 @@
+package gologger
+
+import "golang.org/x/sys/windows"
+
+// diskFree returns the bytes available to the user on the volume of path.
+func diskFree(path string) (uint64, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var free uint64
+	err = windows.GetDiskFreeSpaceEx(p, &free, nil, nil)
+	return free, err
+}
@@ -0,0 +1,48 @@
+package gologger
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// Bytes DUMP shows by default, see SetDumpLimit.
+const defaultDumpLimit = 4096
+
+// SetDumpLimit sets how many bytes DUMP shows at most, 4096 by default,
+// negative for all of them.
+func (l *Log) SetDumpLimit(n int) {
+	l.core().dumpLimit.Store(int64(n))
+}
+
+// DUMP logs data at level as a hex and ASCII dump like hexdump -C, below a
+// line with label and the size, e.g. for protocol debugging. The size is in
+// the bytes field too.
+func (l *Log) DUMP(level byte, label string, data []byte) {
+	if !alwaysLogged(level) && !l.enabled(levelPriority(level)) {
+		return
+	}
+	pc, line := l.caller(1)
+
+	limit := int(l.core().dumpLimit.Load())
+	if limit == 0 {
+		limit = defaultDumpLimit
+	}
+	shown := data
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+
+	var b strings.Builder
+	b.WriteString(label)
+	b.WriteString(" (")
+	b.WriteString(strconv.Itoa(len(data)))
+	b.WriteString(" bytes)\n")
+	b.WriteString(strings.TrimSuffix(hex.Dump(shown), "\n"))
+	if len(shown) < len(data) {
+		b.WriteString("\n... ")
+		b.WriteString(strconv.Itoa(len(data) - len(shown)))
+		b.WriteString(" more bytes")
+	}
+	l.output(pc, line, level, b.String(), []Field{{"bytes", len(data)}})
+}
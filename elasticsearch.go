@@ -0,0 +1,154 @@
+package gologger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ElasticsearchOptions configures NewElasticsearchSink, the zero value works.
+type ElasticsearchOptions struct {
+	// Index name template with the placeholders of LogfileName, {date} being
+	// the day of the entry. "{program}-{date}" if empty, lowercased as Elasticsearch requires.
+	Index string
+
+	Username, Password string
+	APIKey             string // base64 encoded id:key, sent as "ApiKey ..."
+
+	Client *http.Client // one with a 30s timeout if nil
+
+	// Set Batch.MaxQueued to bound memory while the cluster is unreachable.
+	Batch BatchOptions
+}
+
+type elasticsearchSink struct {
+	*batcher
+
+	url  string
+	opts ElasticsearchOptions
+	buf  bytes.Buffer
+}
+
+// NewElasticsearchSink returns a sink indexing entries through the _bulk API of
+// the cluster at baseURL, e.g. "http://localhost:9200".
+func NewElasticsearchSink(baseURL string, opts *ElasticsearchOptions) (Sink, error) {
+	s := &elasticsearchSink{url: strings.TrimSuffix(baseURL, "/") + "/_bulk"}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Index == "" {
+		s.opts.Index = "{program}-{date}"
+	}
+	if s.opts.Client == nil {
+		s.opts.Client = defaultHTTPClient
+	}
+
+	_, err := url.Parse(s.url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Elasticsearch URL: %w", err)
+	}
+
+	s.batcher = newBatcher(s.opts.Batch, s.push)
+	return s, nil
+}
+
+func (s *elasticsearchSink) push(entries []Entry) error {
+	s.buf.Reset()
+	var b []byte
+	for _, entry := range entries {
+		b = append(b[:0], `{"create":{"_index":`...)
+		b = appendJSONString(b, strings.ToLower(logfileName(s.opts.Index, entry.Time)))
+		b = append(b, "}}\n"...)
+		b = appendElasticsearchDoc(b, entry)
+		b = append(b, '\n')
+		s.buf.Write(b)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(s.buf.Bytes()))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.opts.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+s.opts.APIKey)
+	} else if s.opts.Username != "" {
+		req.SetBasicAuth(s.opts.Username, s.opts.Password)
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return checkHTTPResponse(resp)
+	}
+	defer resp.Body.Close()
+
+	// a 200 can still hold rejected documents
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return permanentError{fmt.Errorf("reading bulk response: %w", err)}
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	failed := 0
+	var first string
+	for _, item := range result.Items {
+		for _, r := range item {
+			if r.Status >= 300 {
+				failed++
+				if first == "" {
+					first = r.Error.Type + ": " + r.Error.Reason
+				}
+			}
+		}
+	}
+	// retrying the whole batch would duplicate the documents that made it
+	return permanentError{fmt.Errorf("%d of %d documents rejected, first: %s", failed, len(entries), first)}
+}
+
+// appendElasticsearchDoc writes entry with ECS field names, fields at the top level.
+func appendElasticsearchDoc(b []byte, entry Entry) []byte {
+	b = append(b, `{"@timestamp":`...)
+	b = strconv.AppendQuote(b, entry.Time.Format(time.RFC3339Nano))
+	b = append(b, `,"log.level":`...)
+	b = strconv.AppendQuote(b, levelName(entry.Level))
+	if entry.Logger != "" {
+		b = append(b, `,"log.logger":`...)
+		b = appendJSONString(b, entry.Logger)
+	}
+	b = append(b, `,"log.origin.function":`...)
+	b = appendJSONString(b, entry.Func)
+	b = append(b, `,"log.origin.file.line":`...)
+	b = strconv.AppendInt(b, int64(entry.Line), 10)
+	b = append(b, `,"message":`...)
+	b = appendJSONString(b, entry.Message)
+	if entry.Stack != "" {
+		b = append(b, `,"error.stack_trace":`...)
+		b = appendJSONString(b, entry.Stack)
+	}
+	for _, f := range entry.Fields {
+		b = append(b, ',')
+		b = appendJSONString(b, f.Key)
+		b = append(b, ':')
+		b = appendJSONValue(b, f.Value)
+	}
+	return append(b, '}')
+}
@@ -0,0 +1,146 @@
+package gologger
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Encoder renders entries into bytes for sinks that write text.
+type Encoder interface {
+	// Encode appends the encoded entry, including the trailing newline, to buf.
+	Encode(buf []byte, entry Entry) []byte
+}
+
+// TextEncoder is gologger's classic "15:04:05.0000|I|func():line message" format.
+type TextEncoder struct {
+	TimeFormat string // a time layout, TimeEpochMillis or TimeNone, DefaultTimeFormat if empty
+	UTC        bool   // timestamps in UTC instead of local time
+	Multiline  Multiline
+}
+
+func (e TextEncoder) Encode(buf []byte, entry Entry) []byte {
+	buf = appendTime(buf, entry.Time, e.TimeFormat, e.UTC)
+	return entry.appendText(buf, e.Multiline)
+}
+
+// Special values for the TimeFormat of encoders and Log.
+const (
+	DefaultTimeFormat = "15:04:05.0000"
+	TimeEpochMillis   = "epochms" // milliseconds since the Unix epoch
+	TimeNone          = "none"    // no timestamp, e.g. when journald or syslog add their own
+)
+
+func appendTime(buf []byte, t time.Time, format string, utc bool) []byte {
+	switch format {
+	case "":
+		format = DefaultTimeFormat
+	case TimeNone:
+		return buf
+	case TimeEpochMillis:
+		return strconv.AppendInt(buf, t.UnixMilli(), 10)
+	}
+	if utc {
+		t = t.UTC()
+	}
+	return t.AppendFormat(buf, format)
+}
+
+const (
+	colorReset   = "\x1b[0m"
+	colorDim     = "\x1b[2m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+	colorGray    = "\x1b[90m"
+)
+
+func levelColor(level byte) string {
+	switch StandardLevel(level) {
+	case 'F', 'P':
+		return colorMagenta
+	case 'E':
+		return colorRed
+	case 'W':
+		return colorYellow
+	case 'I':
+		return colorGreen
+	case 'D':
+		return colorBlue
+	default:
+		return colorGray
+	}
+}
+
+// ConsoleEncoder is a human friendly format with colored level tags and an
+// aligned caller column. Not safe for concurrent use, give each sink its own.
+type ConsoleEncoder struct {
+	NoColor     bool
+	CallerWidth int // minimum width of the caller column, it grows to fit longer callers
+	TimeFormat  string
+	UTC         bool
+	Multiline   Multiline
+
+	width  int
+	caller []byte
+}
+
+func (c *ConsoleEncoder) Encode(buf []byte, entry Entry) []byte {
+	color := func(code string) {
+		if !c.NoColor {
+			buf = append(buf, code...)
+		}
+	}
+
+	if c.TimeFormat != TimeNone {
+		color(colorDim)
+		buf = appendTime(buf, entry.Time, c.TimeFormat, c.UTC)
+		color(colorReset)
+		buf = append(buf, ' ')
+	}
+
+	color(levelColor(entry.Level))
+	buf = append(buf, entry.Level)
+	color(colorReset)
+	buf = append(buf, ' ')
+
+	c.caller = entry.appendCaller(c.caller[:0])
+	caller := c.caller
+	if c.width < c.CallerWidth {
+		c.width = c.CallerWidth
+	}
+	if len(caller) > c.width {
+		c.width = len(caller)
+	}
+	color(colorGray)
+	buf = append(buf, caller...)
+	color(colorReset)
+	buf = append(buf, strings.Repeat(" ", c.width-len(caller)+1)...)
+
+	if entry.Logger != "" {
+		color(colorBlue)
+		buf = append(buf, entry.Logger...)
+		color(colorReset)
+		buf = append(buf, ": "...)
+	}
+	buf = appendMessage(buf, entry.Message, c.Multiline)
+	for _, f := range entry.textFields() {
+		buf = append(buf, ' ')
+		color(colorCyan)
+		buf = append(buf, f.Key...)
+		buf = append(buf, '=')
+		color(colorReset)
+		buf = appendFieldValue(buf, f.Value)
+	}
+	buf = append(buf, '\n')
+
+	if entry.Stack != "" {
+		color(colorGray)
+		buf = appendStack(buf, entry.Stack)
+		color(colorReset)
+	}
+	return buf
+}
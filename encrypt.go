@@ -0,0 +1,257 @@
+package gologger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// LogfileEncryption encrypts the logfile with AES-256-GCM, for logs holding
+// personal data that mustn't sit on disk in plain text. Set either Key, shared
+// with whoever reads the logs, or Recipient, whose private key only the reader
+// holds, so not even the logging host can read its logs back. Decrypt them
+// with DecryptLogfile or cmd/gologger-decrypt.
+type LogfileEncryption struct {
+	Key       []byte          // 32 random bytes
+	Recipient *ecdh.PublicKey // an X25519 key, see ecdh.X25519
+}
+
+// An encrypted logfile is a series of segments, one per time the file was
+// opened for appending. A segment is a header and the records written then:
+//
+//	header: encryptedLogfileMagic, mode (0 Key, 1 Recipient), 16 byte salt,
+//	        for Recipient the 32 byte ephemeral X25519 public key
+//	record: 4 byte big endian length, AES-GCM sealed batch of lines
+//
+// The segment's AES key is HKDF-SHA256 of the Key or the X25519 shared secret
+// with the salt, the nonce of a record counts up from zero.
+const encryptedLogfileMagic = "gologger enc v1\n"
+
+const (
+	encryptionKey byte = iota
+	encryptionRecipient
+)
+
+// Longest record DecryptLogfile takes, so garbage doesn't make it allocate
+// gigabytes. Also a length can't start like encryptedLogfileMagic.
+const maxEncryptedRecord = 1 << 30
+
+// fileCipher seals what the file sink writes, for one segment.
+type fileCipher struct {
+	aead    cipher.AEAD
+	header  []byte
+	counter uint64
+	sealed  []byte
+}
+
+func newFileCipher(enc *LogfileEncryption) (*fileCipher, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	header := append([]byte(encryptedLogfileMagic), 0)
+
+	var secret, info []byte
+	switch {
+	case enc.Recipient != nil:
+		ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		secret, err = ephemeral.ECDH(enc.Recipient)
+		if err != nil {
+			return nil, err
+		}
+		header[len(header)-1] = encryptionRecipient
+		header = append(header, salt...)
+		header = append(header, ephemeral.PublicKey().Bytes()...)
+		info = recipientInfo(ephemeral.PublicKey(), enc.Recipient)
+	case len(enc.Key) == 32:
+		secret = enc.Key
+		header = append(header, salt...)
+	default:
+		return nil, errors.New("the key must be 32 bytes")
+	}
+
+	aead, err := segmentCipher(secret, salt, info)
+	if err != nil {
+		return nil, err
+	}
+	return &fileCipher{aead: aead, header: header}, nil
+}
+
+func recipientInfo(ephemeral, recipient *ecdh.PublicKey) []byte {
+	return append(ephemeral.Bytes(), recipient.Bytes()...)
+}
+
+// segmentCipher derives the AES key of a segment with HKDF-SHA256.
+func segmentCipher(secret, salt, info []byte) (cipher.AEAD, error) {
+	prk := hmacSHA256(salt, string(secret))
+	key := hmacSHA256(prk, "gologger logfile"+string(info)+"\x01")
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func recordNonce(counter uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], counter)
+	return nonce
+}
+
+// seal returns b as the next record, valid until the next call.
+func (c *fileCipher) seal(b []byte) []byte {
+	c.sealed = binary.BigEndian.AppendUint32(c.sealed[:0], uint32(len(b)+c.aead.Overhead()))
+	c.sealed = c.aead.Seal(c.sealed, recordNonce(c.counter), b, nil)
+	c.counter++
+	return c.sealed
+}
+
+// DecryptLogfile writes the lines of a logfile encrypted with
+// LogfileEncryption to w. Pass the Key it was encrypted with, or identity, the
+// private key of its Recipient. A damaged record, e.g. one cut off by a crash
+// while writing it and followed by the segment of the next run, is reported
+// once everything else is written: decrypting resumes at the next segment.
+func DecryptLogfile(w io.Writer, r io.Reader, key []byte, identity *ecdh.PrivateKey) error {
+	br := bufio.NewReader(r)
+	var aead cipher.AEAD
+	var counter uint64
+	var offset int64
+	var plain []byte
+	var errs []error
+	for {
+		magic, err := br.Peek(len(encryptedLogfileMagic))
+		if len(magic) == 0 && err == io.EOF {
+			return errors.Join(errs...)
+		}
+
+		if string(magic) == encryptedLogfileMagic {
+			var n int
+			aead, n, err = readSegmentHeader(br, key, identity)
+			if err != nil {
+				return errors.Join(append(errs, fmt.Errorf("header at offset %d: %w", offset, err))...)
+			}
+			counter = 0
+			offset += int64(n)
+			continue
+		}
+		if aead == nil {
+			return errors.Join(append(errs, errors.New("not an encrypted logfile"))...)
+		}
+
+		var length [4]byte
+		n, err := io.ReadFull(br, length[:])
+		consumed := length[:n]
+		size := binary.BigEndian.Uint32(length[:])
+		if err == nil && size > maxEncryptedRecord {
+			err = fmt.Errorf("length %d", size)
+		}
+		if err == nil {
+			sealed := make([]byte, size)
+			n, err = io.ReadFull(br, sealed)
+			consumed = append(consumed, sealed[:n]...)
+			if err == nil {
+				plain, err = aead.Open(plain[:0], recordNonce(counter), sealed, nil)
+				if err != nil {
+					err = errModifiedRecord
+				}
+			}
+		}
+		if err == nil {
+			if _, err := w.Write(plain); err != nil {
+				return err
+			}
+			counter++
+			offset += int64(len(consumed))
+			continue
+		}
+
+		// resume at the next segment header, which may be in what was read
+		br = bufio.NewReader(io.MultiReader(bytes.NewReader(consumed[1:]), br))
+		skipped, skipErr := skipToSegment(br)
+		switch {
+		case skipErr == nil:
+			errs = append(errs, fmt.Errorf("record at offset %d damaged (%w), skipped %d bytes to the next segment", offset, err, 1+skipped))
+		case err == io.ErrUnexpectedEOF || err == io.EOF:
+			errs = append(errs, fmt.Errorf("record at offset %d cut off", offset))
+		default:
+			errs = append(errs, fmt.Errorf("record at offset %d: %w", offset, err))
+		}
+		if skipErr != nil {
+			return errors.Join(errs...)
+		}
+		aead = nil
+		offset += 1 + skipped
+	}
+}
+
+var errModifiedRecord = errors.New("modified or moved")
+
+// skipToSegment discards what br holds up to the next segment header,
+// returning how many bytes that was, and io.EOF when there is none.
+func skipToSegment(br *bufio.Reader) (int64, error) {
+	magic := []byte(encryptedLogfileMagic)
+	var skipped int64
+	for {
+		buf, err := br.Peek(br.Size())
+		if i := bytes.Index(buf, magic); i >= 0 {
+			br.Discard(i)
+			return skipped + int64(i), nil
+		}
+		if err != nil {
+			br.Discard(len(buf))
+			return skipped + int64(len(buf)), err
+		}
+		// keep what may be the start of the header
+		n := len(buf) - len(magic) + 1
+		br.Discard(n)
+		skipped += int64(n)
+	}
+}
+
+// readSegmentHeader reads a header and returns the segment's cipher and the header length.
+func readSegmentHeader(br *bufio.Reader, key []byte, identity *ecdh.PrivateKey) (cipher.AEAD, int, error) {
+	fixed := make([]byte, len(encryptedLogfileMagic)+1+16)
+	if _, err := io.ReadFull(br, fixed); err != nil {
+		return nil, 0, err
+	}
+	mode, salt := fixed[len(encryptedLogfileMagic)], fixed[len(encryptedLogfileMagic)+1:]
+
+	switch mode {
+	case encryptionKey:
+		if len(key) != 32 {
+			return nil, 0, errors.New("encrypted with a key, need its 32 bytes")
+		}
+		aead, err := segmentCipher(key, salt, nil)
+		return aead, len(fixed), err
+	case encryptionRecipient:
+		if identity == nil {
+			return nil, 0, errors.New("encrypted to a public key, need its private key")
+		}
+		pub := make([]byte, 32)
+		if _, err := io.ReadFull(br, pub); err != nil {
+			return nil, 0, err
+		}
+		ephemeral, err := ecdh.X25519().NewPublicKey(pub)
+		if err != nil {
+			return nil, 0, err
+		}
+		secret, err := identity.ECDH(ephemeral)
+		if err != nil {
+			return nil, 0, err
+		}
+		aead, err := segmentCipher(secret, salt, recipientInfo(ephemeral, identity.PublicKey()))
+		return aead, len(fixed) + len(pub), err
+	default:
+		return nil, 0, fmt.Errorf("unknown mode %d", mode)
+	}
+}
@@ -0,0 +1,120 @@
+package gologger
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeEncryptedLogfile logs each batch with the file opened afresh and returns the file's content.
+func writeEncryptedLogfile(t *testing.T, enc *LogfileEncryption, batches ...[]string) []byte {
+	t.Helper()
+	dir := t.TempDir()
+	l := &Log{LogfileDir: dir, LogfileName: "app.log", LogfileEncryption: enc, TimeFormat: TimeNone, counters: &counters{}}
+	for _, messages := range batches {
+		s := &fileSink{l: l}
+		var entries []Entry
+		for _, msg := range messages {
+			entries = append(entries, Entry{Time: time.Now(), Level: 'I', Message: msg})
+		}
+		if err := s.WriteBatch(entries); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Write(Entry{Time: time.Now(), Level: 'W', Message: "alone"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+const wantDecrypted = "|I| secret one\n|I| secret two\n|W| alone\n|I| secret three\n|W| alone\n"
+
+func TestDecryptLogfileKey(t *testing.T) {
+	key := make([]byte, 32)
+	rand.Read(key)
+	data := writeEncryptedLogfile(t, &LogfileEncryption{Key: key}, []string{"secret one", "secret two"}, []string{"secret three"})
+	if bytes.Contains(data, []byte("secret")) {
+		t.Fatal("plain text in the encrypted logfile")
+	}
+
+	var out strings.Builder
+	if err := DecryptLogfile(&out, bytes.NewReader(data), key, nil); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != wantDecrypted {
+		t.Errorf("decrypted:\n%s\nwant:\n%s", out.String(), wantDecrypted)
+	}
+
+	wrong := make([]byte, 32)
+	if err := DecryptLogfile(&out, bytes.NewReader(data), wrong, nil); err == nil || !strings.Contains(err.Error(), "modified or moved") {
+		t.Errorf("DecryptLogfile with the wrong key = %v", err)
+	}
+}
+
+func TestDecryptLogfileRecipient(t *testing.T) {
+	identity, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := writeEncryptedLogfile(t, &LogfileEncryption{Recipient: identity.PublicKey()}, []string{"secret one", "secret two"}, []string{"secret three"})
+
+	var out strings.Builder
+	if err := DecryptLogfile(&out, bytes.NewReader(data), nil, identity); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != wantDecrypted {
+		t.Errorf("decrypted:\n%s\nwant:\n%s", out.String(), wantDecrypted)
+	}
+
+	if err := DecryptLogfile(&out, bytes.NewReader(data), make([]byte, 32), nil); err == nil || !strings.Contains(err.Error(), "need its private key") {
+		t.Errorf("DecryptLogfile with a key = %v", err)
+	}
+}
+
+func TestDecryptLogfileDamaged(t *testing.T) {
+	key := make([]byte, 32)
+	data := writeEncryptedLogfile(t, &LogfileEncryption{Key: key}, []string{"secret one", "secret two"})
+
+	var out strings.Builder
+	err := DecryptLogfile(&out, bytes.NewReader(data[:len(data)-3]), key, nil)
+	if err == nil || !strings.Contains(err.Error(), "cut off") {
+		t.Errorf("DecryptLogfile of a cut off file = %v", err)
+	}
+	if want := "|I| secret one\n|I| secret two\n"; out.String() != want {
+		t.Errorf("decrypted before the cut %q, want %q", out.String(), want)
+	}
+
+	modified := bytes.Clone(data)
+	modified[len(modified)-1] ^= 1
+	if err := DecryptLogfile(&out, bytes.NewReader(modified), key, nil); err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Errorf("DecryptLogfile of a modified file = %v", err)
+	}
+
+	// a crash cut the last record short, the next run appended a segment
+	next := writeEncryptedLogfile(t, &LogfileEncryption{Key: key}, []string{"secret three"})
+	appended := append(bytes.Clone(data[:len(data)-3]), next...)
+	out.Reset()
+	err = DecryptLogfile(&out, bytes.NewReader(appended), key, nil)
+	if err == nil || !strings.Contains(err.Error(), "damaged") {
+		t.Errorf("DecryptLogfile of a cut off then appended file = %v", err)
+	}
+	if want := "|I| secret one\n|I| secret two\n|I| secret three\n|W| alone\n"; out.String() != want {
+		t.Errorf("decrypted around the cut %q, want %q", out.String(), want)
+	}
+
+	if err := DecryptLogfile(&out, strings.NewReader("plain text\n"), key, nil); err == nil {
+		t.Error("DecryptLogfile of a plain file succeeded")
+	}
+}
@@ -0,0 +1,220 @@
+package gologger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Enricher provides fields for every entry, like the hostname or where in a
+// cluster the program runs. It is called once.
+type Enricher func() []Field
+
+// enrichment holds the fields of an Enricher once it returned.
+type enrichment struct {
+	fields atomic.Pointer[[]Field]
+}
+
+// Enrich adds the fields of enrichers to every entry, after the metadata
+// fields and before the logger's. Each is called in a goroutine of its own,
+// as some ask a metadata service; entries logged before it returns go
+// without its fields.
+func (l *Log) Enrich(enrichers ...Enricher) {
+	l = l.core()
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+
+	all := make([]*enrichment, 0, len(l.enrichments)+len(enrichers))
+	all = append(all, l.enrichments...)
+	for _, enricher := range enrichers {
+		e := &enrichment{}
+		all = append(all, e)
+		go func(enricher Enricher) {
+			fields := enricher()
+			e.fields.Store(&fields)
+		}(enricher)
+	}
+	l.enrichments = all
+}
+
+func (l *Log) currentEnrichments() []*enrichment {
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+	return l.enrichments
+}
+
+// StaticEnricher provides fields known up front, given as key, value pairs.
+func StaticEnricher(keyvals ...interface{}) Enricher {
+	fields := keyValsToFields(keyvals)
+	return func() []Field { return fields }
+}
+
+// HostnameEnricher adds host, like HostField. Set both and it is added once.
+func HostnameEnricher() Enricher {
+	return func() []Field { return []Field{{"host", hostname}} }
+}
+
+// ContainerEnricher adds container.id when running in a Docker, containerd
+// or CRI-O container, read from /proc.
+func ContainerEnricher() Enricher {
+	return func() []Field {
+		if id := containerID(); id != "" {
+			return []Field{{"container.id", id}}
+		}
+		return nil
+	}
+}
+
+var containerIDPattern = regexp.MustCompile(`(?:docker|containerd|crio|containers|cri-containerd)[-/:]([0-9a-f]{64})`)
+
+func containerID() string {
+	// cgroup v1 names the container, v2 only shows up in the mounts
+	for _, file := range []string{"/proc/self/cgroup", "/proc/self/mountinfo"} {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if m := containerIDPattern.FindSubmatch(b); m != nil {
+			return string(m[1])
+		}
+	}
+	return ""
+}
+
+// KubernetesEnricher adds the pod, namespace, node and container from the
+// environment variables POD_NAME, POD_NAMESPACE, NODE_NAME and
+// CONTAINER_NAME, which the pod spec sets through the downward API. Without
+// them the pod is the hostname and the namespace that of the service account.
+func KubernetesEnricher() Enricher {
+	return func() []Field {
+		if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+			return nil
+		}
+		pod := os.Getenv("POD_NAME")
+		if pod == "" {
+			pod = hostname
+		}
+		namespace := os.Getenv("POD_NAMESPACE")
+		if namespace == "" {
+			b, _ := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+			namespace = strings.TrimSpace(string(b))
+		}
+
+		fields := []Field{{"k8s.pod.name", pod}}
+		if namespace != "" {
+			fields = append(fields, Field{"k8s.namespace.name", namespace})
+		}
+		if node := os.Getenv("NODE_NAME"); node != "" {
+			fields = append(fields, Field{"k8s.node.name", node})
+		}
+		if container := os.Getenv("CONTAINER_NAME"); container != "" {
+			fields = append(fields, Field{"k8s.container.name", container})
+		}
+		return fields
+	}
+}
+
+// CloudEnricher adds cloud.provider, cloud.region, cloud.availability_zone
+// and host.id from the instance metadata of AWS, GCP or Azure, or the
+// environment of Lambda and Cloud Run. Nothing elsewhere.
+func CloudEnricher() Enricher {
+	return func() []Field {
+		client := &http.Client{Timeout: time.Second}
+
+		switch {
+		case os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "":
+			return []Field{{"cloud.provider", "aws"}, {"cloud.region", os.Getenv("AWS_REGION")},
+				{"faas.name", os.Getenv("AWS_LAMBDA_FUNCTION_NAME")}}
+		case os.Getenv("K_SERVICE") != "":
+			region, _ := gcpMetadata(client, "instance/region")
+			return []Field{{"cloud.provider", "gcp"}, {"cloud.region", path.Base(region)},
+				{"faas.name", os.Getenv("K_SERVICE")}}
+		}
+
+		if doc := awsInstanceIdentity(client); doc != nil {
+			return []Field{{"cloud.provider", "aws"}, {"cloud.region", doc.Region},
+				{"cloud.availability_zone", doc.AvailabilityZone}, {"host.id", doc.InstanceID}}
+		}
+		if id, err := gcpMetadata(client, "instance/id"); err == nil {
+			zone, _ := gcpMetadata(client, "instance/zone")
+			zone = path.Base(zone) // from projects/123/zones/europe-west1-b
+			region := zone
+			if i := strings.LastIndexByte(zone, '-'); i > 0 {
+				region = zone[:i]
+			}
+			return []Field{{"cloud.provider", "gcp"}, {"cloud.region", region},
+				{"cloud.availability_zone", zone}, {"host.id", id}}
+		}
+		if compute := azureInstanceMetadata(client); compute != nil {
+			fields := []Field{{"cloud.provider", "azure"}, {"cloud.region", compute.Location}, {"host.id", compute.VMID}}
+			if compute.Zone != "" {
+				fields = append(fields, Field{"cloud.availability_zone", compute.Zone})
+			}
+			return fields
+		}
+		return nil
+	}
+}
+
+type awsIdentityDocument struct {
+	InstanceID       string `json:"instanceId"`
+	Region           string `json:"region"`
+	AvailabilityZone string `json:"availabilityZone"`
+}
+
+// awsInstanceIdentity reads the instance identity document through IMDSv2, nil off EC2.
+func awsInstanceIdentity(client *http.Client) *awsIdentityDocument {
+	const imds = "http://169.254.169.254/latest"
+	req, _ := http.NewRequest(http.MethodPut, imds+"/api/token", nil)
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	token, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, imds+"/dynamic/instance-identity/document", nil)
+	req.Header.Set("X-aws-ec2-metadata-token", string(token))
+	resp, err = client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	var doc awsIdentityDocument
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&doc) != nil {
+		return nil
+	}
+	return &doc
+}
+
+type azureCompute struct {
+	VMID     string `json:"vmId"`
+	Location string `json:"location"`
+	Zone     string `json:"zone"`
+}
+
+// azureInstanceMetadata reads the compute metadata of an Azure VM, nil elsewhere.
+func azureInstanceMetadata(client *http.Client) *azureCompute {
+	req, _ := http.NewRequest(http.MethodGet, "http://169.254.169.254/metadata/instance/compute?api-version=2021-02-01", nil)
+	req.Header.Set("Metadata", "true")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	var compute azureCompute
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&compute) != nil || compute.VMID == "" {
+		return nil
+	}
+	return &compute
+}
@@ -0,0 +1,106 @@
+package gologger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a single log record as handed to sinks.
+type Entry struct {
+	Seq       uint64 // counts up from 1 in the order entries are written
+	Time      time.Time
+	Level     byte
+	Logger    string // name given with Named, empty for the root logger
+	Func      string // empty with SetDisableCaller
+	File      string // with SetCallerPath, e.g. "gologger/main.go"
+	Line      int
+	Message   string
+	Fields    []Field
+	Template  string   // the message before its placeholders were filled in, see Fields
+	MessageID string   // of the MessageCatalog, with LogID
+	Tags      []string // of a Tagged logger, for routing
+	SyslogTag string   // of WithSyslogTag, SyslogTag if empty
+	Stack     string   // captured when the level reaches StackTraceLevel
+	Err       error    // the error passed to ERR or recovered from a panic, already part of Message
+
+	to     []Sink          // of Call.To, the enabled sinks if nil
+	always bool            // of Call.Always
+	done   <-chan struct{} // of TryINF and LogWithTimeout, giving up on a full queue once closed
+}
+
+// Text renders the entry in gologger's line format without the timestamp,
+// e.g. "|I|main.main():12 hello key=value\n".
+func (e Entry) Text() string {
+	return string(e.appendText(nil, MultilineKeep))
+}
+
+func (e Entry) appendText(buf []byte, multiline Multiline) []byte {
+	buf = append(buf, '|', e.Level, '|')
+	buf = e.appendCaller(buf)
+	buf = append(buf, ' ')
+	if e.Logger != "" {
+		buf = append(buf, '[')
+		buf = append(buf, e.Logger...)
+		buf = append(buf, "] "...)
+	}
+	buf = appendMessage(buf, e.Message, multiline)
+	buf = appendFields(buf, e.textFields())
+	if multiline == MultilineEscape && e.Stack != "" {
+		buf = appendStackLine(buf, e.Stack)
+		return append(buf, '\n')
+	}
+	buf = append(buf, '\n')
+	if e.Stack != "" {
+		buf = appendStack(buf, e.Stack)
+	}
+	return buf
+}
+
+// TimestampedText is Text prefixed with the time of day, as written to stdout and the logfile.
+func (e Entry) TimestampedText() string {
+	return e.Time.Format(DefaultTimeFormat) + e.Text()
+}
+
+// appendCaller renders where the entry was logged, "func():line" or with a
+// file "func() file:line", nothing if unknown.
+func (e Entry) appendCaller(buf []byte) []byte {
+	if e.Func == "" && e.Line == 0 {
+		return buf
+	}
+	buf = append(buf, e.Func...)
+	if e.File != "" {
+		buf = append(buf, "() "...)
+		buf = append(buf, e.File...)
+		buf = append(buf, ':')
+	} else {
+		buf = append(buf, "():"...)
+	}
+	return strconv.AppendInt(buf, int64(e.Line), 10)
+}
+
+// CallerPathStyle is how much of the caller's file path entries carry.
+type CallerPathStyle int
+
+const (
+	CallerFuncOnly  CallerPathStyle = iota // just the function, the default
+	CallerShortPath                        // the file and its directory, e.g. "gologger/main.go"
+	CallerFullPath                         // the absolute path
+)
+
+func callerFile(pc uintptr, style CallerPathStyle) string {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	file, _ := fn.FileLine(pc)
+	if style == CallerShortPath {
+		if i := strings.LastIndexByte(file, '/'); i > 0 {
+			if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+				file = file[j+1:]
+			}
+		}
+	}
+	return file
+}
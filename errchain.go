@@ -0,0 +1,91 @@
+package gologger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// StackTracer is implemented by errors that carry the stack they were created
+// with. Errors of github.com/pkg/errors are recognized too, their
+// StackTrace method returning a slice of program counters of its own type.
+type StackTracer interface {
+	StackTrace() []uintptr
+}
+
+// describeError adds the code and fields of the *Error layers of entry.Err,
+// its wrap chain as the error_chain field, when it has more than one layer,
+// and the stack of the innermost error
+// carrying one, unless entry has a stack already.
+func describeError(entry *Entry) {
+	var chain []string
+	var stack []uintptr
+	walkErrors(entry.Err, "", func(err error, parent string) {
+		part := fmt.Sprintf("%T", err)
+		msg := err.Error()
+		// wrapping with %w repeats the message of the cause, only show
+		// what the layer above left out
+		if parent == "" || !strings.Contains(parent, msg) {
+			part += ": " + msg
+		}
+		chain = append(chain, part)
+		if pcs := errorStack(err); pcs != nil {
+			stack = pcs
+		}
+	})
+
+	if fields := codedErrorFields(entry.Err); fields != nil {
+		entry.Fields = append(entry.Fields[:len(entry.Fields):len(entry.Fields)], fields...)
+	}
+	if len(chain) > 1 {
+		entry.Fields = append(entry.Fields[:len(entry.Fields):len(entry.Fields)], Field{"error_chain", strings.Join(chain, " <- ")})
+	}
+	if stack != nil && entry.Stack == "" {
+		entry.Stack = formatStack(stack)
+	}
+}
+
+// walkErrors calls fn for err and the errors it wraps, depth first, with the
+// message of the error wrapping each, "" for err.
+func walkErrors(err error, parent string, fn func(err error, parent string)) {
+	for depth := 0; err != nil && depth < 32; depth++ {
+		fn(err, parent)
+		parent = err.Error()
+		switch e := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walkErrors(inner, parent, fn)
+			}
+			return
+		default:
+			err = errors.Unwrap(err)
+		}
+	}
+}
+
+// errorStack returns the stack err carries, nil without one.
+func errorStack(err error) []uintptr {
+	if st, ok := err.(StackTracer); ok {
+		return st.StackTrace()
+	}
+
+	// pkg/errors: StackTrace() errors.StackTrace, a []Frame of uintptr
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	t := m.Type().Out(0)
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	frames := m.Call(nil)[0]
+	if frames.Len() == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	return pcs
+}
@@ -0,0 +1,70 @@
+package gologger
+
+import "errors"
+
+// Error is an error with a code and fields, made with E. Logged with ERR,
+// its code becomes the error_code field and its fields are added to the
+// entry's, those of outer layers winning.
+type Error struct {
+	Code   string
+	Err    error // the cause, may be nil
+	Fields []Field
+}
+
+// E returns an *Error wrapping err with code and the alternating key, value
+// pairs of keyvals, e.g.
+//
+//	return gologger.E("order.not_found", err, "order", id)
+//
+// errors.Is(err, gologger.E("order.not_found", nil)) then matches it by code,
+// and errors.As gets at it through further wrapping.
+func E(code string, err error, keyvals ...interface{}) error {
+	return &Error{Code: code, Err: err, Fields: keyValsToFields(keyvals)}
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Code
+	}
+	return e.Code + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches an *Error without a cause by its code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Err == nil && t.Code == e.Code
+}
+
+// ErrorCode returns the code of the outermost *Error in err's chain, "" if none.
+func ErrorCode(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// codedErrorFields returns the error_code field and the fields of the *Error
+// layers of err, nil if it has none.
+func codedErrorFields(err error) []Field {
+	var fields []Field
+	walkErrors(err, "", func(err error, parent string) {
+		e, ok := err.(*Error)
+		if !ok {
+			return
+		}
+		if fields == nil {
+			fields = append(fields, Field{"error_code", e.Code})
+		}
+		for _, f := range e.Fields {
+			if !hasField(fields, f.Key) {
+				fields = append(fields, f)
+			}
+		}
+	})
+	return fields
+}
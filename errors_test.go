@@ -0,0 +1,49 @@
+package gologger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	inner := E("db.timeout", io.ErrUnexpectedEOF, "table", "orders", "attempt", 3)
+	err := fmt.Errorf("loading: %w", E("order.load", inner, "order", 42, "attempt", 4))
+
+	if got, want := err.Error(), "loading: order.load: db.timeout: unexpected EOF"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, E("db.timeout", nil)) || !errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, E("other", nil)) {
+		t.Error("errors.Is doesn't match by code and cause")
+	}
+	if code := ErrorCode(err); code != "order.load" {
+		t.Errorf("ErrorCode() = %q", code)
+	}
+
+	entry := Entry{Err: err}
+	describeError(&entry)
+	want := []Field{{"error_code", "order.load"}, {"order", 42}, {"attempt", 4}, {"table", "orders"}}
+	if got := entry.Fields[:len(want)]; !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
+
+func TestAnyErrToString(t *testing.T) {
+	l := &Log{}
+	for _, tt := range []struct {
+		e    interface{}
+		want string
+	}{
+		{nil, "failed"},
+		{io.EOF, "failed err{EOF}"},
+		{"gone", "failed err{gone}"},
+		{byte(3), "failed RC:03"},
+		{42, "failed RC:42"},
+	} {
+		if got := l.anyErrToString(tt.e, "failed"); got != tt.want {
+			t.Errorf("anyErrToString(%v) = %q, want %q", tt.e, got, tt.want)
+		}
+	}
+}
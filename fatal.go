@@ -0,0 +1,30 @@
+package gologger
+
+import "os"
+
+// FTL logs at fatal level, waits for all sinks to be written and exits with status 1.
+func (l *Log) FTL(e interface{}, prompt string, v ...interface{}) {
+	if l.enabled(LOG_CRIT) {
+		message, template, fields := formatMessage(prompt, v)
+		pc, line := l.caller(1)
+		l.outputEntry(pc, Entry{Level: 'F', Line: line, Message: l.anyErrToString(e, message), Template: template, Fields: fields})
+	}
+
+	l.flush(true)
+	l.dumpRecentOnCrash()
+	os.Exit(1)
+}
+
+// PANIC logs at fatal level, waits for all sinks to be written and panics with the message.
+func (l *Log) PANIC(e interface{}, prompt string, v ...interface{}) {
+	message, template, fields := formatMessage(prompt, v)
+	message = l.anyErrToString(e, message)
+	if l.enabled(LOG_CRIT) {
+		pc, line := l.caller(1)
+		l.outputEntry(pc, Entry{Level: 'P', Line: line, Message: message, Template: template, Fields: fields})
+	}
+
+	l.flush(true)
+	l.dumpRecentOnCrash()
+	panic(message)
+}
@@ -0,0 +1,107 @@
+package gologger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// keyValsToFields turns alternating key, value arguments into fields.
+// A trailing key without a value gets "<missing>".
+func keyValsToFields(keyvals []interface{}) []Field {
+	fields := make([]Field, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if i+1 < len(keyvals) {
+			fields = append(fields, Field{key, keyvals[i+1]})
+		} else {
+			fields = append(fields, Field{key, "<missing>"})
+		}
+	}
+	return fields
+}
+
+// core returns the logger owning the daemon, child loggers only carry fields.
+func (l *Log) core() *Log {
+	if l.root != nil {
+		return l.root
+	}
+	return l
+}
+
+func (l *Log) child(fields []Field) *Log {
+	c := &Log{root: l.core(), name: l.name, callerSkip: l.callerSkip, synchronous: l.synchronous, every: l.every, cooled: l.cooled, tags: l.tags, syslogTag: l.syslogTag}
+	c.sampling.Store(l.sampling.Load())
+	c.fields = make([]Field, 0, len(l.fields)+len(fields))
+	c.fields = append(c.fields, l.fields...)
+	c.fields = append(c.fields, fields...)
+	return c
+}
+
+// With returns a child logger that appends the given key, value pairs to every message.
+func (l *Log) With(keyvals ...interface{}) *Log {
+	return l.child(keyValsToFields(keyvals))
+}
+
+// Named returns a child logger whose messages are prefixed with name.
+// Names of nested children are joined with dots, e.g. "http.client".
+func (l *Log) Named(name string) *Log {
+	c := l.child(nil)
+	if l.name != "" {
+		c.name = l.name + "." + name
+	} else {
+		c.name = name
+	}
+	return c
+}
+
+func hasField(fields []Field, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func appendFields(buf []byte, fields []Field) []byte {
+	for _, f := range fields {
+		buf = append(buf, ' ')
+		buf = append(buf, f.Key...)
+		buf = append(buf, '=')
+		buf = appendFieldValue(buf, f.Value)
+	}
+	return buf
+}
+
+// appendFieldValue quotes values that would otherwise be ambiguous in key=value output.
+func appendFieldValue(buf []byte, value interface{}) []byte {
+	// the common types without going through fmt
+	switch v := value.(type) {
+	case string:
+		return appendFieldString(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case float64:
+		return strconv.AppendFloat(buf, v, 'g', -1, 64)
+	}
+	return appendFieldString(buf, fmt.Sprint(value))
+}
+
+func appendFieldString(buf []byte, v string) []byte {
+	if v == "" || strings.ContainsAny(v, " =\"\t\n") {
+		return strconv.AppendQuote(buf, v)
+	}
+	return append(buf, v...)
+}
@@ -0,0 +1,424 @@
+package gologger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const defaultLogfileName = "{program}_{date}.log"
+
+// fileSink writes to the file named by LogfileDir and LogfileName, starting a new file every day.
+type fileSink struct {
+	l    *Log
+	file *os.File
+	name string
+	buf  *bufio.Writer  // nil when LogfileBufferSize is 0 or LogfileLock is set
+	enc  *fileCipher    // nil unless LogfileEncryption is set
+	sum  *logfileDigest // nil unless LogfileChecksums is set
+	date int
+
+	encoded []byte
+
+	unsynced int // entries written since the last fsync
+	lastSync time.Time
+
+	checked time.Time // see checkHealth
+	lowDisk bool      // below LogfileMinFree
+	kept    []Entry
+
+	part  *logfilePart              // of a file of a split logfile, nil for the sink splitting them
+	parts map[logfilePart]*fileSink // see writeSplit
+	used  time.Time                 // of a part, when it was last written
+}
+
+// SyncPolicy decides when the logfile is committed to disk with fsync, on top
+// of FTL and PANIC. The zero value leaves it to the OS. Conditions combine.
+type SyncPolicy struct {
+	EveryWrites int           // after this many entries
+	Interval    time.Duration // once this long has passed since the last fsync, checked on writes and flushes
+	OnError     bool          // right after entries at E or more severe
+}
+
+func (s *fileSink) Write(entry Entry) error {
+	return s.WriteBatch([]Entry{entry})
+}
+
+// WriteBatch writes the entries with one syscall, or one per day they span.
+func (s *fileSink) WriteBatch(entries []Entry) error {
+	var encoder Encoder = TextEncoder{TimeFormat: s.l.TimeFormat, UTC: s.l.TimeUTC, Multiline: s.l.Multiline}
+	if s.l.LogfileEncoder != nil {
+		encoder = s.l.LogfileEncoder
+	}
+
+	if now := time.Now(); now.Sub(s.checked) >= fileCheckInterval {
+		s.checkHealth(now)
+	}
+	if s.lowDisk {
+		entries = s.lowDiskEntries(entries)
+	}
+	if s.part == nil && splitsLogfile(s.l.LogfileName) {
+		return s.writeSplit(entries)
+	}
+
+	s.encoded = s.encoded[:0]
+	for _, entry := range entries {
+		_, _, date := entry.Time.Date()
+		if s.file == nil || date != s.date {
+			err := s.write(s.encoded)
+			if err != nil {
+				return err
+			}
+			s.encoded = s.encoded[:0]
+
+			err = s.newFile(entry.Time)
+			if err != nil {
+				return err
+			}
+			s.date = date
+		}
+		s.encoded = encoder.Encode(s.encoded, entry)
+	}
+	err := s.write(s.encoded)
+	if err != nil {
+		return err
+	}
+	return s.syncByPolicy(entries)
+}
+
+// syncByPolicy fsyncs if LogfileSync asks for it after writing entries.
+func (s *fileSink) syncByPolicy(entries []Entry) error {
+	policy := s.l.LogfileSync
+	s.unsynced += len(entries)
+
+	sync := policy.EveryWrites > 0 && s.unsynced >= policy.EveryWrites ||
+		policy.Interval > 0 && time.Since(s.lastSync) >= policy.Interval
+	if policy.OnError && !sync {
+		for _, entry := range entries {
+			if levelPriority(entry.Level) <= LOG_ERR {
+				sync = true
+				break
+			}
+		}
+	}
+	if !sync {
+		return nil
+	}
+	return s.Sync()
+}
+
+func (s *fileSink) write(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	if s.l.LogfileLock {
+		return s.writeLocked(b)
+	}
+	if s.enc != nil {
+		b = s.enc.seal(b)
+	}
+	if s.sum != nil {
+		s.sum.Write(b)
+	}
+
+	var err error
+	if s.buf != nil {
+		_, err = s.buf.Write(b)
+	} else {
+		_, err = s.file.Write(b)
+	}
+	if err != nil {
+		return fmt.Errorf("writing to logfile: %w", err)
+	}
+	return nil
+}
+
+// writeLocked writes b with a single call while holding an exclusive lock on
+// the file, so the lines of processes sharing it never interleave. Another
+// process or logrotate may have moved the file away meanwhile, then it is
+// opened again by name first.
+func (s *fileSink) writeLocked(b []byte) error {
+	err := s.reopenIfMoved()
+	if err != nil {
+		return err
+	}
+
+	err = lockFile(s.file)
+	if err != nil {
+		return fmt.Errorf("locking logfile: %w", err)
+	}
+	defer unlockFile(s.file)
+
+	_, err = s.file.Write(b)
+	if err != nil {
+		return fmt.Errorf("writing to logfile: %w", err)
+	}
+	return nil
+}
+
+func (s *fileSink) reopenIfMoved() error {
+	current, err := s.file.Stat()
+	if err != nil {
+		return fmt.Errorf("checking logfile: %w", err)
+	}
+	named, err := os.Stat(s.name)
+	if err == nil && os.SameFile(current, named) {
+		return nil
+	}
+
+	s.file.Close()
+	s.file, err = os.OpenFile(s.name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, orDefaultMode(s.l.LogfileMode, 0600))
+	if err != nil {
+		return fmt.Errorf("reopening logfile: %w", err)
+	}
+	return nil
+}
+
+func (s *fileSink) newFile(now time.Time) error {
+	previous := s.name
+	err := s.Close()
+	if err != nil {
+		return fmt.Errorf("closing logfile: %w", err)
+	}
+
+	l := s.l
+	if l.LogfileEncryption != nil && l.LogfileLock {
+		return errors.New("LogfileEncryption can't be combined with LogfileLock")
+	}
+	if l.LogfileChecksums && l.LogfileLock {
+		return errors.New("LogfileChecksums can't be combined with LogfileLock")
+	}
+	if l.LogfileDir != "" {
+		err := os.MkdirAll(l.LogfileDir, orDefaultMode(l.LogfileDirMode, 0750))
+		if err != nil {
+			return fmt.Errorf("creating logfile directory: %w", err)
+		}
+	}
+
+	fileName := filepath.Join(l.LogfileDir, logfileName(s.part.expand(l.LogfileName), now))
+	if dir := filepath.Dir(fileName); s.part != nil && dir != filepath.Clean(l.LogfileDir) {
+		// a split logfile's template may put parts in directories, e.g. "{logger}/{level}.log"
+		err := os.MkdirAll(dir, orDefaultMode(l.LogfileDirMode, 0750))
+		if err != nil {
+			return fmt.Errorf("creating logfile directory: %w", err)
+		}
+	}
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, orDefaultMode(l.LogfileMode, 0600))
+	if err != nil {
+		return fmt.Errorf("creating logfile: %w", err)
+	}
+	if l.LogfileEncryption != nil {
+		// every opening starts a segment with a key of its own
+		s.enc, err = newFileCipher(l.LogfileEncryption)
+		if err == nil {
+			_, err = file.Write(s.enc.header)
+		}
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("encrypting logfile: %w", err)
+		}
+	}
+	if l.LogfileChecksums {
+		// appending to a file from an earlier run
+		s.sum, err = newLogfileDigest(fileName)
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("checksumming logfile: %w", err)
+		}
+	}
+	s.file, s.name = file, fileName
+	if l.LogfileArchive != nil {
+		if previous == "" {
+			s.archiveLeftovers(fileName, now)
+		} else if previous != fileName {
+			s.archiveLogfile(previous)
+		}
+	}
+	if l.LogfileBufferSize > 0 && !l.LogfileLock {
+		s.buf = bufio.NewWriterSize(file, l.LogfileBufferSize)
+	}
+
+	if l.LogfileLink != "" {
+		link := filepath.Join(l.LogfileDir, s.part.expand(l.LogfileLink))
+		// the link is a convenience, failing to update it shouldn't fail the entries
+		if link != fileName && updateLink(fileName, link) != nil {
+			l.counters.sinkErrors.Add(1)
+		}
+	}
+	return nil
+}
+
+// updateLink atomically points link at target, with a symlink or, where those
+// aren't allowed like on Windows without the privilege, a hard link.
+func updateLink(target, link string) error {
+	tmp := link + ".tmp"
+	_ = os.Remove(tmp)
+
+	err := os.Symlink(filepath.Base(target), tmp)
+	if err != nil {
+		err = os.Link(target, tmp)
+		if err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, link)
+}
+
+// logfileName expands the {program}, {date}, {pid} and {hostname} placeholders of template.
+func logfileName(template string, now time.Time) string {
+	if template == "" {
+		template = defaultLogfileName
+	}
+	hostname, _ := os.Hostname()
+
+	return strings.NewReplacer(
+		"{program}", filepath.Base(os.Args[0]),
+		"{date}", now.Format("2006-01-02"),
+		"{pid}", strconv.Itoa(os.Getpid()),
+		"{hostname}", hostname,
+	).Replace(template)
+}
+
+func orDefaultMode(mode, def os.FileMode) os.FileMode {
+	if mode == 0 {
+		return def
+	}
+	return mode
+}
+
+// Reopen closes the current file, the next write opens it again by name.
+func (s *fileSink) Reopen() error {
+	return s.Close()
+}
+
+func (s *fileSink) Flush() error {
+	if len(s.parts) > 0 {
+		return s.eachPart((*fileSink).Flush)
+	}
+	if interval := s.l.LogfileSync.Interval; interval > 0 && s.unsynced > 0 && time.Since(s.lastSync) >= interval {
+		return s.Sync()
+	}
+	if s.buf != nil {
+		err := s.buf.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	return s.writeManifest()
+}
+
+func (s *fileSink) writeManifest() error {
+	if s.sum == nil {
+		return nil
+	}
+	return s.sum.writeManifest(s.name)
+}
+
+func (s *fileSink) Sync() error {
+	if len(s.parts) > 0 {
+		return s.eachPart((*fileSink).Sync)
+	}
+	if s.file == nil {
+		return nil
+	}
+	if s.buf != nil {
+		err := s.buf.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	s.unsynced, s.lastSync = 0, time.Now()
+	err := s.file.Sync()
+	if err != nil {
+		return err
+	}
+	return s.writeManifest()
+}
+
+func (s *fileSink) Close() error {
+	var partsErr error
+	if len(s.parts) > 0 {
+		partsErr = s.eachPart((*fileSink).Close)
+		clear(s.parts)
+	}
+	if s.file == nil {
+		return partsErr
+	}
+	flushErr := s.Flush()
+	err := s.file.Close()
+	s.file, s.buf, s.enc, s.sum = nil, nil, nil, nil
+	if flushErr != nil {
+		return flushErr
+	}
+	return err
+}
+
+// Reopen makes every sink holding a file reopen it, to be called after logrotate
+// moved the file. It returns once the daemon has reopened them.
+func (l *Log) Reopen() error {
+	l = l.core()
+
+	var errs []error
+	done := make(chan struct{})
+	l.send(logMsg{ctrl: func() {
+		defer close(done)
+		_ = l.forEachSink(func(s Sink) error {
+			if r, ok := s.(Reopener); ok {
+				err := r.Reopen()
+				if err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return nil
+		})
+	}})
+
+	select {
+	case <-done:
+	case <-time.After(flushTimeout):
+		return fmt.Errorf("timed out waiting for the log daemon to reopen files")
+	}
+	return errors.Join(errs...)
+}
+
+// ReopenOnSignal calls Reopen whenever one of sigs (SIGHUP if none given) is received,
+// matching logrotate's postrotate "kill -HUP". Call the returned function to stop.
+func (l *Log) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	quit := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-c:
+				err := l.Reopen()
+				if err != nil {
+					l.ERR(err, "Error reopening logfile")
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(quit)
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package gologger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter decides whether an entry is logged. It runs on the logging
+// goroutine for SetFilter, so it must be safe for concurrent use.
+type Filter func(entry Entry) bool
+
+// SetFilter drops the entries f rejects, after the level checks and before
+// they are queued for the sinks. Levels registered as Always pass regardless.
+// nil removes the filter.
+func (l *Log) SetFilter(f Filter) {
+	l = l.core()
+	if f == nil {
+		l.filter.Store(nil)
+		return
+	}
+	l.filter.Store(&f)
+}
+
+// WithFilter returns a sink writing only the entries f accepts to s.
+func WithFilter(s Sink, f Filter) Sink {
+	return &filterSink{Sink: s, filter: f}
+}
+
+type filterSink struct {
+	Sink
+	filter   Filter
+	selected []Entry
+}
+
+func (s *filterSink) Write(entry Entry) error {
+	if !s.filter(entry) {
+		return nil
+	}
+	return s.Sink.Write(entry)
+}
+
+func (s *filterSink) WriteBatch(entries []Entry) error {
+	s.selected = s.selected[:0]
+	for _, entry := range entries {
+		if s.filter(entry) {
+			s.selected = append(s.selected, entry)
+		}
+	}
+
+	if bw, ok := s.Sink.(BatchWriter); ok {
+		return bw.WriteBatch(s.selected)
+	}
+	var errs []error
+	for _, entry := range s.selected {
+		err := s.Sink.Write(entry)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *filterSink) Flush() error {
+	if flusher, ok := s.Sink.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+func (s *filterSink) startFlush() error {
+	return startFlush(s.Sink)
+}
+
+func (s *filterSink) Sync() error {
+	if syncer, ok := s.Sink.(Syncer); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+func (s *filterSink) Reopen() error {
+	if reopener, ok := s.Sink.(Reopener); ok {
+		return reopener.Reopen()
+	}
+	return nil
+}
+
+// ParseFilter compiles a filter expression like
+//
+//	level>=warn || logger=="db" || msg~="timeout"
+//
+// Comparisons are joined with &&, || and !, grouped with parentheses. On the
+// left is level, logger, msg, msg_id, func, file, line or the key of a field; on the
+// right a quoted string, a number or a bare word. The operators are ==, !=,
+// <, <=, >, >=, ~= (matches the regular expression) and !~. Levels compare
+// by severity, level>=warn taking warnings and worse. Numbers compare as
+// numbers, everything else as text; a missing field only satisfies !=.
+func ParseFilter(expr string) (Filter, error) {
+	p := &filterParser{}
+	err := p.tokenize(expr)
+	if err != nil {
+		return nil, fmt.Errorf("filter %q: %w", expr, err)
+	}
+	f, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("filter %q: %w", expr, err)
+	}
+	return f, nil
+}
+
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+// tokenize splits expr into operators, parentheses, quoted strings and words.
+func (p *filterParser) tokenize(expr string) error {
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			p.tokens = append(p.tokens, expr[i:i+1])
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(expr) && expr[j] != c {
+				if expr[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(expr) {
+				return errors.New("unterminated string")
+			}
+			p.tokens = append(p.tokens, expr[i:j+1])
+			i = j + 1
+		case strings.ContainsRune("=!<>~&|", rune(c)):
+			j := i + 1
+			if j < len(expr) && strings.ContainsRune("=~&|", rune(expr[j])) {
+				j++
+			}
+			p.tokens = append(p.tokens, expr[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(expr) && !strings.ContainsRune(" \t\n()\"'=!<>~&|", rune(expr[j])) {
+				j++
+			}
+			p.tokens = append(p.tokens, expr[i:j])
+			i = j
+		}
+	}
+	return nil
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *filterParser) or() (Filter, error) {
+	left, err := p.and()
+	for err == nil && p.peek() == "||" {
+		p.next()
+		var right Filter
+		right, err = p.and()
+		l := left
+		left = func(e Entry) bool { return l(e) || right(e) }
+	}
+	return left, err
+}
+
+func (p *filterParser) and() (Filter, error) {
+	left, err := p.unary()
+	for err == nil && p.peek() == "&&" {
+		p.next()
+		var right Filter
+		right, err = p.unary()
+		l := left
+		left = func(e Entry) bool { return l(e) && right(e) }
+	}
+	return left, err
+}
+
+func (p *filterParser) unary() (Filter, error) {
+	switch p.peek() {
+	case "!":
+		p.next()
+		f, err := p.unary()
+		return func(e Entry) bool { return !f(e) }, err
+	case "(":
+		p.next()
+		f, err := p.or()
+		if err == nil && p.next() != ")" {
+			err = errors.New("missing )")
+		}
+		return f, err
+	}
+	return p.comparison()
+}
+
+func (p *filterParser) comparison() (Filter, error) {
+	name := p.next()
+	if name == "" || !isFilterWord(name) {
+		return nil, fmt.Errorf("expected a name, got %q", name)
+	}
+	op := p.next()
+	value := p.next()
+	if value == "" {
+		return nil, fmt.Errorf("%s %s: missing value", name, op)
+	}
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+		quoted := value
+		if value[0] == '\'' {
+			quoted = `"` + strings.ReplaceAll(value[1:len(value)-1], `"`, `\"`) + `"`
+		}
+		unquoted, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", value)
+		}
+		value = unquoted
+	} else if !isFilterWord(value) {
+		return nil, fmt.Errorf("%s %s: expected a value, got %q", name, op, value)
+	}
+
+	if op == "~=" || op == "!~" {
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, err
+		}
+		get := filterGetter(name)
+		return func(e Entry) bool {
+			v, ok := get(e)
+			return ok && re.MatchString(v) == (op == "~=")
+		}, nil
+	}
+
+	// compare orders the entry's side against value, false if it has none
+	var compare func(e Entry) (int, bool)
+	get := filterGetter(name)
+	if name == "level" {
+		want, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		// more severe is greater, the reverse of priorities
+		compare = func(e Entry) (int, bool) { return int(want) - int(levelPriority(e.Level)), true }
+	} else if n, err := strconv.ParseFloat(value, 64); err == nil {
+		compare = func(e Entry) (int, bool) {
+			v, ok := get(e)
+			m, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return strings.Compare(v, value), ok
+			}
+			return compareFloat(m, n), ok
+		}
+	} else {
+		compare = func(e Entry) (int, bool) {
+			v, ok := get(e)
+			return strings.Compare(v, value), ok
+		}
+	}
+
+	var test func(c int) bool
+	switch op {
+	case "==":
+		test = func(c int) bool { return c == 0 }
+	case "!=":
+		test = func(c int) bool { return c != 0 }
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	case ">=":
+		test = func(c int) bool { return c >= 0 }
+	default:
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+
+	return func(e Entry) bool {
+		c, ok := compare(e)
+		if !ok {
+			return op == "!="
+		}
+		return test(c)
+	}, nil
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isFilterWord(s string) bool {
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("_.-:/+", r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// filterGetter returns what name refers to in an entry, false for a missing field.
+func filterGetter(name string) func(e Entry) (string, bool) {
+	switch name {
+	case "level":
+		return func(e Entry) (string, bool) { return levelName(e.Level), true }
+	case "logger":
+		return func(e Entry) (string, bool) { return e.Logger, true }
+	case "msg", "message":
+		return func(e Entry) (string, bool) { return e.Message, true }
+	case "msg_id":
+		return func(e Entry) (string, bool) { return e.MessageID, true }
+	case "func":
+		return func(e Entry) (string, bool) { return e.Func, true }
+	case "file":
+		return func(e Entry) (string, bool) { return e.File, true }
+	case "line":
+		return func(e Entry) (string, bool) { return strconv.Itoa(e.Line), true }
+	}
+	return func(e Entry) (string, bool) {
+		for _, f := range e.Fields {
+			if f.Key == name {
+				return fmt.Sprint(f.Value), true
+			}
+		}
+		return "", false
+	}
+}
@@ -0,0 +1,94 @@
+package gologger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	warn := Entry{Level: 'W', Logger: "db", Message: "disk almost full"}
+	info := Entry{Level: 'I', Message: "request timeout after 3s", Fields: []Field{{"status", 503}, {"user", `bob "the" builder`}}}
+	debug := Entry{Level: 'D', Message: "query", Fields: []Field{{"status", 200}, {"path", "/api/v1"}}}
+	entries := []Entry{warn, info, debug}
+
+	tests := []struct {
+		expr string
+		want []bool // for warn, info and debug
+	}{
+		// levels compare by severity
+		{`level>=warn`, []bool{true, false, false}},
+		{`level<info`, []bool{false, false, true}},
+		{`level==INFO`, []bool{false, true, false}},
+		{`level!=debug`, []bool{true, true, false}},
+
+		// ! binds tighter than &&, && tighter than ||
+		{`level==warn || level==info && status==200`, []bool{true, false, false}},
+		{`(level==warn || level==info) && status==503`, []bool{false, true, false}},
+		{`!level==warn && !level==debug`, []bool{false, true, false}},
+		{`!(level==warn || level==debug)`, []bool{false, true, false}},
+
+		// numbers compare as numbers, the rest as text
+		{`status>=500`, []bool{false, true, false}},
+		{`status<1000`, []bool{false, true, true}},
+		{`logger=="db"`, []bool{true, false, false}},
+		{`path==/api/v1`, []bool{false, false, true}},
+
+		// quoting and escapes
+		{`user=="bob \"the\" builder"`, []bool{false, true, false}},
+		{`user=='bob "the" builder'`, []bool{false, true, false}},
+		{`msg=="disk almost full"`, []bool{true, false, false}},
+
+		// regular expressions
+		{`msg~="time(out)?"`, []bool{false, true, false}},
+		{`msg!~"^query$"`, []bool{true, true, false}},
+		{`user~="bob"`, []bool{false, true, false}},
+
+		// a missing field only satisfies !=
+		{`user=="alice"`, []bool{false, false, false}},
+		{`user!="alice"`, []bool{true, true, true}},
+		{`status<0`, []bool{false, false, false}},
+		{`status>0`, []bool{false, true, true}},
+		{`user~="."`, []bool{false, true, false}},
+		{`user!~"bob"`, []bool{false, false, false}},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Errorf("ParseFilter(%s): %v", tt.expr, err)
+			continue
+		}
+		for i, e := range entries {
+			if got := f(e); got != tt.want[i] {
+				t.Errorf("ParseFilter(%s) on %q = %v, want %v", tt.expr, e.Message, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr, want string
+	}{
+		{``, `expected a name, got ""`},
+		{`level>=`, `level >=: missing value`},
+		{`level>=loud`, `unknown log level "loud"`},
+		{`msg=="open`, `unterminated string`},
+		{`msg~="("`, `missing closing )`},
+		{`(level==warn`, `missing )`},
+		{`level==warn)`, `unexpected ")"`},
+		{`a == b c`, `unexpected "c"`},
+		{`a =~ b`, `unknown operator "=~"`},
+		{`a == (b)`, `a ==: expected a value, got "("`},
+		{`level==warn &&`, `expected a name, got ""`},
+	}
+	for _, tt := range tests {
+		_, err := ParseFilter(tt.expr)
+		if err == nil {
+			t.Errorf("ParseFilter(%s) succeeded, want an error", tt.expr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseFilter(%s) = %q, want it to contain %q", tt.expr, err, tt.want)
+		}
+	}
+}
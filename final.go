@@ -0,0 +1,91 @@
+package gologger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Final logs the record a batch job or cron task ends with: its exit code,
+// how long the process ran and how many entries it logged at each level as
+// logged_<level> fields, followed by summary. It is logged whatever the
+// levels and filter say, at I for exit code 0 and E otherwise, and written
+// and synced to disk when Final returns. Run calls it.
+func (l *Log) Final(exitCode int, summary Fields) {
+	pc, line := l.caller(1)
+	l.final(pc, line, exitCode, summary)
+}
+
+// Run runs main, then logs Final with the exit code main returns, shuts the
+// logger down and exits with it, for the main function of a job:
+//
+//	func main() { gologger.L.Run(run) }
+//
+// A panic in main is logged with its stack and exits with status 2, like an
+// unrecovered one.
+func (l *Log) Run(main func() int) {
+	pc, line := l.caller(1)
+	code := l.runMain(main)
+	l.final(pc, line, code, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	err := l.Shutdown(ctx)
+	cancel()
+	if err != nil {
+		// straight to stderr, the logger is stopped
+		l.notice('E', "shutdown", Field{"error", err.Error()})
+	}
+	os.Exit(code)
+}
+
+func (l *Log) runMain(main func() int) (code int) {
+	defer func() {
+		if r := recover(); r != nil {
+			l.logPanic(r)
+			code = 2
+		}
+	}()
+	return main()
+}
+
+func (l *Log) final(pc uintptr, line int, exitCode int, summary Fields) {
+	core := l.core()
+	var elapsed time.Duration
+	if !core.startTime.IsZero() {
+		elapsed = time.Since(core.startTime).Round(time.Millisecond)
+	}
+
+	fields := []Field{{"exit_code", exitCode}, {"duration", elapsed}}
+	counted := func(level byte) {
+		if n := core.counters.logged[level].Load(); n > 0 {
+			fields = append(fields, Field{"logged_" + levelName(level), n})
+		}
+	}
+	for _, level := range []byte(builtinLevelTags) {
+		counted(level)
+	}
+	for level := 0; level < len(core.counters.logged); level++ {
+		if strings.IndexByte(builtinLevelTags, byte(level)) < 0 {
+			counted(byte(level))
+		}
+	}
+	// with no placeholders these are just the fields, by key
+	_, rest := renderTemplate("", summary)
+	fields = append(fields, rest...)
+
+	level := byte('I')
+	if exitCode != 0 {
+		level = 'E'
+	}
+	l.outputEntry(pc, Entry{
+		Level:    level,
+		Line:     line,
+		Message:  fmt.Sprintf("exiting with code %d after %s", exitCode, elapsed),
+		Template: "exiting with code {exit_code} after {duration}",
+		Fields:   fields,
+		always:   true,
+	})
+	l.flush(true)
+}
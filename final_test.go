@@ -0,0 +1,59 @@
+package gologger
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestFinal(t *testing.T) {
+	l, sink := newTestLog(t)
+	l.WRN("slow")
+	l.ERR(nil, "failed")
+	l.ERR(nil, "failed again")
+	l.Final(1, Fields{"rows": 5})
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	entry := sink.entries[len(sink.entries)-1]
+	if entry.Level != 'E' || !strings.HasPrefix(entry.Message, "exiting with code 1 after ") {
+		t.Errorf("final entry %c %q", entry.Level, entry.Message)
+	}
+	fields := make(map[string]interface{})
+	for _, f := range entry.Fields {
+		fields[f.Key] = f.Value
+	}
+	for key, want := range map[string]interface{}{"exit_code": 1, "logged_warning": uint64(1), "logged_error": uint64(2), "rows": 5} {
+		if fields[key] != want {
+			t.Errorf("%s = %v, want %v", key, fields[key], want)
+		}
+	}
+	if _, ok := fields["duration"]; !ok {
+		t.Error("no duration")
+	}
+	if _, ok := fields["logged_info"]; ok {
+		t.Error("logged_info without info entries")
+	}
+}
+
+func TestRunPanic(t *testing.T) {
+	if os.Getenv("GOLOGGER_TEST_RUN") == "panic" {
+		L.Run(func() int { panic("boom") })
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRunPanic$")
+	cmd.Env = append(os.Environ(), "GOLOGGER_TEST_RUN=panic")
+	out, err := cmd.CombinedOutput()
+	var exit *exec.ExitError
+	if !errors.As(err, &exit) || exit.ExitCode() != 2 {
+		t.Fatalf("Run exited with %v, want status 2:\n%s", err, out)
+	}
+	for _, want := range []string{"Recovered panic: boom", "exiting with code 2"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package gologger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FluentdOptions configures NewFluentdSink, the zero value works.
+type FluentdOptions struct {
+	// Tag template with {program}, {hostname}, {logger} and {level} placeholders,
+	// e.g. "app.{logger}". "{program}" if empty.
+	Tag string
+
+	// Wait for the aggregator to acknowledge every chunk, resending it otherwise.
+	RequireAck bool
+	AckTimeout time.Duration // 10s if zero
+
+	// Compression of the chunks, which the forward protocol only has "gzip"
+	// for, at any level.
+	Compression Compression
+
+	Batch BatchOptions
+}
+
+type fluentdSink struct {
+	*batcher
+
+	network string
+	addr    string
+	opts    FluentdOptions
+	tag     *strings.Replacer
+	zip     *compressor // nil without Compression
+
+	conn       net.Conn
+	r          *bufio.Reader
+	buf        []byte
+	events     []byte // of a compressed chunk
+	compressed bytes.Buffer
+}
+
+// NewFluentdSink returns a sink speaking Fluentd's forward protocol to the
+// fluentd or fluent-bit at addr, network being "tcp" or "unix".
+func NewFluentdSink(network, addr string, opts *FluentdOptions) (Sink, error) {
+	s := &fluentdSink{network: network, addr: addr}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Tag == "" {
+		s.opts.Tag = "{program}"
+	}
+	if s.opts.AckTimeout <= 0 {
+		s.opts.AckTimeout = 10 * time.Second
+	}
+	hostname, _ := os.Hostname()
+	s.tag = strings.NewReplacer("{program}", filepath.Base(os.Args[0]), "{hostname}", hostname)
+	if codec := s.opts.Compression.Codec; codec != "" && !strings.EqualFold(codec, "gzip") {
+		return nil, fmt.Errorf("fluentd can't take %s compression, only gzip", codec)
+	}
+	var err error
+	s.zip, err = newCompressor(s.opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.connect()
+	if err != nil {
+		return nil, err
+	}
+	s.batcher = newBatcher(s.opts.Batch, s.push)
+	return s, nil
+}
+
+func (s *fluentdSink) connect() error {
+	conn, err := net.DialTimeout(s.network, s.addr, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("connecting to fluentd: %w", err)
+	}
+	s.conn = conn
+	s.r = bufio.NewReader(conn)
+	return nil
+}
+
+func (s *fluentdSink) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func (s *fluentdSink) tagFor(entry Entry) string {
+	tag := s.tag.Replace(s.opts.Tag)
+	if strings.Contains(tag, "{") {
+		tag = strings.NewReplacer("{logger}", entry.Logger, "{level}", levelName(entry.Level)).Replace(tag)
+	}
+	return strings.ReplaceAll(strings.Trim(tag, "."), "..", ".")
+}
+
+// push sends one forward mode message per tag, reconnecting on the way.
+func (s *fluentdSink) push(entries []Entry) error {
+	byTag := map[string][]Entry{}
+	var tags []string
+	for _, entry := range entries {
+		tag := s.tagFor(entry)
+		if _, ok := byTag[tag]; !ok {
+			tags = append(tags, tag)
+		}
+		byTag[tag] = append(byTag[tag], entry)
+	}
+
+	for _, tag := range tags {
+		err := s.forward(tag, byTag[tag])
+		if err != nil {
+			// the whole batch is resent, tags sent before may arrive twice
+			s.disconnect()
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fluentdSink) forward(tag string, entries []Entry) error {
+	if s.conn == nil {
+		err := s.connect()
+		if err != nil {
+			return err
+		}
+	}
+
+	b := appendMsgpackArrayHeader(s.buf[:0], 3)
+	b = appendMsgpackString(b, tag)
+	options := 0
+	if s.zip == nil {
+		b = appendMsgpackArrayHeader(b, len(entries))
+		for _, entry := range entries {
+			b = appendFluentdEvent(b, entry)
+		}
+	} else {
+		// CompressedPackedForward mode, the events back to back, compressed
+		var err error
+		b, err = s.appendCompressed(b, entries)
+		if err != nil {
+			return permanentError{err}
+		}
+		options += 2
+	}
+
+	var chunk string
+	if s.opts.RequireAck {
+		var id [16]byte
+		_, _ = rand.Read(id[:])
+		chunk = base64.StdEncoding.EncodeToString(id[:])
+		options++
+	}
+	b = appendMsgpackMapHeader(b, options)
+	if chunk != "" {
+		b = appendMsgpackString(b, "chunk")
+		b = appendMsgpackString(b, chunk)
+	}
+	if s.zip != nil {
+		b = appendMsgpackString(b, "compressed")
+		b = appendMsgpackString(b, "gzip")
+		b = appendMsgpackString(b, "size")
+		b = appendMsgpackInt(b, int64(len(entries)))
+	}
+	s.buf = b
+
+	_, err := s.conn.Write(b)
+	if err != nil || !s.opts.RequireAck {
+		return err
+	}
+
+	_ = s.conn.SetReadDeadline(time.Now().Add(s.opts.AckTimeout))
+	ack, err := readFluentdAck(s.r)
+	if err != nil {
+		return fmt.Errorf("waiting for fluentd ack: %w", err)
+	}
+	if ack != chunk {
+		return fmt.Errorf("fluentd acked chunk %q instead of %q", ack, chunk)
+	}
+	return nil
+}
+
+// appendCompressed appends entries as the compressed binary of a
+// CompressedPackedForward message.
+func (s *fluentdSink) appendCompressed(b []byte, entries []Entry) ([]byte, error) {
+	s.events = s.events[:0]
+	for _, entry := range entries {
+		s.events = appendFluentdEvent(s.events, entry)
+	}
+	s.compressed.Reset()
+	zw, err := s.zip.writer(&s.compressed)
+	if err != nil {
+		return b, err
+	}
+	zw.Write(s.events)
+	err = zw.Close()
+	if err != nil {
+		return b, err
+	}
+	return appendMsgpackBinary(b, s.compressed.Bytes()), nil
+}
+
+// appendFluentdEvent appends entry as an [EventTime, record] pair.
+func appendFluentdEvent(b []byte, entry Entry) []byte {
+	b = appendMsgpackArrayHeader(b, 2)
+	b = appendMsgpackEventTime(b, entry.Time)
+	return appendFluentdRecord(b, entry)
+}
+
+func appendFluentdRecord(b []byte, entry Entry) []byte {
+	n := 4 + len(entry.Fields)
+	if entry.Logger != "" {
+		n++
+	}
+	if entry.Stack != "" {
+		n++
+	}
+
+	b = appendMsgpackMapHeader(b, n)
+	b = appendMsgpackString(b, "level")
+	b = appendMsgpackString(b, levelName(entry.Level))
+	if entry.Logger != "" {
+		b = appendMsgpackString(b, "logger")
+		b = appendMsgpackString(b, entry.Logger)
+	}
+	b = appendMsgpackString(b, "func")
+	b = appendMsgpackString(b, entry.Func)
+	b = appendMsgpackString(b, "line")
+	b = appendMsgpackInt(b, int64(entry.Line))
+	b = appendMsgpackString(b, "message")
+	b = appendMsgpackString(b, entry.Message)
+	for _, f := range entry.Fields {
+		b = appendMsgpackString(b, f.Key)
+		b = appendMsgpackValue(b, f.Value)
+	}
+	if entry.Stack != "" {
+		b = appendMsgpackString(b, "stacktrace")
+		b = appendMsgpackString(b, entry.Stack)
+	}
+	return b
+}
+
+// readFluentdAck reads the {"ack": chunk} response.
+func readFluentdAck(r *bufio.Reader) (string, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if header&0xf0 != 0x80 {
+		return "", fmt.Errorf("unexpected response type 0x%02x", header)
+	}
+
+	var ack string
+	for i := 0; i < int(header&0x0f); i++ {
+		key, err := readMsgpackString(r)
+		if err != nil {
+			return "", err
+		}
+		value, err := readMsgpackString(r)
+		if err != nil {
+			return "", err
+		}
+		if key == "ack" {
+			ack = value
+		}
+	}
+	return ack, nil
+}
+
+func readMsgpackString(r *bufio.Reader) (string, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	var n int
+	switch {
+	case header&0xe0 == 0xa0:
+		n = int(header & 0x1f)
+	case header == 0xd9 || header == 0xc4:
+		l, err := r.ReadByte()
+		n = int(l)
+		if err != nil {
+			return "", err
+		}
+	case header == 0xda || header == 0xc5:
+		var l [2]byte
+		_, err = io.ReadFull(r, l[:])
+		n = int(binary.BigEndian.Uint16(l[:]))
+	case header == 0xdb || header == 0xc6:
+		var l [4]byte
+		_, err = io.ReadFull(r, l[:])
+		n = int(binary.BigEndian.Uint32(l[:]))
+	default:
+		return "", errors.New("expected a string")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return string(b), err
+}
+
+// Close flushes what is pending and closes the connection.
+func (s *fluentdSink) Close() error {
+	err := s.batcher.Close()
+	s.disconnect()
+	return err
+}
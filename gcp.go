@@ -0,0 +1,504 @@
+package gologger
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gcpSeverity maps levels onto the LogSeverity of Cloud Logging, which
+// follows the syslog severities: 'A' (audit) is NOTICE.
+func gcpSeverity(level byte) string {
+	switch levelSeverity(level) {
+	case LOG_EMERG:
+		return "EMERGENCY"
+	case LOG_ALERT:
+		return "ALERT"
+	case LOG_CRIT:
+		return "CRITICAL"
+	case LOG_ERR:
+		return "ERROR"
+	case LOG_WARNING:
+		return "WARNING"
+	case LOG_NOTICE:
+		return "NOTICE"
+	case LOG_INFO:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
+
+// GCPEncoder writes the structured JSON the logging agents of GKE, Cloud Run
+// and GCE turn into properly leveled Cloud Logging entries, for stdout:
+// {"severity":"ERROR","message":"...","time":"...","logging.googleapis.com/sourceLocation":{...},"key":"value"}
+type GCPEncoder struct {
+	// Project of the trace_id and span_id fields of gologgerotel, which
+	// become trace links when set.
+	ProjectID string
+}
+
+func (e GCPEncoder) Encode(buf []byte, entry Entry) []byte {
+	buf = append(buf, `{"severity":"`...)
+	buf = append(buf, gcpSeverity(entry.Level)...)
+	buf = append(buf, `","time":"`...)
+	buf = entry.Time.UTC().AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, '"')
+	if entry.Func != "" || entry.File != "" {
+		buf = append(buf, `,"logging.googleapis.com/sourceLocation":`...)
+		buf = appendGCPSourceLocation(buf, entry)
+	}
+	if entry.Logger != "" {
+		buf = append(buf, `,"logging.googleapis.com/labels":{"logger":`...)
+		buf = appendJSONString(buf, entry.Logger)
+		buf = append(buf, '}')
+	}
+	buf = appendGCPTrace(buf, entry.Fields, e.ProjectID, "logging.googleapis.com/trace", "logging.googleapis.com/spanId")
+	buf = append(buf, ',')
+	buf = appendGCPPayload(buf, entry)
+	return append(buf, "}\n"...)
+}
+
+func appendGCPSourceLocation(buf []byte, entry Entry) []byte {
+	buf = append(buf, `{"function":`...)
+	buf = appendJSONString(buf, entry.Func)
+	if entry.File != "" {
+		buf = append(buf, `,"file":`...)
+		buf = appendJSONString(buf, entry.File)
+	}
+	buf = append(buf, `,"line":"`...)
+	buf = strconv.AppendInt(buf, int64(entry.Line), 10)
+	return append(buf, `"}`...)
+}
+
+func appendGCPTrace(buf []byte, fields []Field, project, traceKey, spanKey string) []byte {
+	if project == "" {
+		return buf
+	}
+	for _, f := range fields {
+		switch f.Key {
+		case "trace_id":
+			buf = append(buf, ',')
+			buf = appendJSONString(buf, traceKey)
+			buf = append(buf, ':')
+			buf = appendJSONString(buf, "projects/"+project+"/traces/"+fmt.Sprint(f.Value))
+		case "span_id":
+			buf = append(buf, ',')
+			buf = appendJSONString(buf, spanKey)
+			buf = append(buf, ':')
+			buf = appendJSONString(buf, fmt.Sprint(f.Value))
+		}
+	}
+	return buf
+}
+
+// appendGCPPayload writes the message, fields and stack as members of the
+// JSON payload, the stack as stack_trace for Error Reporting.
+func appendGCPPayload(buf []byte, entry Entry) []byte {
+	buf = append(buf, `"message":`...)
+	buf = appendJSONString(buf, entry.Message)
+	for _, f := range entry.Fields {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, f.Key)
+		buf = append(buf, ':')
+		buf = appendJSONValue(buf, f.Value)
+	}
+	if entry.Stack != "" {
+		buf = append(buf, `,"stack_trace":`...)
+		buf = appendJSONString(buf, entry.Message+"\n"+entry.Stack)
+	}
+	return buf
+}
+
+// GCPResource is the monitored resource entries are attributed to, e.g.
+// {"k8s_container", {"cluster_name": ..., "pod_name": ...}}.
+type GCPResource struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels"`
+}
+
+// GCPOptions configures NewGCPSink, the zero value works on GCP.
+type GCPOptions struct {
+	ProjectID string // from the credentials, GOOGLE_CLOUD_PROJECT or the metadata server if empty
+	LogName   string // "{program}" if empty, with the placeholders of LogfileName
+
+	// Detected if nil: k8s_container on GKE, cloud_run_revision on Cloud
+	// Run, gce_instance on GCE and global elsewhere.
+	Resource *GCPResource
+	Labels   map[string]string // added to every entry
+
+	// Service account key file, GOOGLE_APPLICATION_CREDENTIALS if empty. The
+	// metadata server's account is used without one.
+	CredentialsFile string
+	Endpoint        string // https://logging.googleapis.com if empty
+
+	Client *http.Client // one with a 30s timeout if nil
+	Batch  BatchOptions
+}
+
+type gcpSink struct {
+	*batcher
+
+	url     string
+	project string
+	logName string
+	opts    GCPOptions
+	token   *gcpTokenSource
+	buf     bytes.Buffer
+}
+
+// Entries of a request, below its limit of 10 MB.
+const gcpMaxRequestBytes = 9 << 20
+
+// NewGCPSink returns a sink writing batches of entries to Google Cloud
+// Logging through its API, leveled and with the resource they came from.
+func NewGCPSink(opts *GCPOptions) (Sink, error) {
+	s := &gcpSink{}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Client == nil {
+		s.opts.Client = defaultHTTPClient
+	}
+	endpoint := s.opts.Endpoint
+	if endpoint == "" {
+		endpoint = "https://logging.googleapis.com"
+	}
+	s.url = strings.TrimSuffix(endpoint, "/") + "/v2/entries:write"
+	if s.opts.LogName == "" {
+		s.opts.LogName = "{program}"
+	}
+
+	credentials := s.opts.CredentialsFile
+	if credentials == "" {
+		credentials = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	s.token = &gcpTokenSource{client: s.opts.Client}
+	if credentials != "" {
+		err := s.token.loadServiceAccount(credentials)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.project = s.opts.ProjectID
+	if s.project == "" {
+		s.project = s.token.account.ProjectID
+	}
+	if s.project == "" {
+		s.project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	if s.project == "" {
+		s.project, _ = gcpMetadata(s.opts.Client, "project/project-id")
+	}
+	if s.project == "" {
+		return nil, fmt.Errorf("no GCP project, set ProjectID or GOOGLE_CLOUD_PROJECT")
+	}
+	s.logName = "projects/" + s.project + "/logs/" + url.PathEscape(logfileName(s.opts.LogName, time.Now()))
+	if s.opts.Resource == nil {
+		s.opts.Resource = detectGCPResource(s.opts.Client, s.project)
+	}
+
+	s.batcher = newBatcher(s.opts.Batch, s.push)
+	return s, nil
+}
+
+// push writes entries, split into requests below the size limit.
+func (s *gcpSink) push(entries []Entry) error {
+	var b []byte
+	for len(entries) > 0 {
+		s.buf.Reset()
+		b = append(b[:0], `{"logName":`...)
+		b = appendJSONString(b, s.logName)
+		b = append(b, `,"resource":`...)
+		resource, _ := json.Marshal(s.opts.Resource)
+		b = append(b, resource...)
+		if len(s.opts.Labels) > 0 {
+			labels, _ := json.Marshal(s.opts.Labels)
+			b = append(b, `,"labels":`...)
+			b = append(b, labels...)
+		}
+		b = append(b, `,"partialSuccess":true,"entries":[`...)
+		s.buf.Write(b)
+
+		n := 0
+		for n < len(entries) && (n == 0 || s.buf.Len() < gcpMaxRequestBytes) {
+			b = b[:0]
+			if n > 0 {
+				b = append(b, ',')
+			}
+			b = s.appendEntry(b, entries[n])
+			s.buf.Write(b)
+			n++
+		}
+		s.buf.WriteString("]}")
+		entries = entries[n:]
+
+		err := s.write(s.buf.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *gcpSink) appendEntry(buf []byte, entry Entry) []byte {
+	buf = append(buf, `{"severity":"`...)
+	buf = append(buf, gcpSeverity(entry.Level)...)
+	buf = append(buf, `","timestamp":"`...)
+	buf = entry.Time.UTC().AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, '"')
+	if entry.Func != "" || entry.File != "" {
+		buf = append(buf, `,"sourceLocation":`...)
+		buf = appendGCPSourceLocation(buf, entry)
+	}
+	if entry.Logger != "" {
+		buf = append(buf, `,"labels":{"logger":`...)
+		buf = appendJSONString(buf, entry.Logger)
+		buf = append(buf, '}')
+	}
+	buf = appendGCPTrace(buf, entry.Fields, s.project, "trace", "spanId")
+	buf = append(buf, `,"jsonPayload":{`...)
+	buf = appendGCPPayload(buf, entry)
+	return append(buf, "}}"...)
+}
+
+func (s *gcpSink) write(body []byte) error {
+	token, err := s.token.get()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		s.token.reset()
+	}
+	return checkHTTPResponse(resp)
+}
+
+// gcpServiceAccount is the part of a service account key file used here.
+type gcpServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+
+	key *rsa.PrivateKey
+}
+
+// gcpTokenSource gets OAuth access tokens for a service account key, or the
+// metadata server's account without one, cached until shortly before they expire.
+type gcpTokenSource struct {
+	client  *http.Client
+	account gcpServiceAccount
+	scope   string // of the tokens of a service account key, logging.write if empty
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (t *gcpTokenSource) loadServiceAccount(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading GCP credentials: %w", err)
+	}
+	err = json.Unmarshal(b, &t.account)
+	if err != nil {
+		return fmt.Errorf("reading GCP credentials %s: %w", path, err)
+	}
+	block, _ := pem.Decode([]byte(t.account.PrivateKey))
+	if block == nil {
+		return fmt.Errorf("reading GCP credentials %s: no private key", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("reading GCP credentials %s: %w", path, err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("reading GCP credentials %s: not an RSA key", path)
+	}
+	t.account.key = rsaKey
+	if t.account.TokenURI == "" {
+		t.account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return nil
+}
+
+func (t *gcpTokenSource) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.token = ""
+}
+
+func (t *gcpTokenSource) get() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && time.Until(t.expires) > time.Minute {
+		return t.token, nil
+	}
+
+	var resp *http.Response
+	var err error
+	if t.account.key != nil {
+		var assertion string
+		assertion, err = t.assertion()
+		if err != nil {
+			return "", err
+		}
+		resp, err = t.client.PostForm(t.account.TokenURI, url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		})
+	} else {
+		var req *http.Request
+		req, err = http.NewRequest(http.MethodGet, gcpMetadataURL+"instance/service-accounts/default/token", nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		resp, err = t.client.Do(req)
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting GCP access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("getting GCP access token: %s: %s", resp.Status, body)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", fmt.Errorf("getting GCP access token: %w", err)
+	}
+	t.token = token.AccessToken
+	t.expires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return t.token, nil
+}
+
+// assertion is the JWT exchanged for an access token, signed with the account's key.
+func (t *gcpTokenSource) assertion() (string, error) {
+	now := time.Now()
+	scope := t.scope
+	if scope == "" {
+		scope = "https://www.googleapis.com/auth/logging.write"
+	}
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   t.account.ClientEmail,
+		"scope": scope,
+		"aud":   t.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	sum := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.account.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("signing GCP token request: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+const gcpMetadataURL = "http://metadata.google.internal/computeMetadata/v1/"
+
+// gcpMetadata reads path from the metadata server, failing fast off GCP.
+func gcpMetadata(client *http.Client, path string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, gcpMetadataURL+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := (&http.Client{Timeout: time.Second, Transport: client.Transport}).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GCP metadata %s: %s", path, resp.Status)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// detectGCPResource finds out where the program runs from the environment
+// and the metadata server.
+func detectGCPResource(client *http.Client, project string) *GCPResource {
+	metadata := func(path string) string {
+		v, _ := gcpMetadata(client, path)
+		return v
+	}
+
+	switch {
+	case os.Getenv("K_SERVICE") != "":
+		return &GCPResource{Type: "cloud_run_revision", Labels: map[string]string{
+			"project_id":         project,
+			"service_name":       os.Getenv("K_SERVICE"),
+			"revision_name":      os.Getenv("K_REVISION"),
+			"configuration_name": os.Getenv("K_CONFIGURATION"),
+			"location":           filepath.Base(metadata("instance/region")),
+		}}
+	case os.Getenv("KUBERNETES_SERVICE_HOST") != "":
+		namespace := os.Getenv("POD_NAMESPACE")
+		if namespace == "" {
+			b, _ := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+			namespace = strings.TrimSpace(string(b))
+		}
+		pod := os.Getenv("POD_NAME")
+		if pod == "" {
+			pod, _ = os.Hostname()
+		}
+		return &GCPResource{Type: "k8s_container", Labels: map[string]string{
+			"project_id":     project,
+			"location":       metadata("instance/attributes/cluster-location"),
+			"cluster_name":   metadata("instance/attributes/cluster-name"),
+			"namespace_name": namespace,
+			"pod_name":       pod,
+			"container_name": os.Getenv("CONTAINER_NAME"),
+		}}
+	}
+	if id := metadata("instance/id"); id != "" {
+		return &GCPResource{Type: "gce_instance", Labels: map[string]string{
+			"project_id":  project,
+			"instance_id": id,
+			"zone":        filepath.Base(metadata("instance/zone")), // from projects/123/zones/europe-west1-b
+		}}
+	}
+	return &GCPResource{Type: "global", Labels: map[string]string{"project_id": project}}
+}
@@ -0,0 +1,263 @@
+package gologger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type GELFCompression int
+
+const (
+	GELFUncompressed GELFCompression = iota
+	GELFGzip
+	GELFZlib
+)
+
+// GELFOptions configures NewGELFSink, the zero value works.
+type GELFOptions struct {
+	Host        string          // the "host" attribute, os.Hostname() if empty
+	Compression GELFCompression // UDP only, GELF over TCP can't be compressed
+	ChunkSize   int             // maximum UDP datagram size, 1420 if zero
+
+	// After a failed connect writes fail right away for this long instead of
+	// blocking the daemon on every entry. A second if zero.
+	ReconnectDelay time.Duration
+}
+
+const (
+	gelfDefaultChunkSize = 1420
+	gelfChunkHeaderSize  = 12
+	gelfMaxChunks        = 128
+)
+
+// gelfSink sends GELF 1.1 messages to Graylog, chunked over UDP or null byte delimited over TCP.
+type gelfSink struct {
+	network string
+	addr    string
+	opts    GELFOptions
+
+	conn        net.Conn
+	retryAt     time.Time
+	buf         []byte
+	compressed  bytes.Buffer
+	nextChunkID uint64
+}
+
+// NewGELFSink returns a sink for the GELF input at addr, network being "udp" or "tcp".
+// Fields become additional fields prefixed with an underscore.
+func NewGELFSink(network, addr string, opts *GELFOptions) (Sink, error) {
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported GELF network %q", network)
+	}
+
+	s := &gelfSink{network: network, addr: addr}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Host == "" {
+		s.opts.Host, _ = os.Hostname()
+	}
+	if s.opts.ChunkSize <= gelfChunkHeaderSize {
+		s.opts.ChunkSize = gelfDefaultChunkSize
+	}
+	if s.opts.ReconnectDelay <= 0 {
+		s.opts.ReconnectDelay = time.Second
+	}
+
+	var seed [8]byte
+	_, _ = rand.Read(seed[:])
+	s.nextChunkID = binary.BigEndian.Uint64(seed[:])
+
+	err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *gelfSink) connect() error {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	conn, err := net.DialTimeout(s.network, s.addr, 10*time.Second)
+	if err != nil {
+		s.retryAt = time.Now().Add(s.opts.ReconnectDelay)
+		return fmt.Errorf("connecting to GELF input: %w", err)
+	}
+	s.conn = conn
+	return nil
+}
+
+func (s *gelfSink) Write(entry Entry) error {
+	s.buf = s.encode(s.buf[:0], entry)
+
+	if s.conn != nil {
+		err := s.send()
+		if err == nil || s.network == "udp" {
+			return err
+		}
+	}
+	if time.Now().Before(s.retryAt) {
+		return errors.New("GELF input unreachable, waiting to reconnect")
+	}
+	err := s.connect()
+	if err != nil {
+		return err
+	}
+	return s.send()
+}
+
+func (s *gelfSink) send() error {
+	if s.network == "tcp" {
+		_, err := s.conn.Write(append(s.buf, 0))
+		return err
+	}
+
+	payload, err := s.compress(s.buf)
+	if err != nil {
+		return err
+	}
+	if len(payload) <= s.opts.ChunkSize {
+		_, err = s.conn.Write(payload)
+		return err
+	}
+	return s.sendChunks(payload)
+}
+
+func (s *gelfSink) compress(b []byte) ([]byte, error) {
+	if s.opts.Compression == GELFUncompressed {
+		return b, nil
+	}
+
+	s.compressed.Reset()
+	var w io.WriteCloser
+	if s.opts.Compression == GELFZlib {
+		w = zlib.NewWriter(&s.compressed)
+	} else {
+		w = gzip.NewWriter(&s.compressed)
+	}
+	_, err := w.Write(b)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return s.compressed.Bytes(), nil
+}
+
+func (s *gelfSink) sendChunks(payload []byte) error {
+	size := s.opts.ChunkSize - gelfChunkHeaderSize
+	count := (len(payload) + size - 1) / size
+	if count > gelfMaxChunks {
+		return fmt.Errorf("GELF message of %d bytes needs more than %d chunks", len(payload), gelfMaxChunks)
+	}
+
+	s.nextChunkID++
+	chunk := make([]byte, 0, s.opts.ChunkSize)
+	for i := 0; i < count; i++ {
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = binary.BigEndian.AppendUint64(chunk, s.nextChunkID)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, payload[i*size:min(len(payload), (i+1)*size)]...)
+
+		_, err := s.conn.Write(chunk)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *gelfSink) encode(buf []byte, entry Entry) []byte {
+	message := entry.Message
+	if entry.Logger != "" {
+		message = "[" + entry.Logger + "] " + message
+	}
+	short, _, multiline := strings.Cut(message, "\n")
+
+	buf = append(buf, `{"version":"1.1","host":`...)
+	buf = appendJSONString(buf, s.opts.Host)
+	buf = append(buf, `,"short_message":`...)
+	buf = appendJSONString(buf, short)
+	if multiline || entry.Stack != "" {
+		full := message
+		if entry.Stack != "" {
+			full += "\n" + entry.Stack
+		}
+		buf = append(buf, `,"full_message":`...)
+		buf = appendJSONString(buf, full)
+	}
+	buf = append(buf, `,"timestamp":`...)
+	buf = strconv.AppendFloat(buf, float64(entry.Time.UnixMicro())/1e6, 'f', 6, 64)
+	buf = append(buf, `,"level":`...)
+	buf = strconv.AppendInt(buf, int64(levelSeverity(entry.Level)), 10)
+	buf = append(buf, `,"_level_name":`...)
+	buf = appendJSONString(buf, levelName(entry.Level))
+	if entry.Logger != "" {
+		buf = append(buf, `,"_logger":`...)
+		buf = appendJSONString(buf, entry.Logger)
+	}
+	buf = append(buf, `,"_func":`...)
+	buf = appendJSONString(buf, entry.Func)
+	buf = append(buf, `,"_line":`...)
+	buf = strconv.AppendInt(buf, int64(entry.Line), 10)
+
+	for _, f := range entry.Fields {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, gelfFieldName(f.Key))
+		buf = append(buf, ':')
+		buf = appendGELFValue(buf, f.Value)
+	}
+	return append(buf, '}')
+}
+
+// gelfFieldName prefixes key with an underscore and replaces characters GELF doesn't allow.
+// "_id" is reserved, so id becomes "_id_".
+func gelfFieldName(key string) string {
+	name := []byte("_" + key)
+	for i := 1; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			name[i] = '_'
+		}
+	}
+	if string(name) == "_id" {
+		return "_id_"
+	}
+	return string(name)
+}
+
+// appendGELFValue writes numbers as they are, additional fields may only be strings or numbers.
+func appendGELFValue(buf []byte, v interface{}) []byte {
+	switch t := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return appendJSONValue(buf, v)
+	case string:
+		return appendJSONString(buf, t)
+	}
+	return appendJSONString(buf, fmt.Sprint(v))
+}
+
+func (s *gelfSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,141 @@
+// Package gologgerbench measures the throughput and allocations of gologger's
+// encoders and sinks, with Run for a table at the command line and
+// "go test -bench ." in this directory for the benchmark harness.
+package gologgerbench
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danielwiratman/gologger"
+)
+
+// Case is a sink to measure, made fresh for each run.
+type Case struct {
+	Name string
+	Sink func() (gologger.Sink, error)
+}
+
+// Entry is what the cases write: a message with a few fields of the usual kinds.
+var Entry = gologger.Entry{
+	Time:    time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+	Level:   'I',
+	Func:    "main.handle",
+	Line:    42,
+	Message: "request served",
+	Fields: []gologger.Field{
+		{Key: "method", Value: "GET"},
+		{Key: "path", Value: "/api/v1/users"},
+		{Key: "status", Value: 200},
+		{Key: "duration", Value: 1500 * time.Microsecond},
+		{Key: "cached", Value: true},
+	},
+}
+
+// Encoders are the built-in encoders by name.
+func Encoders() map[string]func() gologger.Encoder {
+	return map[string]func() gologger.Encoder{
+		"text":    func() gologger.Encoder { return gologger.TextEncoder{} },
+		"console": func() gologger.Encoder { return &gologger.ConsoleEncoder{} },
+		"json":    func() gologger.Encoder { return gologger.JSONEncoder{} },
+		"logfmt":  func() gologger.Encoder { return gologger.LogfmtEncoder{} },
+		"cef":     func() gologger.Encoder { return gologger.CEFEncoder{} },
+		"msgpack": func() gologger.Encoder { return gologger.MsgpackEncoder{} },
+	}
+}
+
+// Cases combines every encoder with a writer sink to io.Discard, which measures
+// the encoding, and to a new file in dir, which adds the write syscalls.
+func Cases(dir string) []Case {
+	var cases []Case
+	for _, name := range []string{"text", "console", "json", "logfmt", "cef", "msgpack"} {
+		encoder := Encoders()[name]
+		cases = append(cases,
+			Case{name + "/discard", func() (gologger.Sink, error) {
+				return gologger.NewWriterSink(io.Discard, encoder()), nil
+			}},
+			Case{name + "/file", func() (gologger.Sink, error) {
+				f, err := os.CreateTemp(dir, "gologgerbench-*.log")
+				if err != nil {
+					return nil, err
+				}
+				return gologger.NewWriterSink(f, encoder()), nil
+			}},
+		)
+	}
+	return cases
+}
+
+// Measure writes Entry to a sink of c one entry at a time, and, if it is a
+// BatchWriter, in batches of 256 as the daemon does under load. Either result
+// is per entry.
+func Measure(c Case) (single, batch testing.BenchmarkResult, err error) {
+	s, err := c.Sink()
+	if err != nil {
+		return single, batch, err
+	}
+	defer func() {
+		err = errors.Join(err, s.Close())
+	}()
+
+	single = testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if werr := s.Write(Entry); werr != nil && err == nil {
+				err = werr
+			}
+		}
+	})
+	if bw, ok := s.(gologger.BatchWriter); ok {
+		entries := make([]gologger.Entry, 256)
+		for i := range entries {
+			entries[i] = Entry
+		}
+		batch = testing.Benchmark(func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i += len(entries) {
+				if werr := bw.WriteBatch(entries); werr != nil && err == nil {
+					err = werr
+				}
+			}
+		})
+	}
+	return single, batch, err
+}
+
+// Run measures every case and writes a table of entries per second, nanoseconds,
+// bytes and allocations per entry to w, e.g. os.Stdout. Files go to a temporary
+// directory.
+func Run(w io.Writer) error {
+	dir, err := os.MkdirTemp("", "gologgerbench")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "case\twrite\tentries/s\tns/entry\tB/entry\tallocs/entry\t")
+	var errs []error
+	for _, c := range Cases(dir) {
+		single, batch, err := Measure(c)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+			continue
+		}
+		printResult(tw, c.Name, "single", single)
+		if batch.N > 0 {
+			printResult(tw, c.Name, "batch", batch)
+		}
+	}
+	return errors.Join(tw.Flush(), errors.Join(errs...))
+}
+
+func printResult(w io.Writer, name, write string, r testing.BenchmarkResult) {
+	ns := float64(r.T.Nanoseconds()) / float64(r.N)
+	fmt.Fprintf(w, "%s\t%s\t%.0f\t%.0f\t%d\t%d\t\n", name, write, 1e9/ns, ns, r.AllocedBytesPerOp(), r.AllocsPerOp())
+}
@@ -0,0 +1,43 @@
+package gologgerbench
+
+import (
+	"io"
+	"testing"
+
+	"github.com/danielwiratman/gologger"
+)
+
+func BenchmarkSinks(b *testing.B) {
+	for _, c := range Cases(b.TempDir()) {
+		b.Run(c.Name, func(b *testing.B) {
+			s, err := c.Sink()
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer s.Close()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := s.Write(Entry); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkLog measures what logging costs the caller, the daemon encoding
+// and writing in the background.
+func BenchmarkLog(b *testing.B) {
+	l := gologger.L
+	l.SendToStdout = false
+	s := gologger.NewWriterSink(io.Discard, gologger.JSONEncoder{})
+	l.AddSink(s)
+	defer l.RemoveSink(s)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.INF("request served with {status}", gologger.Fields{"status": 200})
+	}
+	l.Flush()
+}
@@ -0,0 +1,29 @@
+// Package gologgerconfig lets gologger.Configure read YAML and TOML files.
+// Import it for its side effect:
+//
+//	import _ "github.com/danielwiratman/gologger/gologgerconfig"
+package gologgerconfig
+
+import (
+	"github.com/BurntSushi/toml"
+	"github.com/danielwiratman/gologger"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	gologger.RegisterConfigFormat(".yaml", decodeYAML)
+	gologger.RegisterConfigFormat(".yml", decodeYAML)
+	gologger.RegisterConfigFormat(".toml", decodeTOML)
+}
+
+func decodeYAML(data []byte) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	err := yaml.Unmarshal(data, &m)
+	return m, err
+}
+
+func decodeTOML(data []byte) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	err := toml.Unmarshal(data, &m)
+	return m, err
+}
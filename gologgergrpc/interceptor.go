@@ -0,0 +1,90 @@
+// Package gologgergrpc logs gRPC calls through gologger.
+package gologgergrpc
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/danielwiratman/gologger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor logs every unary RPC with its method, status code, duration
+// and peer. Panics in the handler are logged at E and returned as codes.Internal.
+func UnaryServerInterceptor(l *gologger.Log) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, l, info.FullMethod, r)
+			}
+			logRPC(ctx, l, info.FullMethod, start, err)
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs, logged once the stream ends.
+func StreamServerInterceptor(l *gologger.Log) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		ctx := ss.Context()
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, l, info.FullMethod, r)
+			}
+			logRPC(ctx, l, info.FullMethod, start, err)
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, l *gologger.Log, method string, r interface{}) error {
+	l.WithContext(ctx).With("grpc.method", method, "stack", string(debug.Stack())).
+		ERR(r, "Panic in gRPC handler")
+	return status.Errorf(codes.Internal, "panic in %s", method)
+}
+
+func logRPC(ctx context.Context, l *gologger.Log, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := codeLevel(code)
+	if !l.Enabled(level) {
+		return
+	}
+
+	fields := []interface{}{
+		"grpc.method", method,
+		"grpc.code", code.String(),
+		"grpc.duration", time.Since(start),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, "peer", p.Addr.String())
+	}
+	if err != nil {
+		fields = append(fields, "error", status.Convert(err).Message())
+	}
+
+	// depth 2 skips this function and the deferred closure, reporting grpc's caller of the interceptor
+	l.WithContext(ctx).With(fields...).Log(2, level, method+" "+code.String())
+}
+
+// codeLevel follows the usual split: client mistakes are informational,
+// server trouble is a warning or an error.
+func codeLevel(code codes.Code) byte {
+	switch code {
+	case codes.OK, codes.Canceled, codes.InvalidArgument, codes.NotFound,
+		codes.AlreadyExists, codes.Unauthenticated:
+		return 'I'
+	case codes.DeadlineExceeded, codes.PermissionDenied, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange, codes.Unavailable:
+		return 'W'
+	default:
+		return 'E'
+	}
+}
@@ -0,0 +1,33 @@
+package gologgergrpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/danielwiratman/gologger"
+	"github.com/danielwiratman/gologger/gologgertest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptorPanic(t *testing.T) {
+	sink := gologgertest.Attach(t, gologger.L)
+	interceptor := UnaryServerInterceptor(gologger.L)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	for _, value := range []interface{}{42, "boom", struct{}{}} {
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic(value)
+		})
+		if status.Code(err) != codes.Internal {
+			t.Errorf("panic(%v) returned %v, want Internal", value, err)
+		}
+	}
+
+	panics := sink.FilterMessage("Panic in gRPC handler")
+	if len(panics) != 3 || !strings.Contains(panics[0].Message, "42") {
+		t.Errorf("got %+v, want the 3 panics logged", panics)
+	}
+}
@@ -0,0 +1,136 @@
+// Package gologgerkafka publishes gologger entries to Kafka.
+package gologgerkafka
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/danielwiratman/gologger"
+	"github.com/segmentio/kafka-go"
+)
+
+// Options configures NewSink, Brokers and Topic are required.
+type Options struct {
+	Brokers []string
+	Topic   string
+
+	// Key picks the partition key of an entry, e.g. KeyHostname or KeyLogger.
+	// Entries are spread round robin if nil.
+	Key func(entry gologger.Entry) []byte
+
+	// "gzip", "snappy", "lz4", "zstd" or "" for none, at the codec's default
+	// level. kafka-go shares one codec per algorithm across the process, so
+	// levels, e.g. "zstd:3", can't be set per sink and are rejected.
+	Compression string
+	Encoder     gologger.Encoder // gologger.JSONEncoder if nil
+
+	BatchSize    int           // messages per produce request, 100 if zero
+	BatchTimeout time.Duration // longest a message waits for its batch, a second if zero
+
+	// Called from the producer's goroutine with the entries Kafka didn't accept.
+	OnError func(err error, entries []gologger.Entry)
+}
+
+// KeyHostname keeps the entries of a host in one partition, and thereby in order.
+func KeyHostname(gologger.Entry) []byte {
+	return hostname
+}
+
+// KeyLogger keeps the entries of a named logger in one partition.
+func KeyLogger(entry gologger.Entry) []byte {
+	return []byte(entry.Logger)
+}
+
+var hostname = func() []byte {
+	h, _ := os.Hostname()
+	return []byte(h)
+}()
+
+type sink struct {
+	writer  *kafka.Writer
+	key     func(entry gologger.Entry) []byte
+	encoder gologger.Encoder
+}
+
+// NewSink returns a sink producing entries to Kafka asynchronously, Write
+// only queues them. Delivery errors go to Options.OnError.
+func NewSink(opts Options) (gologger.Sink, error) {
+	if len(opts.Brokers) == 0 || opts.Topic == "" {
+		return nil, fmt.Errorf("kafka brokers and topic are required")
+	}
+
+	codec, err := gologger.ParseCompression(opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	var compression kafka.Compression
+	switch codec.Codec {
+	case "":
+	case "gzip":
+		compression = kafka.Gzip
+	case "snappy":
+		compression = kafka.Snappy
+	case "lz4":
+		compression = kafka.Lz4
+	case "zstd":
+		compression = kafka.Zstd
+	default:
+		return nil, fmt.Errorf("unknown kafka compression %q", opts.Compression)
+	}
+	if codec.Level != 0 {
+		return nil, fmt.Errorf("kafka compression %q: levels aren't supported, kafka-go shares its codecs across the process", opts.Compression)
+	}
+
+	s := &sink{key: opts.Key, encoder: opts.Encoder}
+	if s.encoder == nil {
+		s.encoder = gologger.JSONEncoder{}
+	}
+
+	s.writer = &kafka.Writer{
+		Addr:         kafka.TCP(opts.Brokers...),
+		Topic:        opts.Topic,
+		Compression:  compression,
+		BatchSize:    opts.BatchSize,
+		BatchTimeout: opts.BatchTimeout,
+		Async:        true,
+	}
+	if s.writer.BatchTimeout <= 0 {
+		s.writer.BatchTimeout = time.Second
+	}
+	if s.key != nil {
+		s.writer.Balancer = &kafka.Hash{}
+	}
+
+	onError := opts.OnError
+	s.writer.Completion = func(messages []kafka.Message, err error) {
+		if err == nil || onError == nil {
+			return
+		}
+		entries := make([]gologger.Entry, len(messages))
+		for i, m := range messages {
+			entries[i], _ = m.WriterData.(gologger.Entry)
+		}
+		onError(err, entries)
+	}
+	return s, nil
+}
+
+func (s *sink) Write(entry gologger.Entry) error {
+	// the writer holds on to the value, so every message gets its own buffer
+	m := kafka.Message{
+		Value:      bytes.TrimSuffix(s.encoder.Encode(nil, entry), []byte("\n")),
+		WriterData: entry,
+	}
+	if s.key != nil {
+		m.Key = s.key(entry)
+	}
+	return s.writer.WriteMessages(context.Background(), m)
+}
+
+// Close waits for the queued messages to be delivered.
+func (s *sink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,16 @@
+package gologgerkafka
+
+import "testing"
+
+func TestNewSinkCompression(t *testing.T) {
+	for _, c := range []string{"gzip:9", "zstd:3", "brotli"} {
+		if _, err := NewSink(Options{Brokers: []string{"localhost:9092"}, Topic: "logs", Compression: c}); err == nil {
+			t.Errorf("compression %q accepted", c)
+		}
+	}
+	s, err := NewSink(Options{Brokers: []string{"localhost:9092"}, Topic: "logs", Compression: "zstd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+}
@@ -0,0 +1,67 @@
+// Package gologgerlogr lets code built on go-logr, e.g. controller-runtime and
+// client-go, log through gologger.
+package gologgerlogr
+
+import (
+	"github.com/danielwiratman/gologger"
+	"github.com/go-logr/logr"
+)
+
+// New returns a logr.Logger writing through l. V(0) logs at I, higher
+// verbosities at D.
+func New(l *gologger.Log) logr.Logger {
+	return logr.New(&sink{l: l})
+}
+
+type sink struct {
+	l     *gologger.Log
+	depth int // logr frames between our methods and the caller
+}
+
+func vLevel(v int) byte {
+	if v > 0 {
+		return 'D'
+	}
+	return 'I'
+}
+
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.depth = info.CallDepth
+}
+
+func (s *sink) Enabled(v int) bool {
+	return s.l.Enabled(vLevel(v))
+}
+
+func (s *sink) Info(v int, msg string, keysAndValues ...interface{}) {
+	l := s.l
+	if len(keysAndValues) > 0 {
+		l = l.With(keysAndValues...)
+	}
+	l.Log(s.depth, vLevel(v), msg)
+}
+
+func (s *sink) Error(err error, msg string, keysAndValues ...interface{}) {
+	l := s.l
+	if len(keysAndValues) > 0 {
+		l = l.With(keysAndValues...)
+	}
+	if err == nil {
+		l.Log(s.depth, 'E', msg)
+		return
+	}
+	// ERR reports its own caller, which is us
+	l.CallerSkip(1+s.depth).ERR(err, msg)
+}
+
+func (s *sink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &sink{l: s.l.With(keysAndValues...), depth: s.depth}
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	return &sink{l: s.l.Named(name), depth: s.depth}
+}
+
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	return &sink{l: s.l, depth: s.depth + depth}
+}
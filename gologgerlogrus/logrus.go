@@ -0,0 +1,393 @@
+// Package gologgerlogrus mirrors the logging calls of logrus on top of
+// gologger, so a code base written against logrus migrates by changing its
+// import and constructor instead of every call site:
+//
+//	log "github.com/danielwiratman/gologger/gologgerlogrus"
+//
+//	logger := log.New(gologger.L)
+//	logger.WithField("user", id).Infof("logged in from %s", addr)
+//
+// The package level functions log through gologger.L. Hooks, formatters and
+// Out aren't mirrored, those are gologger's sinks and encoders.
+package gologgerlogrus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/danielwiratman/gologger"
+)
+
+// Fields are the fields of WithFields.
+type Fields map[string]interface{}
+
+// Level is a logrus level, the most severe first.
+type Level uint32
+
+const (
+	PanicLevel Level = iota
+	FatalLevel
+	ErrorLevel
+	WarnLevel
+	InfoLevel
+	DebugLevel
+	TraceLevel
+)
+
+// AllLevels lists the levels, the most severe first.
+var AllLevels = []Level{PanicLevel, FatalLevel, ErrorLevel, WarnLevel, InfoLevel, DebugLevel, TraceLevel}
+
+var levelNames = []string{"panic", "fatal", "error", "warning", "info", "debug", "trace"}
+
+func (level Level) String() string {
+	if int(level) >= len(levelNames) {
+		return "unknown"
+	}
+	return levelNames[level]
+}
+
+// ParseLevel parses a level name, e.g. "warn" or "warning".
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "panic":
+		return PanicLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "info":
+		return InfoLevel, nil
+	case "debug":
+		return DebugLevel, nil
+	case "trace":
+		return TraceLevel, nil
+	}
+	return 0, fmt.Errorf("not a valid logrus Level: %q", s)
+}
+
+// tag is gologger's level of level.
+func (level Level) tag() byte {
+	if int(level) >= len(levelNames) {
+		return 'T'
+	}
+	return "PFEWIDT"[level]
+}
+
+// priority is the gologger.Priority logging level and the ones above it.
+func (level Level) priority() gologger.Priority {
+	switch level {
+	case PanicLevel, FatalLevel:
+		return gologger.LOG_CRIT
+	case ErrorLevel:
+		return gologger.LOG_ERR
+	case WarnLevel:
+		return gologger.LOG_WARNING
+	case InfoLevel:
+		return gologger.LOG_INFO
+	case DebugLevel:
+		return gologger.LOG_DEBUG
+	default:
+		return gologger.LOG_TRACE
+	}
+}
+
+// ErrorKey is the field WithError adds.
+var ErrorKey = "error"
+
+// FieldLogger is logrus' interface of the same name, for code taking either a
+// *Logger or an *Entry.
+type FieldLogger interface {
+	WithField(key string, value interface{}) *Entry
+	WithFields(fields Fields) *Entry
+	WithError(err error) *Entry
+
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Printf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Warningf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Panicf(format string, args ...interface{})
+
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Print(args ...interface{})
+	Warn(args ...interface{})
+	Warning(args ...interface{})
+	Error(args ...interface{})
+	Fatal(args ...interface{})
+	Panic(args ...interface{})
+
+	Debugln(args ...interface{})
+	Infoln(args ...interface{})
+	Println(args ...interface{})
+	Warnln(args ...interface{})
+	Warningln(args ...interface{})
+	Errorln(args ...interface{})
+	Fatalln(args ...interface{})
+	Panicln(args ...interface{})
+}
+
+// Logger stands in for *logrus.Logger.
+type Logger struct {
+	calls
+}
+
+// New returns a Logger writing through l, where logrus.New would create one.
+func New(l *gologger.Log) *Logger {
+	return &Logger{calls{l: l}}
+}
+
+var std = New(gologger.L)
+
+// StandardLogger returns the Logger of the package level functions.
+func StandardLogger() *Logger {
+	return std
+}
+
+// SetLevel sets the Priority of the gologger.Log, for every logger sharing it.
+func (logger *Logger) SetLevel(level Level) {
+	logger.l.SetPriority(level.priority())
+}
+
+// GetLevel returns the least severe level the gologger.Log's Priority lets through.
+func (logger *Logger) GetLevel() Level {
+	p := logger.l.CurrentPriority()
+	for i := len(AllLevels) - 1; i >= 0; i-- {
+		if AllLevels[i].priority() <= p {
+			return AllLevels[i]
+		}
+	}
+	return PanicLevel
+}
+
+// IsLevelEnabled reports whether entries at level are logged.
+func (logger *Logger) IsLevelEnabled(level Level) bool {
+	return logger.l.Enabled(level.tag())
+}
+
+// Entry stands in for *logrus.Entry, a Logger with fields.
+type Entry struct {
+	calls
+}
+
+// calls are the logging calls of Logger and Entry.
+type calls struct {
+	l *gologger.Log
+}
+
+// WithField returns an Entry adding key to the entries.
+func (c *calls) WithField(key string, value interface{}) *Entry {
+	return &Entry{calls{l: c.l.With(key, value)}}
+}
+
+// WithFields returns an Entry adding fields to the entries, sorted by key.
+func (c *calls) WithFields(fields Fields) *Entry {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	keyvals := make([]interface{}, 0, 2*len(fields))
+	for _, key := range keys {
+		keyvals = append(keyvals, key, fields[key])
+	}
+	return &Entry{calls{l: c.l.With(keyvals...)}}
+}
+
+// WithError returns an Entry adding err as the ErrorKey field.
+func (c *calls) WithError(err error) *Entry {
+	return c.WithField(ErrorKey, err)
+}
+
+// Log logs at level, Logf and Logln likewise.
+func (c *calls) Log(level Level, args ...interface{}) {
+	if c.l.Enabled(level.tag()) {
+		c.log(level, fmt.Sprint(args...))
+	}
+}
+
+func (c *calls) Logf(level Level, format string, args ...interface{}) {
+	if c.l.Enabled(level.tag()) {
+		c.log(level, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *calls) Logln(level Level, args ...interface{}) {
+	if c.l.Enabled(level.tag()) {
+		c.log(level, sprintln(args))
+	}
+}
+
+// log writes message at level, called by the exported methods, so the caller
+// reported is theirs. Fatal exits and Panic panics, as in logrus.
+func (c *calls) log(level Level, message string) {
+	switch level {
+	case FatalLevel:
+		c.l.CallerSkip(2).FTL(nil, message)
+	case PanicLevel:
+		c.l.CallerSkip(2).PANIC(nil, message)
+	default:
+		c.l.Log(1, level.tag(), message)
+	}
+}
+
+// sprintln is fmt.Sprintln without the newline, always spacing the operands.
+func sprintln(args []interface{}) string {
+	s := fmt.Sprintln(args...)
+	return s[:len(s)-1]
+}
+
+func (c *calls) Tracef(format string, args ...interface{}) {
+	if c.l.Enabled('T') {
+		c.log(TraceLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *calls) Debugf(format string, args ...interface{}) {
+	if c.l.Enabled('D') {
+		c.log(DebugLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *calls) Infof(format string, args ...interface{}) {
+	if c.l.Enabled('I') {
+		c.log(InfoLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *calls) Printf(format string, args ...interface{}) {
+	if c.l.Enabled('I') {
+		c.log(InfoLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *calls) Warnf(format string, args ...interface{}) {
+	if c.l.Enabled('W') {
+		c.log(WarnLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *calls) Warningf(format string, args ...interface{}) {
+	if c.l.Enabled('W') {
+		c.log(WarnLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *calls) Errorf(format string, args ...interface{}) {
+	if c.l.Enabled('E') {
+		c.log(ErrorLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *calls) Fatalf(format string, args ...interface{}) {
+	c.log(FatalLevel, fmt.Sprintf(format, args...))
+}
+
+func (c *calls) Panicf(format string, args ...interface{}) {
+	c.log(PanicLevel, fmt.Sprintf(format, args...))
+}
+
+func (c *calls) Trace(args ...interface{}) {
+	if c.l.Enabled('T') {
+		c.log(TraceLevel, fmt.Sprint(args...))
+	}
+}
+
+func (c *calls) Debug(args ...interface{}) {
+	if c.l.Enabled('D') {
+		c.log(DebugLevel, fmt.Sprint(args...))
+	}
+}
+
+func (c *calls) Info(args ...interface{}) {
+	if c.l.Enabled('I') {
+		c.log(InfoLevel, fmt.Sprint(args...))
+	}
+}
+
+func (c *calls) Print(args ...interface{}) {
+	if c.l.Enabled('I') {
+		c.log(InfoLevel, fmt.Sprint(args...))
+	}
+}
+
+func (c *calls) Warn(args ...interface{}) {
+	if c.l.Enabled('W') {
+		c.log(WarnLevel, fmt.Sprint(args...))
+	}
+}
+
+func (c *calls) Warning(args ...interface{}) {
+	if c.l.Enabled('W') {
+		c.log(WarnLevel, fmt.Sprint(args...))
+	}
+}
+
+func (c *calls) Error(args ...interface{}) {
+	if c.l.Enabled('E') {
+		c.log(ErrorLevel, fmt.Sprint(args...))
+	}
+}
+
+func (c *calls) Fatal(args ...interface{}) {
+	c.log(FatalLevel, fmt.Sprint(args...))
+}
+
+func (c *calls) Panic(args ...interface{}) {
+	c.log(PanicLevel, fmt.Sprint(args...))
+}
+
+func (c *calls) Traceln(args ...interface{}) {
+	if c.l.Enabled('T') {
+		c.log(TraceLevel, sprintln(args))
+	}
+}
+
+func (c *calls) Debugln(args ...interface{}) {
+	if c.l.Enabled('D') {
+		c.log(DebugLevel, sprintln(args))
+	}
+}
+
+func (c *calls) Infoln(args ...interface{}) {
+	if c.l.Enabled('I') {
+		c.log(InfoLevel, sprintln(args))
+	}
+}
+
+func (c *calls) Println(args ...interface{}) {
+	if c.l.Enabled('I') {
+		c.log(InfoLevel, sprintln(args))
+	}
+}
+
+func (c *calls) Warnln(args ...interface{}) {
+	if c.l.Enabled('W') {
+		c.log(WarnLevel, sprintln(args))
+	}
+}
+
+func (c *calls) Warningln(args ...interface{}) {
+	if c.l.Enabled('W') {
+		c.log(WarnLevel, sprintln(args))
+	}
+}
+
+func (c *calls) Errorln(args ...interface{}) {
+	if c.l.Enabled('E') {
+		c.log(ErrorLevel, sprintln(args))
+	}
+}
+
+func (c *calls) Fatalln(args ...interface{}) {
+	c.log(FatalLevel, sprintln(args))
+}
+
+func (c *calls) Panicln(args ...interface{}) {
+	c.log(PanicLevel, sprintln(args))
+}
@@ -0,0 +1,84 @@
+package gologgerlogrus
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/danielwiratman/gologger"
+	"github.com/danielwiratman/gologger/gologgertest"
+)
+
+func TestCalls(t *testing.T) {
+	sink := gologgertest.Attach(t, gologger.L)
+	priority := gologger.L.CurrentPriority()
+	t.Cleanup(func() { gologger.L.SetPriority(priority) })
+
+	logger := New(gologger.L)
+	logger.SetLevel(DebugLevel)
+	if got := logger.GetLevel(); got != DebugLevel {
+		t.Errorf("GetLevel() = %v, want debug", got)
+	}
+	logger.WithFields(Fields{"b": 2, "a": 1}).WithError(errors.New("boom")).Warnf("failed %d times", 3)
+	logger.Infoln("one", 2)
+	logger.Trace("not logged")
+	Debug("package", "level")
+
+	entries := sink.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	want := []struct {
+		level   byte
+		message string
+	}{{'W', "failed 3 times"}, {'I', "one 2"}, {'D', "packagelevel"}}
+	for i, w := range want {
+		e := entries[i]
+		if e.Level != w.level || e.Message != w.message {
+			t.Errorf("entry %d is %c %q, want %c %q", i, e.Level, e.Message, w.level, w.message)
+		}
+		if !strings.HasSuffix(e.Func, ".TestCalls") {
+			t.Errorf("entry %d has caller %q, want TestCalls", i, e.Func)
+		}
+	}
+	var keys []string
+	for _, f := range entries[0].Fields {
+		keys = append(keys, f.Key)
+	}
+	if got := strings.Join(keys, ","); got != "a,b,error" {
+		t.Errorf("fields are %s, want a,b,error", got)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range AllLevels {
+		got, err := ParseLevel(level.String())
+		if err != nil || got != level {
+			t.Errorf("ParseLevel(%q) = %v, %v", level.String(), got, err)
+		}
+	}
+	if got, _ := ParseLevel("WARN"); got != WarnLevel {
+		t.Errorf("ParseLevel(WARN) = %v, want warning", got)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(verbose) succeeded")
+	}
+}
+
+func TestPanic(t *testing.T) {
+	sink := gologgertest.Attach(t, gologger.L)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "gave up" {
+				t.Errorf("recovered %v, want the message", r)
+			}
+		}()
+		WithField("attempts", 3).Panic("gave up")
+	}()
+
+	entries := sink.FilterLevel('P')
+	if len(entries) != 1 || entries[0].Message != "gave up" || !strings.HasSuffix(entries[0].Func, ".TestPanic.func1") {
+		t.Errorf("got %+v, want the panic logged by TestPanic", entries)
+	}
+}
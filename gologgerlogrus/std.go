@@ -0,0 +1,163 @@
+package gologgerlogrus
+
+import "fmt"
+
+// The package level functions of logrus, logging through StandardLogger.
+
+func WithField(key string, value interface{}) *Entry { return std.WithField(key, value) }
+func WithFields(fields Fields) *Entry                { return std.WithFields(fields) }
+func WithError(err error) *Entry                     { return std.WithError(err) }
+
+func SetLevel(level Level)            { std.SetLevel(level) }
+func GetLevel() Level                 { return std.GetLevel() }
+func IsLevelEnabled(level Level) bool { return std.IsLevelEnabled(level) }
+
+func Tracef(format string, args ...interface{}) {
+	if std.l.Enabled('T') {
+		std.log(TraceLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func Debugf(format string, args ...interface{}) {
+	if std.l.Enabled('D') {
+		std.log(DebugLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func Infof(format string, args ...interface{}) {
+	if std.l.Enabled('I') {
+		std.log(InfoLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func Printf(format string, args ...interface{}) {
+	if std.l.Enabled('I') {
+		std.log(InfoLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func Warnf(format string, args ...interface{}) {
+	if std.l.Enabled('W') {
+		std.log(WarnLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func Warningf(format string, args ...interface{}) {
+	if std.l.Enabled('W') {
+		std.log(WarnLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func Errorf(format string, args ...interface{}) {
+	if std.l.Enabled('E') {
+		std.log(ErrorLevel, fmt.Sprintf(format, args...))
+	}
+}
+
+func Fatalf(format string, args ...interface{}) {
+	std.log(FatalLevel, fmt.Sprintf(format, args...))
+}
+
+func Panicf(format string, args ...interface{}) {
+	std.log(PanicLevel, fmt.Sprintf(format, args...))
+}
+
+func Trace(args ...interface{}) {
+	if std.l.Enabled('T') {
+		std.log(TraceLevel, fmt.Sprint(args...))
+	}
+}
+
+func Debug(args ...interface{}) {
+	if std.l.Enabled('D') {
+		std.log(DebugLevel, fmt.Sprint(args...))
+	}
+}
+
+func Info(args ...interface{}) {
+	if std.l.Enabled('I') {
+		std.log(InfoLevel, fmt.Sprint(args...))
+	}
+}
+
+func Print(args ...interface{}) {
+	if std.l.Enabled('I') {
+		std.log(InfoLevel, fmt.Sprint(args...))
+	}
+}
+
+func Warn(args ...interface{}) {
+	if std.l.Enabled('W') {
+		std.log(WarnLevel, fmt.Sprint(args...))
+	}
+}
+
+func Warning(args ...interface{}) {
+	if std.l.Enabled('W') {
+		std.log(WarnLevel, fmt.Sprint(args...))
+	}
+}
+
+func Error(args ...interface{}) {
+	if std.l.Enabled('E') {
+		std.log(ErrorLevel, fmt.Sprint(args...))
+	}
+}
+
+func Fatal(args ...interface{}) {
+	std.log(FatalLevel, fmt.Sprint(args...))
+}
+
+func Panic(args ...interface{}) {
+	std.log(PanicLevel, fmt.Sprint(args...))
+}
+
+func Traceln(args ...interface{}) {
+	if std.l.Enabled('T') {
+		std.log(TraceLevel, sprintln(args))
+	}
+}
+
+func Debugln(args ...interface{}) {
+	if std.l.Enabled('D') {
+		std.log(DebugLevel, sprintln(args))
+	}
+}
+
+func Infoln(args ...interface{}) {
+	if std.l.Enabled('I') {
+		std.log(InfoLevel, sprintln(args))
+	}
+}
+
+func Println(args ...interface{}) {
+	if std.l.Enabled('I') {
+		std.log(InfoLevel, sprintln(args))
+	}
+}
+
+func Warnln(args ...interface{}) {
+	if std.l.Enabled('W') {
+		std.log(WarnLevel, sprintln(args))
+	}
+}
+
+func Warningln(args ...interface{}) {
+	if std.l.Enabled('W') {
+		std.log(WarnLevel, sprintln(args))
+	}
+}
+
+func Errorln(args ...interface{}) {
+	if std.l.Enabled('E') {
+		std.log(ErrorLevel, sprintln(args))
+	}
+}
+
+func Fatalln(args ...interface{}) {
+	std.log(FatalLevel, sprintln(args))
+}
+
+func Panicln(args ...interface{}) {
+	std.log(PanicLevel, sprintln(args))
+}
@@ -0,0 +1,230 @@
+// Package gologgerotel exports gologger entries over OTLP and correlates them with OpenTelemetry traces.
+package gologgerotel
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/danielwiratman/gologger"
+	"go.opentelemetry.io/otel/trace"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// TraceFields returns trace_id and span_id of the span active in ctx. Register
+// it with l.AddContextExtractor so WithContext and FromContext attach them.
+func TraceFields(ctx context.Context) []gologger.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []gologger.Field{
+		{Key: "trace_id", Value: sc.TraceID().String()},
+		{Key: "span_id", Value: sc.SpanID().String()},
+	}
+}
+
+// Options configures NewSink, the zero value exports to a collector on localhost.
+type Options struct {
+	Endpoint  string      // host:port of the collector, "localhost:4317" if empty
+	Insecure  bool        // plain text instead of TLS
+	TLSConfig *tls.Config // system roots if nil
+	Headers   map[string]string
+
+	ServiceName        string // the service.name resource attribute, the program name if empty
+	ResourceAttributes map[string]string
+
+	Timeout     time.Duration // per export request, 10s if zero
+	Batch       gologger.BatchOptions
+	DialOptions []grpc.DialOption // appended to the ones derived from the fields above
+}
+
+type exporter struct {
+	conn     *grpc.ClientConn
+	client   collogspb.LogsServiceClient
+	headers  metadata.MD
+	timeout  time.Duration
+	resource *resourcepb.Resource
+}
+
+// NewSink returns a sink exporting entries as OTLP log records over gRPC.
+// trace_id and span_id fields, as added by TraceFields, become the record's
+// trace context; the other fields become attributes.
+func NewSink(opts Options) (gologger.Sink, error) {
+	if opts.Endpoint == "" {
+		opts.Endpoint = "localhost:4317"
+	}
+	if opts.ServiceName == "" {
+		opts.ServiceName = filepath.Base(os.Args[0])
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	creds := credentials.NewTLS(opts.TLSConfig)
+	if opts.Insecure {
+		creds = insecure.NewCredentials()
+	}
+	dialOptions := append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, opts.DialOptions...)
+	conn, err := grpc.NewClient(opts.Endpoint, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to OTLP collector: %w", err)
+	}
+
+	e := &exporter{
+		conn:    conn,
+		client:  collogspb.NewLogsServiceClient(conn),
+		headers: metadata.New(opts.Headers),
+		timeout: opts.Timeout,
+		resource: &resourcepb.Resource{
+			Attributes: []*commonpb.KeyValue{stringAttribute("service.name", opts.ServiceName)},
+		},
+	}
+	for k, v := range opts.ResourceAttributes {
+		e.resource.Attributes = append(e.resource.Attributes, stringAttribute(k, v))
+	}
+
+	return &sink{Sink: gologger.NewBatchSink(opts.Batch, e.export), conn: conn}, nil
+}
+
+type sink struct {
+	gologger.Sink
+	conn *grpc.ClientConn
+}
+
+func (s *sink) Flush() error {
+	return s.Sink.(gologger.Flusher).Flush()
+}
+
+func (s *sink) Close() error {
+	err := s.Sink.Close()
+	s.conn.Close()
+	return err
+}
+
+func (e *exporter) export(entries []gologger.Entry) error {
+	records := make([]*logspb.LogRecord, len(entries))
+	for i, entry := range entries {
+		records[i] = logRecord(entry)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	ctx = metadata.NewOutgoingContext(ctx, e.headers)
+
+	resp, err := e.client.Export(ctx, &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: e.resource,
+			ScopeLogs: []*logspb.ScopeLogs{{
+				Scope:      &commonpb.InstrumentationScope{Name: "github.com/danielwiratman/gologger"},
+				LogRecords: records,
+			}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	if ps := resp.GetPartialSuccess(); ps != nil && ps.RejectedLogRecords > 0 {
+		return gologger.Permanent(fmt.Errorf("collector rejected %d log records: %s", ps.RejectedLogRecords, ps.ErrorMessage))
+	}
+	return nil
+}
+
+func logRecord(entry gologger.Entry) *logspb.LogRecord {
+	severity, text := severity(entry.Level)
+	r := &logspb.LogRecord{
+		TimeUnixNano:         uint64(entry.Time.UnixNano()),
+		ObservedTimeUnixNano: uint64(entry.Time.UnixNano()),
+		SeverityNumber:       severity,
+		SeverityText:         text,
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: entry.Message}},
+		Attributes: []*commonpb.KeyValue{
+			stringAttribute("code.function", entry.Func),
+			{Key: "code.lineno", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(entry.Line)}}},
+		},
+	}
+	if entry.Logger != "" {
+		r.Attributes = append(r.Attributes, stringAttribute("logger.name", entry.Logger))
+	}
+	if entry.Stack != "" {
+		r.Attributes = append(r.Attributes, stringAttribute("exception.stacktrace", entry.Stack))
+	}
+
+	for _, f := range entry.Fields {
+		if id, ok := f.Value.(string); ok {
+			switch f.Key {
+			case "trace_id":
+				if b, err := hex.DecodeString(id); err == nil && len(b) == 16 {
+					r.TraceId = b
+					continue
+				}
+			case "span_id":
+				if b, err := hex.DecodeString(id); err == nil && len(b) == 8 {
+					r.SpanId = b
+					continue
+				}
+			}
+		}
+		r.Attributes = append(r.Attributes, &commonpb.KeyValue{Key: f.Key, Value: anyValue(f.Value)})
+	}
+	return r
+}
+
+func severity(level byte) (logspb.SeverityNumber, string) {
+	switch gologger.StandardLevel(level) {
+	case 'F', 'P':
+		return logspb.SeverityNumber_SEVERITY_NUMBER_FATAL, "FATAL"
+	case 'E':
+		return logspb.SeverityNumber_SEVERITY_NUMBER_ERROR, "ERROR"
+	case 'W':
+		return logspb.SeverityNumber_SEVERITY_NUMBER_WARN, "WARN"
+	case 'I':
+		return logspb.SeverityNumber_SEVERITY_NUMBER_INFO, "INFO"
+	case 'T':
+		return logspb.SeverityNumber_SEVERITY_NUMBER_TRACE, "TRACE"
+	default:
+		return logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG, "DEBUG"
+	}
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func anyValue(v interface{}) *commonpb.AnyValue {
+	switch t := v.(type) {
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: t}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: t}}
+	case []byte:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: t}}
+	case error:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: t.Error()}}
+	case fmt.Stringer:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: t.String()}}
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: rv.Int()}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(rv.Uint())}}
+	case reflect.Float32, reflect.Float64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: rv.Float()}}
+	}
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprint(v)}}
+}
@@ -0,0 +1,67 @@
+// Package gologgerprom exposes gologger's own metrics and counts of what is logged to Prometheus.
+package gologgerprom
+
+import (
+	"github.com/danielwiratman/gologger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type collector struct {
+	l *gologger.Log
+
+	logged      *prometheus.Desc
+	dropped     *prometheus.Desc
+	sampledOut  *prometheus.Desc
+	rateLimited *prometheus.Desc
+	overBudget  *prometheus.Desc
+	sinkErrors  *prometheus.Desc
+	queueLength *prometheus.Desc
+	queueCap    *prometheus.Desc
+	highWater   *prometheus.Desc
+}
+
+// NewCollector returns a prometheus.Collector reporting l.Stats() as
+// gologger_* metrics, to be registered with prometheus.MustRegister or a custom registry.
+func NewCollector(l *gologger.Log) prometheus.Collector {
+	return &collector{
+		l: l,
+
+		logged:      prometheus.NewDesc("gologger_messages_total", "Log entries queued for the sinks.", []string{"level"}, nil),
+		dropped:     prometheus.NewDesc("gologger_dropped_total", "Log entries discarded because the queue was full.", nil, nil),
+		sampledOut:  prometheus.NewDesc("gologger_sampled_out_total", "Log entries discarded by sampling.", nil, nil),
+		rateLimited: prometheus.NewDesc("gologger_rate_limited_total", "Log entries discarded by the per call site rate limit.", nil, nil),
+		overBudget:  prometheus.NewDesc("gologger_over_budget_total", "Log entries discarded by a log budget.", nil, nil),
+		sinkErrors:  prometheus.NewDesc("gologger_sink_errors_total", "Failed sink writes, flushes and syncs.", nil, nil),
+		queueLength: prometheus.NewDesc("gologger_queue_length", "Log entries waiting for the daemon.", nil, nil),
+		queueCap:    prometheus.NewDesc("gologger_queue_capacity", "Size of the log queue.", nil, nil),
+		highWater:   prometheus.NewDesc("gologger_queue_high_water_mark", "Longest the log queue has been.", nil, nil),
+	}
+}
+
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.logged
+	ch <- c.dropped
+	ch <- c.sampledOut
+	ch <- c.rateLimited
+	ch <- c.overBudget
+	ch <- c.sinkErrors
+	ch <- c.queueLength
+	ch <- c.queueCap
+	ch <- c.highWater
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	s := c.l.Stats()
+
+	for level, n := range s.Logged {
+		ch <- prometheus.MustNewConstMetric(c.logged, prometheus.CounterValue, float64(n), string(level))
+	}
+	ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(s.Dropped))
+	ch <- prometheus.MustNewConstMetric(c.sampledOut, prometheus.CounterValue, float64(s.SampledOut))
+	ch <- prometheus.MustNewConstMetric(c.rateLimited, prometheus.CounterValue, float64(s.RateLimited))
+	ch <- prometheus.MustNewConstMetric(c.overBudget, prometheus.CounterValue, float64(s.OverBudget))
+	ch <- prometheus.MustNewConstMetric(c.sinkErrors, prometheus.CounterValue, float64(s.SinkErrors))
+	ch <- prometheus.MustNewConstMetric(c.queueLength, prometheus.GaugeValue, float64(s.QueueLength))
+	ch <- prometheus.MustNewConstMetric(c.queueCap, prometheus.GaugeValue, float64(s.QueueCapacity))
+	ch <- prometheus.MustNewConstMetric(c.highWater, prometheus.GaugeValue, float64(s.HighWaterMark))
+}
@@ -0,0 +1,47 @@
+package gologgerprom
+
+import (
+	"github.com/danielwiratman/gologger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type hook struct {
+	messages *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+}
+
+// NewHook returns a hook counting the entries written as
+// log_messages_total{level="E"} and, for errors and worse, the functions
+// logging them as log_errors_total{caller="main.run"}, for alerts on error
+// rates. Add it with l.AddHook; it registers the counters with reg.
+func NewHook(reg prometheus.Registerer) (gologger.Hook, error) {
+	h := &hook{
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "log_messages_total",
+			Help: "Log entries written, by level.",
+		}, []string{"level"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "log_errors_total",
+			Help: "Log entries at error level or worse, by the function logging them.",
+		}, []string{"caller"}),
+	}
+	for _, c := range []prometheus.Collector{h.messages, h.errors} {
+		err := reg.Register(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func (h *hook) Before(entry *gologger.Entry) bool {
+	return true
+}
+
+func (h *hook) After(entry gologger.Entry, err error) {
+	h.messages.WithLabelValues(string(entry.Level)).Inc()
+	switch gologger.StandardLevel(entry.Level) {
+	case 'E', 'F', 'P':
+		h.errors.WithLabelValues(entry.Func).Inc()
+	}
+}
@@ -0,0 +1,166 @@
+// Package gologgersentry reports gologger errors to Sentry.
+package gologgersentry
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielwiratman/gologger"
+	"github.com/getsentry/sentry-go"
+)
+
+// Options configures NewSink.
+type Options struct {
+	DSN         string
+	Release     string
+	Environment string
+
+	MinLevel   byte    // least severe level reported, 'E' if zero, e.g. 'W' to include warnings
+	SampleRate float64 // share of events sent, all if zero
+
+	// Events buffered while Sentry is slow or unreachable, more are dropped
+	// rather than holding up the logger. 100 if zero.
+	QueueSize int
+
+	// Used instead of creating a client from the fields above.
+	Client *sentry.Client
+}
+
+// How long Sync and Close wait for queued events to be sent.
+const flushTimeout = 2 * time.Second
+
+type sink struct {
+	client   *sentry.Client
+	minLevel int
+}
+
+// NewSink returns a sink sending entries at MinLevel and above to Sentry, with the
+// error passed to ERR as the exception, the entry's stack trace and its fields as tags.
+// Register it with AddSink; Write only queues the event.
+func NewSink(opts Options) (gologger.Sink, error) {
+	s := &sink{client: opts.Client, minLevel: severity(opts.MinLevel)}
+	if opts.MinLevel == 0 {
+		s.minLevel = severity('E')
+	}
+
+	if s.client == nil {
+		transport := sentry.NewHTTPTransport()
+		transport.BufferSize = opts.QueueSize
+		if transport.BufferSize <= 0 {
+			transport.BufferSize = 100
+		}
+
+		client, err := sentry.NewClient(sentry.ClientOptions{
+			Dsn:         opts.DSN,
+			Release:     opts.Release,
+			Environment: opts.Environment,
+			SampleRate:  opts.SampleRate,
+			Transport:   transport,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating sentry client: %w", err)
+		}
+		s.client = client
+	}
+	return s, nil
+}
+
+// severity orders levels from most to least severe.
+func severity(level byte) int {
+	switch gologger.StandardLevel(level) {
+	case 'F', 'P':
+		return 0
+	case 'E':
+		return 1
+	case 'W':
+		return 2
+	case 'I':
+		return 3
+	default:
+		return 4
+	}
+}
+
+func sentryLevel(level byte) sentry.Level {
+	switch gologger.StandardLevel(level) {
+	case 'F', 'P':
+		return sentry.LevelFatal
+	case 'E':
+		return sentry.LevelError
+	case 'W':
+		return sentry.LevelWarning
+	case 'I':
+		return sentry.LevelInfo
+	default:
+		return sentry.LevelDebug
+	}
+}
+
+func (s *sink) Write(entry gologger.Entry) error {
+	if severity(entry.Level) > s.minLevel {
+		return nil
+	}
+
+	event := sentry.NewEvent()
+	event.Level = sentryLevel(entry.Level)
+	event.Message = entry.Message
+	event.Timestamp = entry.Time
+	event.Logger = entry.Logger
+	event.Tags["func"] = entry.Func + ":" + strconv.Itoa(entry.Line)
+	for _, f := range entry.Fields {
+		event.Tags[f.Key] = fmt.Sprint(f.Value)
+	}
+
+	stacktrace := parseStack(entry.Stack)
+	if entry.Err != nil {
+		event.SetException(entry.Err, 10)
+		// SetException adds the stack it was called from, the daemon's, not the caller's
+		event.Exception[len(event.Exception)-1].Stacktrace = stacktrace
+	} else if stacktrace != nil {
+		event.Threads = []sentry.Thread{{Stacktrace: stacktrace, Current: true, Crashed: entry.Level == 'F' || entry.Level == 'P'}}
+	}
+
+	s.client.CaptureEvent(event, nil, nil)
+	return nil
+}
+
+// parseStack turns an Entry.Stack, "func()\n\tfile:line\n" per frame with the
+// innermost first, into Sentry's frames, the innermost last.
+func parseStack(stack string) *sentry.Stacktrace {
+	if stack == "" {
+		return nil
+	}
+
+	var frames []sentry.Frame
+	lines := strings.Split(strings.TrimSuffix(stack, "\n"), "\n")
+	for i := 0; i+1 < len(lines); i += 2 {
+		location := strings.TrimPrefix(lines[i+1], "\t")
+		file, line, _ := strings.Cut(location, ":")
+		n, _ := strconv.Atoi(line)
+		frames = append(frames, sentry.NewFrame(runtime.Frame{
+			Function: strings.TrimSuffix(lines[i], "()"),
+			File:     file,
+			Line:     n,
+		}))
+	}
+
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return &sentry.Stacktrace{Frames: frames}
+}
+
+// Sync waits for queued events to be sent, so fatal ones make it out before the process exits.
+func (s *sink) Sync() error {
+	if !s.client.Flush(flushTimeout) {
+		return fmt.Errorf("sentry events still queued after %s", flushTimeout)
+	}
+	return nil
+}
+
+func (s *sink) Close() error {
+	return s.Sync()
+}
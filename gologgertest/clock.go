@@ -0,0 +1,49 @@
+package gologgertest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/danielwiratman/gologger"
+)
+
+// Clock is a gologger.Clock standing still until the test moves it, so
+// entries carry the same times on every run.
+type Clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+// NewClock returns a clock showing t.
+func NewClock(t time.Time) *Clock {
+	return &Clock{t: t}
+}
+
+// Freeze sets a new clock showing t on l for the duration of the test.
+func Freeze(tb testing.TB, l *gologger.Log, t time.Time) *Clock {
+	c := NewClock(t)
+	l.SetClock(c)
+	tb.Cleanup(func() { l.SetClock(nil) })
+	return c
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// Set moves the clock to t.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	c.t = t
+	c.mu.Unlock()
+}
+
+// Add moves the clock on by d.
+func (c *Clock) Add(d time.Duration) {
+	c.mu.Lock()
+	c.t = c.t.Add(d)
+	c.mu.Unlock()
+}
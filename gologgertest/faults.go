@@ -0,0 +1,209 @@
+package gologgertest
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/danielwiratman/gologger"
+)
+
+// ErrInjected is the error of the writes a FaultySink or FaultyWriter fails.
+var ErrInjected = errors.New("gologgertest: injected fault")
+
+// Faults says how a FaultySink or FaultyWriter misbehaves. Which writes are
+// hit is random but follows Seed, so a failing test fails the same way again.
+type Faults struct {
+	Seed int64
+
+	FailRate    float64 // share of writes failing with ErrInjected, 0 to 1
+	PartialRate float64 // share of writes passing part of their data on before failing
+	FailAfter   int     // writes succeeding before all later ones fail, 0 for no such outage
+
+	Latency time.Duration // added to every write
+	Jitter  time.Duration // up to this much more, random
+}
+
+// faults decides the fate of each write.
+type faults struct {
+	Faults
+
+	mu       sync.Mutex
+	rng      *rand.Rand
+	failing  bool
+	writes   int
+	injected int
+}
+
+type fault int
+
+const (
+	noFault fault = iota
+	failWrite
+	partialWrite
+)
+
+func newFaults(f Faults) *faults {
+	return &faults{Faults: f, rng: rand.New(rand.NewSource(f.Seed))}
+}
+
+// next sleeps for the latency of a write and returns what happens to it, and
+// for a partial write how much of n gets through.
+func (f *faults) next(n int) (fault, int) {
+	f.mu.Lock()
+	f.writes++
+	delay := f.Latency
+	if f.Jitter > 0 {
+		delay += time.Duration(f.rng.Int63n(int64(f.Jitter)))
+	}
+	what, part := noFault, n
+	switch r := f.rng.Float64(); {
+	case f.failing || f.FailAfter > 0 && f.writes > f.FailAfter || r < f.FailRate:
+		what, part = failWrite, 0
+	case r < f.FailRate+f.PartialRate && n > 0:
+		what, part = partialWrite, f.rng.Intn(n)
+	}
+	if what != noFault {
+		f.injected++
+	}
+	f.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return what, part
+}
+
+func (f *faults) setFailing(on bool) {
+	f.mu.Lock()
+	f.failing = on
+	f.mu.Unlock()
+}
+
+func (f *faults) counts() (writes, injected int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.writes, f.injected
+}
+
+// FaultySink wraps a sink, failing and delaying its writes as Faults says, to
+// see how an application copes with a misbehaving log backend. A partial
+// write of a batch passes the first entries on, then fails.
+type FaultySink struct {
+	next   gologger.Sink
+	faults *faults
+}
+
+// NewFaultySink returns a sink writing to next, which may be nil to discard
+// the entries, with the faults of f.
+func NewFaultySink(next gologger.Sink, f Faults) *FaultySink {
+	return &FaultySink{next: next, faults: newFaults(f)}
+}
+
+func (s *FaultySink) Write(entry gologger.Entry) error {
+	if what, _ := s.faults.next(1); what != noFault {
+		return ErrInjected
+	}
+	if s.next == nil {
+		return nil
+	}
+	return s.next.Write(entry)
+}
+
+func (s *FaultySink) WriteBatch(entries []gologger.Entry) error {
+	what, part := s.faults.next(len(entries))
+	if err := s.pass(entries[:part]); err != nil || what != noFault {
+		return errors.Join(err, faultErr(what))
+	}
+	return nil
+}
+
+// pass hands entries to the wrapped sink.
+func (s *FaultySink) pass(entries []gologger.Entry) error {
+	if s.next == nil || len(entries) == 0 {
+		return nil
+	}
+	if bw, ok := s.next.(gologger.BatchWriter); ok {
+		return bw.WriteBatch(entries)
+	}
+	for _, entry := range entries {
+		if err := s.next.Write(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FaultySink) Flush() error {
+	if f, ok := s.next.(gologger.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (s *FaultySink) Close() error {
+	if s.next == nil {
+		return nil
+	}
+	return s.next.Close()
+}
+
+// SetFailing starts an outage, failing every write until it is switched off again.
+func (s *FaultySink) SetFailing(on bool) {
+	s.faults.setFailing(on)
+}
+
+// Writes returns the number of writes so far and how many of them had a fault injected.
+func (s *FaultySink) Writes() (writes, injected int) {
+	return s.faults.counts()
+}
+
+// FaultyWriter wraps an io.Writer with the faults of Faults, a partial write
+// writing part of the bytes and returning io.ErrShortWrite, e.g. for
+// gologger.NewWriterSink.
+type FaultyWriter struct {
+	w      io.Writer
+	faults *faults
+}
+
+// NewFaultyWriter returns a writer writing to w, io.Discard if nil, with the faults of f.
+func NewFaultyWriter(w io.Writer, f Faults) *FaultyWriter {
+	if w == nil {
+		w = io.Discard
+	}
+	return &FaultyWriter{w: w, faults: newFaults(f)}
+}
+
+func (w *FaultyWriter) Write(p []byte) (int, error) {
+	what, part := w.faults.next(len(p))
+	switch what {
+	case failWrite:
+		return 0, ErrInjected
+	case partialWrite:
+		n, err := w.w.Write(p[:part])
+		if err == nil {
+			err = io.ErrShortWrite
+		}
+		return n, err
+	}
+	return w.w.Write(p)
+}
+
+// SetFailing starts an outage, failing every write until it is switched off again.
+func (w *FaultyWriter) SetFailing(on bool) {
+	w.faults.setFailing(on)
+}
+
+// Writes returns the number of writes so far and how many of them had a fault injected.
+func (w *FaultyWriter) Writes() (writes, injected int) {
+	return w.faults.counts()
+}
+
+func faultErr(what fault) error {
+	if what == noFault {
+		return nil
+	}
+	return ErrInjected
+}
@@ -0,0 +1,99 @@
+// Package gologgertest records gologger entries in memory, so tests can check
+// what code logged without capturing stdout.
+package gologgertest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/danielwiratman/gologger"
+)
+
+// Sink keeps every entry written to it. It is safe to read from the test while
+// the daemon writes.
+type Sink struct {
+	l *gologger.Log // flushed before reading, nil if the sink was added by hand
+
+	mu      sync.Mutex
+	entries []gologger.Entry
+}
+
+// NewSink returns an empty sink, to be added with l.AddSink. Prefer Attach,
+// which also waits for queued entries before they are read.
+func NewSink() *Sink {
+	return &Sink{}
+}
+
+// Attach adds a new sink to l for the duration of the test.
+func Attach(tb testing.TB, l *gologger.Log) *Sink {
+	s := &Sink{l: l}
+	l.AddSink(s)
+	tb.Cleanup(func() { l.RemoveSink(s) })
+	return s
+}
+
+func (s *Sink) Write(entry gologger.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *Sink) Close() error {
+	return nil
+}
+
+// Entries returns what was logged so far, oldest first.
+func (s *Sink) Entries() []gologger.Entry {
+	if s.l != nil {
+		s.l.Flush()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]gologger.Entry(nil), s.entries...)
+}
+
+// FilterLevel returns the entries logged at level, e.g. 'E'.
+func (s *Sink) FilterLevel(level byte) []gologger.Entry {
+	var filtered []gologger.Entry
+	for _, entry := range s.Entries() {
+		if entry.Level == level {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// FilterMessage returns the entries whose message contains substr.
+func (s *Sink) FilterMessage(substr string) []gologger.Entry {
+	var filtered []gologger.Entry
+	for _, entry := range s.Entries() {
+		if strings.Contains(entry.Message, substr) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// ContainsMessage reports whether any entry's message contains substr.
+func (s *Sink) ContainsMessage(substr string) bool {
+	return len(s.FilterMessage(substr)) > 0
+}
+
+// Len is the number of entries logged so far.
+func (s *Sink) Len() int {
+	return len(s.Entries())
+}
+
+// Reset forgets the entries recorded so far.
+func (s *Sink) Reset() {
+	if s.l != nil {
+		s.l.Flush()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = nil
+}
@@ -0,0 +1,110 @@
+package gologgertui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/danielwiratman/gologger"
+)
+
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l\x1b[2J" // the alternate screen, without cursor
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+)
+
+// How often the screen is redrawn at most while entries come in.
+const redrawInterval = 50 * time.Millisecond
+
+// Run shows the entries of src on the terminal of stdin and stdout until q is
+// pressed, ctx is done or, with an error, src fails. opts may be nil.
+func Run(ctx context.Context, src Source, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	v, err := newViewer(*opts)
+	if err != nil {
+		return fmt.Errorf("gologgertui: %w", err)
+	}
+	in, out := os.Stdin, os.Stdout
+
+	restore, err := makeRaw(in)
+	if err != nil {
+		return fmt.Errorf("gologgertui: stdin isn't a terminal: %w", err)
+	}
+	defer restore()
+	io.WriteString(out, enterScreen)
+	defer io.WriteString(out, leaveScreen)
+	v.width, v.height, _ = termSize(out)
+	v.dirty = true
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	entries := make(chan gologger.Entry, 1000)
+	srcDone := make(chan error, 1)
+	go func() {
+		srcDone <- src(ctx, entries)
+	}()
+
+	// the reader waits for more after each read, so it stops on q rather
+	// than taking the next key press from whoever reads the terminal next
+	keys := make(chan []rune)
+	more := make(chan bool)
+	go readKeys(in, keys, more)
+
+	ticker := time.NewTicker(redrawInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case entry := <-entries:
+			v.add(entry)
+		case err := <-srcDone:
+			srcDone = nil
+			if err != nil {
+				v.message = err.Error()
+			} else {
+				v.message = "no more entries"
+			}
+			v.dirty = true
+		case pressed, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, k := range pressed {
+				if v.key(k) {
+					more <- false
+					return nil
+				}
+			}
+			more <- true
+			out.Write(v.draw())
+		case <-ticker.C:
+			if width, height, err := termSize(out); err == nil && (width != v.width || height != v.height) {
+				v.width, v.height, v.dirty = width, height, true
+			}
+			if v.dirty {
+				out.Write(v.draw())
+			}
+		}
+	}
+}
+
+// readKeys sends the keys read from in until told not to read more.
+func readKeys(in io.Reader, keys chan<- []rune, more <-chan bool) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+		keys <- parseKeys(buf[:n])
+		if !<-more {
+			return
+		}
+	}
+}
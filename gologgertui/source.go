@@ -0,0 +1,149 @@
+package gologgertui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/danielwiratman/gologger"
+)
+
+// Source sends entries to the viewer until ctx is done or it runs out.
+type Source func(ctx context.Context, entries chan<- gologger.Entry) error
+
+// Subscribe is a Source of the entries l writes from now on, see
+// Log.Subscribe. Turn off the sinks writing to the terminal meanwhile, like
+// SendToStdout.
+func Subscribe(l *gologger.Log) Source {
+	return func(ctx context.Context, entries chan<- gologger.Entry) error {
+		ch, cancel := l.Subscribe(nil)
+		defer cancel()
+		for {
+			select {
+			case entry := <-ch:
+				select {
+				case entries <- entry:
+				case <-ctx.Done():
+					return nil
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// How often Files looks for more entries while following.
+const followInterval = 250 * time.Millisecond
+
+// Files is a Source of the entries of the logfiles names, in the text or
+// JSON format, read with opts as by gologger.NewReader. With follow, it goes
+// on with what is appended to them like tail -F, also after logrotate or
+// LogfileLink moved on to a new file.
+func Files(names []string, opts *gologger.ReaderOptions, follow bool) Source {
+	return func(ctx context.Context, entries chan<- gologger.Entry) error {
+		var wg sync.WaitGroup
+		errs := make([]error, len(names))
+		for i, name := range names {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				errs[i] = readFile(ctx, name, opts, follow, entries)
+			}(i, name)
+		}
+		wg.Wait()
+		return errors.Join(errs...)
+	}
+}
+
+// readFile sends the entries of name, following it until ctx is done.
+func readFile(ctx context.Context, name string, opts *gologger.ReaderOptions, follow bool, entries chan<- gologger.Entry) error {
+	var f *os.File
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	var partial []byte // the last line, not complete yet
+	buf := make([]byte, 64*1024)
+	for {
+		if f == nil {
+			var err error
+			f, err = os.Open(name)
+			if err != nil && (!follow || !os.IsNotExist(err)) {
+				return err
+			}
+		}
+
+		// checked before reading the rest, which comes before the new file
+		switching := follow && f != nil && moved(f, name)
+
+		// what is there so far, complete lines only so entries aren't cut
+		var chunk []byte
+		for f != nil {
+			n, err := f.Read(buf)
+			chunk = append(chunk, buf[:n]...)
+			if err == io.EOF || n == 0 {
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+		chunk = append(partial, chunk...)
+		partial = nil
+		if i := bytes.LastIndexByte(chunk, '\n'); i < len(chunk)-1 && follow && !switching {
+			chunk, partial = chunk[:i+1], append([]byte(nil), chunk[i+1:]...)
+		}
+		if len(chunk) > 0 {
+			r := gologger.NewReader(bytes.NewReader(chunk), opts)
+			for {
+				entry, err := r.Next()
+				if err != nil {
+					break
+				}
+				select {
+				case entries <- entry:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+		if !follow {
+			return nil
+		}
+		if switching {
+			f.Close()
+			f = nil
+			continue
+		}
+
+		select {
+		case <-time.After(followInterval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// moved reports whether name no longer is the file f, or f was truncated.
+func moved(f *os.File, name string) bool {
+	current, err := f.Stat()
+	if err != nil {
+		return true
+	}
+	named, err := os.Stat(name)
+	if err != nil {
+		return false // not recreated yet, stay with what we have
+	}
+	if !os.SameFile(current, named) {
+		return true
+	}
+	offset, err := f.Seek(0, io.SeekCurrent)
+	return err == nil && named.Size() < offset
+}
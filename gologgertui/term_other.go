@@ -0,0 +1,18 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package gologgertui
+
+import (
+	"errors"
+	"os"
+)
+
+var errNoTerminal = errors.New("terminal control isn't supported on this platform")
+
+func makeRaw(*os.File) (func(), error) {
+	return nil, errNoTerminal
+}
+
+func termSize(*os.File) (int, int, error) {
+	return 0, 0, errNoTerminal
+}
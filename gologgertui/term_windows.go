@@ -0,0 +1,47 @@
+package gologgertui
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// makeRaw switches the console f to raw input with escape sequences for the
+// special keys, and its output, os.Stdout, to take them, returning the
+// function restoring both.
+func makeRaw(f *os.File) (func(), error) {
+	in := windows.Handle(f.Fd())
+	var inMode uint32
+	err := windows.GetConsoleMode(in, &inMode)
+	if err != nil {
+		return nil, err
+	}
+	raw := inMode &^ (windows.ENABLE_ECHO_INPUT | windows.ENABLE_PROCESSED_INPUT | windows.ENABLE_LINE_INPUT)
+	err = windows.SetConsoleMode(in, raw|windows.ENABLE_VIRTUAL_TERMINAL_INPUT)
+	if err != nil {
+		return nil, err
+	}
+
+	out := windows.Handle(os.Stdout.Fd())
+	var outMode uint32
+	outErr := windows.GetConsoleMode(out, &outMode)
+	if outErr == nil {
+		_ = windows.SetConsoleMode(out, outMode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+	}
+	return func() {
+		_ = windows.SetConsoleMode(in, inMode)
+		if outErr == nil {
+			_ = windows.SetConsoleMode(out, outMode)
+		}
+	}, nil
+}
+
+// termSize returns the columns and rows of the console window.
+func termSize(*os.File) (int, int, error) {
+	var info windows.ConsoleScreenBufferInfo
+	err := windows.GetConsoleScreenBufferInfo(windows.Handle(os.Stdout.Fd()), &info)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(info.Window.Right-info.Window.Left) + 1, int(info.Window.Bottom-info.Window.Top) + 1, nil
+}
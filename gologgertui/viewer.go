@@ -0,0 +1,504 @@
+// Package gologgertui is a terminal viewer of log entries, live from a Log in
+// the same process or from logfiles, for debugging on headless servers over
+// SSH. It filters by level, logger, regular expression and filter
+// expression, pauses and scrolls back, and colors the levels.
+//
+//	err := gologgertui.Run(ctx, gologgertui.Files([]string{"app.log"}, nil, true), nil)
+//
+// Keys: space pauses and resumes following new entries, the arrows, page up
+// and down, home and end scroll, l and L raise and lower the minimum level, n
+// filters by logger, / by regular expression, f by filter expression, c
+// clears the filters, C toggles color, ? shows the keys, q quits.
+package gologgertui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/danielwiratman/gologger"
+)
+
+// Options configures Run, the zero value works.
+type Options struct {
+	Scrollback int    // entries kept to scroll back to, 10000 if zero
+	Level      string // minimum level to start with, e.g. "info", all if empty
+	Filter     string // expression of gologger.ParseFilter to start with
+	NoColor    bool
+}
+
+// levels are what l and L step through.
+var levels = []string{"", "debug", "info", "warn", "error", "fatal"}
+
+// line is an entry as the viewer shows it.
+type line struct {
+	entry  gologger.Entry
+	color  string // rendered with escape sequences
+	plain  string // without, for the regular expression
+	logger string
+}
+
+// viewer is the state of the screen, apart from the terminal.
+type viewer struct {
+	scrollback int
+
+	lines   []line // oldest first
+	dropped int    // trimmed off the front of lines, the number of lines[0]
+	shown   []int  // numbers of the lines the filters keep
+	end     int    // of shown, the bottom line on the screen being shown[end-1]
+	follow  bool   // keep end at the newest line, off when paused or scrolled back
+
+	level      string
+	logger     string
+	re         *regexp.Regexp
+	filterExpr string
+	filter     gologger.Filter
+
+	prompt  rune   // the key of the prompt being typed into, 0 if none
+	input   []rune // typed so far
+	message string // an error or notice in the status line
+	help    bool
+
+	width, height int
+	noColor       bool
+	enc           gologger.ConsoleEncoder
+	plain         gologger.ConsoleEncoder
+	dirty         bool
+}
+
+func newViewer(opts Options) (*viewer, error) {
+	v := &viewer{
+		scrollback: opts.Scrollback,
+		follow:     true,
+		noColor:    opts.NoColor,
+		width:      80,
+		height:     24,
+		enc:        gologger.ConsoleEncoder{Multiline: gologger.MultilineEscape},
+		plain:      gologger.ConsoleEncoder{NoColor: true, Multiline: gologger.MultilineEscape},
+	}
+	if v.scrollback <= 0 {
+		v.scrollback = 10000
+	}
+	if opts.Level != "" {
+		if _, err := gologger.ParseLevel(opts.Level); err != nil {
+			return nil, err
+		}
+		v.level = opts.Level
+	}
+	if opts.Filter != "" {
+		f, err := gologger.ParseFilter(opts.Filter)
+		if err != nil {
+			return nil, err
+		}
+		v.filterExpr, v.filter = opts.Filter, f
+	}
+	return v, nil
+}
+
+// add takes in a new entry.
+func (v *viewer) add(entry gologger.Entry) {
+	entry.Stack = ""
+	entry.Message = printable(entry.Message)
+	entry.Logger = printable(entry.Logger)
+	copied := false
+	for i, f := range entry.Fields {
+		if s, ok := f.Value.(string); ok && printable(s) != s {
+			if !copied {
+				// the fields may be shared with the logger that wrote them
+				entry.Fields, copied = append([]gologger.Field(nil), entry.Fields...), true
+			}
+			entry.Fields[i].Value = printable(s)
+		}
+	}
+
+	l := line{
+		entry:  entry,
+		color:  strings.TrimSuffix(string(v.enc.Encode(nil, entry)), "\n"),
+		plain:  strings.TrimSuffix(string(v.plain.Encode(nil, entry)), "\n"),
+		logger: entry.Logger,
+	}
+	v.lines = append(v.lines, l)
+	if v.keeps(l) {
+		v.shown = append(v.shown, v.dropped+len(v.lines)-1)
+		if v.follow {
+			v.end = len(v.shown)
+			v.dirty = true
+		}
+	}
+	if len(v.lines) >= v.scrollback+v.scrollback/4 {
+		v.trim(len(v.lines) - v.scrollback)
+	}
+	if !v.follow {
+		// the count of new entries in the status line
+		v.dirty = true
+	}
+}
+
+// trim drops the n oldest lines.
+func (v *viewer) trim(n int) {
+	v.lines = append([]line(nil), v.lines[n:]...)
+	v.dropped += n
+	i := sort.SearchInts(v.shown, v.dropped)
+	v.shown = v.shown[i:]
+	v.end = max(v.end-i, 0)
+}
+
+// keeps reports whether the filters keep l.
+func (v *viewer) keeps(l line) bool {
+	if v.level != "" {
+		threshold, _ := gologger.ParseLevel(v.level)
+		if p, err := gologger.ParseLevel(string(gologger.StandardLevel(l.entry.Level))); err == nil && p > threshold {
+			return false
+		}
+	}
+	if v.logger != "" && l.logger != v.logger && !strings.HasPrefix(l.logger, v.logger+"/") && !strings.HasPrefix(l.logger, v.logger+".") {
+		return false
+	}
+	if v.re != nil && !v.re.MatchString(l.plain) {
+		return false
+	}
+	return v.filter == nil || v.filter(l.entry)
+}
+
+// refilter applies changed filters to the scrollback, keeping the bottom line
+// in place when not following.
+func (v *viewer) refilter() {
+	bottom := -1
+	if !v.follow && v.end > 0 {
+		bottom = v.shown[v.end-1]
+	}
+	v.shown = v.shown[:0]
+	for i, l := range v.lines {
+		if v.keeps(l) {
+			v.shown = append(v.shown, v.dropped+i)
+		}
+	}
+	v.end = len(v.shown)
+	if bottom >= 0 {
+		v.end = sort.SearchInts(v.shown, bottom+1)
+	}
+	v.dirty = true
+}
+
+// Special keys, as negative runes.
+const (
+	keyUp rune = -1 - iota
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEscape
+	keyEnter
+	keyBackspace
+	keyInterrupt
+)
+
+// parseKeys splits what a read from the terminal returned into keys.
+func parseKeys(b []byte) []rune {
+	sequences := map[string]rune{
+		"\x1b[A": keyUp, "\x1b[B": keyDown, "\x1bOA": keyUp, "\x1bOB": keyDown,
+		"\x1b[5~": keyPageUp, "\x1b[6~": keyPageDown,
+		"\x1b[H": keyHome, "\x1b[F": keyEnd, "\x1b[1~": keyHome, "\x1b[4~": keyEnd, "\x1bOH": keyHome, "\x1bOF": keyEnd,
+	}
+	var keys []rune
+	s := string(b)
+	for len(s) > 0 {
+		if s[0] == 0x1b {
+			matched := false
+			for seq, key := range sequences {
+				if strings.HasPrefix(s, seq) {
+					keys, s, matched = append(keys, key), s[len(seq):], true
+					break
+				}
+			}
+			if !matched {
+				// Escape itself, or a sequence we don't know, skipped whole
+				if len(s) > 1 && (s[1] == '[' || s[1] == 'O') {
+					i := 2
+					for i < len(s) && (s[i] < 0x40 || s[i] > 0x7e) {
+						i++
+					}
+					s = s[min(i+1, len(s)):]
+					continue
+				}
+				keys, s = append(keys, keyEscape), s[1:]
+			}
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		switch r {
+		case '\r', '\n':
+			r = keyEnter
+		case 0x7f, 0x08:
+			r = keyBackspace
+		case 0x03:
+			r = keyInterrupt
+		}
+		keys, s = append(keys, r), s[size:]
+	}
+	return keys
+}
+
+// key handles a key press and reports whether to quit.
+func (v *viewer) key(k rune) bool {
+	v.dirty = true
+	if v.prompt != 0 {
+		v.promptKey(k)
+		return false
+	}
+	if v.help && k != keyInterrupt {
+		v.help = false
+		return false
+	}
+
+	rows := v.rows()
+	switch k {
+	case 'q', keyInterrupt:
+		return true
+	case ' ':
+		v.follow = !v.follow
+		if v.follow {
+			v.end = len(v.shown)
+		}
+	case keyUp, 'k':
+		v.scroll(-1)
+	case keyDown, 'j':
+		v.scroll(1)
+	case keyPageUp, 'b':
+		v.scroll(-rows)
+	case keyPageDown:
+		v.scroll(rows)
+	case keyHome, 'g':
+		v.follow, v.end = false, min(rows, len(v.shown))
+	case keyEnd, 'G':
+		v.follow, v.end = true, len(v.shown)
+	case 'l', 'L':
+		i := 0
+		for i < len(levels) && levels[i] != v.level {
+			i++
+		}
+		if k == 'l' {
+			i = min(i+1, len(levels)-1)
+		} else {
+			i = max(i-1, 0)
+		}
+		v.level = levels[i]
+		v.refilter()
+	case 'n', '/', 'f':
+		v.prompt, v.message = k, ""
+		switch k {
+		case 'n':
+			v.input = []rune(v.logger)
+		case '/':
+			v.input = nil
+			if v.re != nil {
+				v.input = []rune(v.re.String())
+			}
+		case 'f':
+			v.input = []rune(v.filterExpr)
+		}
+	case 'c':
+		v.level, v.logger, v.re, v.filterExpr, v.filter, v.message = "", "", nil, "", nil, ""
+		v.refilter()
+	case 'C':
+		v.noColor = !v.noColor
+	case '?':
+		v.help = true
+	default:
+		v.dirty = false
+	}
+	return false
+}
+
+// promptKey edits the prompt, applying it on enter.
+func (v *viewer) promptKey(k rune) {
+	switch k {
+	case keyEscape, keyInterrupt:
+		v.prompt = 0
+		return
+	case keyBackspace:
+		if len(v.input) > 0 {
+			v.input = v.input[:len(v.input)-1]
+		}
+		return
+	case keyEnter:
+	default:
+		if k >= 0 && unicode.IsPrint(k) {
+			v.input = append(v.input, k)
+		}
+		return
+	}
+
+	input := strings.TrimSpace(string(v.input))
+	switch v.prompt {
+	case 'n':
+		v.logger = input
+	case '/':
+		re, err := regexp.Compile(input)
+		if err != nil {
+			v.message = err.Error()
+			return
+		}
+		v.re = re
+		if input == "" {
+			v.re = nil
+		}
+	case 'f':
+		var f gologger.Filter
+		if input != "" {
+			var err error
+			f, err = gologger.ParseFilter(input)
+			if err != nil {
+				v.message = err.Error()
+				return
+			}
+		}
+		v.filterExpr, v.filter = input, f
+	}
+	v.prompt = 0
+	v.refilter()
+}
+
+// scroll moves the bottom of the screen by n lines.
+func (v *viewer) scroll(n int) {
+	v.end = min(max(v.end+n, min(v.rows(), len(v.shown))), len(v.shown))
+	v.follow = v.end == len(v.shown) && n > 0
+}
+
+// rows is the number of entries on the screen.
+func (v *viewer) rows() int {
+	return max(v.height-1, 1)
+}
+
+// draw renders the screen.
+func (v *viewer) draw() []byte {
+	buf := []byte("\x1b[H")
+	rows := v.rows()
+	if v.help {
+		for i := 0; i < rows; i++ {
+			buf = append(buf, "\x1b[2K"...)
+			if i < len(helpLines) {
+				buf = append(buf, fit(helpLines[i], v.width)...)
+			}
+			buf = append(buf, "\r\n"...)
+		}
+	} else {
+		start := max(v.end-rows, 0)
+		for i := 0; i < rows; i++ {
+			buf = append(buf, "\x1b[2K"...)
+			if n := start + i; n < v.end {
+				l := v.lines[v.shown[n]-v.dropped]
+				if v.noColor {
+					buf = append(buf, fit(l.plain, v.width)...)
+				} else {
+					buf = append(buf, fit(l.color, v.width)...)
+				}
+			}
+			buf = append(buf, "\r\n"...)
+		}
+	}
+
+	buf = append(buf, "\x1b[2K\x1b[7m"...)
+	buf = append(buf, fit(v.status(), v.width)...)
+	buf = append(buf, "\x1b[0m"...)
+	v.dirty = false
+	return buf
+}
+
+// status is the bottom line: the prompt being typed or the state of the view.
+func (v *viewer) status() string {
+	if v.prompt != 0 {
+		label := map[rune]string{'n': "logger: ", '/': "regexp: ", 'f': "filter: "}[v.prompt]
+		return label + string(v.input) + "█"
+	}
+
+	var parts []string
+	if v.follow {
+		parts = append(parts, "FOLLOW")
+	} else {
+		parts = append(parts, fmt.Sprintf("PAUSED +%d", len(v.shown)-v.end))
+	}
+	if v.level != "" {
+		parts = append(parts, "level>="+v.level)
+	}
+	if v.logger != "" {
+		parts = append(parts, "logger="+v.logger)
+	}
+	if v.re != nil {
+		parts = append(parts, "/"+v.re.String()+"/")
+	}
+	if v.filterExpr != "" {
+		parts = append(parts, v.filterExpr)
+	}
+	parts = append(parts, fmt.Sprintf("%d/%d", len(v.shown), len(v.lines)))
+	if v.message != "" {
+		parts = append(parts, v.message)
+	}
+	parts = append(parts, "? help")
+	return " " + strings.Join(parts, "  ")
+}
+
+var helpLines = []string{
+	"",
+	"  space          pause or resume following new entries",
+	"  ↑ ↓ j k        scroll a line",
+	"  PgUp PgDn b    scroll a page",
+	"  Home End g G   oldest, newest",
+	"  l L            raise, lower the minimum level",
+	"  n              filter by logger, with its children",
+	"  /              filter by regular expression",
+	"  f              filter by expression, e.g. level>=warn || user==\"ann\"",
+	"  c              clear the filters",
+	"  C              toggle color",
+	"  q              quit",
+	"",
+	"  any key to go back",
+}
+
+// fit cuts s to width columns, leaving its escape sequences alone.
+func fit(s string, width int) string {
+	var b strings.Builder
+	columns := 0
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '[' {
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			j = min(j+1, len(s))
+			b.WriteString(s[i:j])
+			i = j
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		if columns < width {
+			b.WriteString(s[i : i+size])
+			columns++
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// printable replaces the control characters of s, like escape sequences of
+// the logged text, that would mess up the screen. Newlines are left to
+// MultilineEscape.
+func printable(s string) string {
+	control := func(r rune) bool { return r != '\n' && unicode.IsControl(r) }
+	if strings.IndexFunc(s, control) < 0 {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return ' '
+		case control(r):
+			return '?'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,71 @@
+package gologgertui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/danielwiratman/gologger"
+)
+
+func TestParseKeys(t *testing.T) {
+	got := parseKeys([]byte("q\x1b[A\x1b[6~\x1b\x1b[99;5Xé\r\x7f"))
+	want := []rune{'q', keyUp, keyPageDown, keyEscape, 'é', keyEnter, keyBackspace}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseKeys = %v, want %v", got, want)
+	}
+}
+
+func TestFit(t *testing.T) {
+	if got, want := fit("\x1b[31mE\x1b[0m héllo", 4), "\x1b[31mE\x1b[0m hé"; got != want {
+		t.Errorf("fit = %q, want %q", got, want)
+	}
+}
+
+func TestViewerFilters(t *testing.T) {
+	v, _ := newViewer(Options{Scrollback: 4})
+	for _, e := range []gologger.Entry{
+		{Level: 'D', Logger: "db/pool", Message: "acquired"},
+		{Level: 'E', Logger: "db", Message: "timeout"},
+		{Level: 'I', Logger: "dbx", Message: "started"},
+		{Level: 'W', Message: "slow \x1b[2Jquery"},
+	} {
+		v.add(e)
+	}
+	messages := func() []string {
+		var m []string
+		for _, n := range v.shown[:v.end] {
+			m = append(m, v.lines[n-v.dropped].entry.Message)
+		}
+		return m
+	}
+	if got := messages(); len(got) != 4 || got[3] != "slow ?[2Jquery" {
+		t.Errorf("all: %q", got)
+	}
+
+	press := func(s string) {
+		for _, k := range parseKeys([]byte(s)) {
+			v.key(k)
+		}
+	}
+	press("ndb\r")
+	if got := messages(); !reflect.DeepEqual(got, []string{"acquired", "timeout"}) {
+		t.Errorf("logger db: %q", got)
+	}
+	press("ll")
+	if got := messages(); !reflect.DeepEqual(got, []string{"timeout"}) {
+		t.Errorf("logger db at info: %q", got)
+	}
+
+	// paused, new entries wait below, old ones fall out of the scrollback
+	press("c ")
+	for i := 0; i < 2; i++ {
+		v.add(gologger.Entry{Level: 'I', Message: "new"})
+	}
+	if got := messages(); !reflect.DeepEqual(got, []string{"started", "slow ?[2Jquery"}) {
+		t.Errorf("paused: %q", got)
+	}
+	press("\x1b[F")
+	if got := messages(); !reflect.DeepEqual(got, []string{"started", "slow ?[2Jquery", "new", "new"}) {
+		t.Errorf("resumed: %q", got)
+	}
+}
@@ -0,0 +1,224 @@
+// Package gologgerzap mirrors zap's SugaredLogger on top of gologger, so a
+// code base written against it migrates by changing its import and where the
+// logger is built instead of every call site:
+//
+//	sugar := gologgerzap.New(gologger.L)
+//	sugar.Infow("request served", "path", path, "status", status)
+//
+// Keys and values alternate as in zap, typed zap.Field values aren't
+// supported. DPanic logs at error level, as in zap's production config.
+package gologgerzap
+
+import (
+	"fmt"
+
+	"github.com/danielwiratman/gologger"
+)
+
+// SugaredLogger stands in for *zap.SugaredLogger.
+type SugaredLogger struct {
+	l *gologger.Log
+}
+
+// New returns a SugaredLogger writing through l.
+func New(l *gologger.Log) *SugaredLogger {
+	return &SugaredLogger{l: l}
+}
+
+// With returns a logger adding alternating keys and values to its entries.
+func (s *SugaredLogger) With(args ...interface{}) *SugaredLogger {
+	return &SugaredLogger{l: s.l.With(args...)}
+}
+
+// Named returns a logger named name below this one's, joined with a dot as in
+// zap.
+func (s *SugaredLogger) Named(name string) *SugaredLogger {
+	return &SugaredLogger{l: s.l.Named(name)}
+}
+
+// Sync waits for the entries logged so far to be written.
+func (s *SugaredLogger) Sync() error {
+	s.l.Flush()
+	return nil
+}
+
+// output writes message at level, called by the exported methods, so the
+// caller reported is theirs. Fatal exits and Panic panics, as in zap.
+func output(l *gologger.Log, level byte, message string) {
+	switch level {
+	case 'F':
+		l.CallerSkip(2).FTL(nil, message)
+	case 'P':
+		l.CallerSkip(2).PANIC(nil, message)
+	default:
+		l.Log(1, level, message)
+	}
+}
+
+// sprint is fmt.Sprint, sparing a lone string the copy.
+func sprint(args []interface{}) string {
+	if len(args) == 1 {
+		if s, ok := args[0].(string); ok {
+			return s
+		}
+	}
+	return fmt.Sprint(args...)
+}
+
+// sprintln is fmt.Sprintln without the newline, always spacing the operands.
+func sprintln(args []interface{}) string {
+	s := fmt.Sprintln(args...)
+	return s[:len(s)-1]
+}
+
+func (s *SugaredLogger) Debug(args ...interface{}) {
+	if s.l.Enabled('D') {
+		output(s.l, 'D', sprint(args))
+	}
+}
+
+func (s *SugaredLogger) Debugf(template string, args ...interface{}) {
+	if s.l.Enabled('D') {
+		output(s.l, 'D', fmt.Sprintf(template, args...))
+	}
+}
+
+func (s *SugaredLogger) Debugln(args ...interface{}) {
+	if s.l.Enabled('D') {
+		output(s.l, 'D', sprintln(args))
+	}
+}
+
+func (s *SugaredLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	if s.l.Enabled('D') {
+		output(s.l.With(keysAndValues...), 'D', msg)
+	}
+}
+
+func (s *SugaredLogger) Info(args ...interface{}) {
+	if s.l.Enabled('I') {
+		output(s.l, 'I', sprint(args))
+	}
+}
+
+func (s *SugaredLogger) Infof(template string, args ...interface{}) {
+	if s.l.Enabled('I') {
+		output(s.l, 'I', fmt.Sprintf(template, args...))
+	}
+}
+
+func (s *SugaredLogger) Infoln(args ...interface{}) {
+	if s.l.Enabled('I') {
+		output(s.l, 'I', sprintln(args))
+	}
+}
+
+func (s *SugaredLogger) Infow(msg string, keysAndValues ...interface{}) {
+	if s.l.Enabled('I') {
+		output(s.l.With(keysAndValues...), 'I', msg)
+	}
+}
+
+func (s *SugaredLogger) Warn(args ...interface{}) {
+	if s.l.Enabled('W') {
+		output(s.l, 'W', sprint(args))
+	}
+}
+
+func (s *SugaredLogger) Warnf(template string, args ...interface{}) {
+	if s.l.Enabled('W') {
+		output(s.l, 'W', fmt.Sprintf(template, args...))
+	}
+}
+
+func (s *SugaredLogger) Warnln(args ...interface{}) {
+	if s.l.Enabled('W') {
+		output(s.l, 'W', sprintln(args))
+	}
+}
+
+func (s *SugaredLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	if s.l.Enabled('W') {
+		output(s.l.With(keysAndValues...), 'W', msg)
+	}
+}
+
+func (s *SugaredLogger) Error(args ...interface{}) {
+	if s.l.Enabled('E') {
+		output(s.l, 'E', sprint(args))
+	}
+}
+
+func (s *SugaredLogger) Errorf(template string, args ...interface{}) {
+	if s.l.Enabled('E') {
+		output(s.l, 'E', fmt.Sprintf(template, args...))
+	}
+}
+
+func (s *SugaredLogger) Errorln(args ...interface{}) {
+	if s.l.Enabled('E') {
+		output(s.l, 'E', sprintln(args))
+	}
+}
+
+func (s *SugaredLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	if s.l.Enabled('E') {
+		output(s.l.With(keysAndValues...), 'E', msg)
+	}
+}
+
+func (s *SugaredLogger) DPanic(args ...interface{}) {
+	if s.l.Enabled('E') {
+		output(s.l, 'E', sprint(args))
+	}
+}
+
+func (s *SugaredLogger) DPanicf(template string, args ...interface{}) {
+	if s.l.Enabled('E') {
+		output(s.l, 'E', fmt.Sprintf(template, args...))
+	}
+}
+
+func (s *SugaredLogger) DPanicln(args ...interface{}) {
+	if s.l.Enabled('E') {
+		output(s.l, 'E', sprintln(args))
+	}
+}
+
+func (s *SugaredLogger) DPanicw(msg string, keysAndValues ...interface{}) {
+	if s.l.Enabled('E') {
+		output(s.l.With(keysAndValues...), 'E', msg)
+	}
+}
+
+func (s *SugaredLogger) Panic(args ...interface{}) {
+	output(s.l, 'P', sprint(args))
+}
+
+func (s *SugaredLogger) Panicf(template string, args ...interface{}) {
+	output(s.l, 'P', fmt.Sprintf(template, args...))
+}
+
+func (s *SugaredLogger) Panicln(args ...interface{}) {
+	output(s.l, 'P', sprintln(args))
+}
+
+func (s *SugaredLogger) Panicw(msg string, keysAndValues ...interface{}) {
+	output(s.l.With(keysAndValues...), 'P', msg)
+}
+
+func (s *SugaredLogger) Fatal(args ...interface{}) {
+	output(s.l, 'F', sprint(args))
+}
+
+func (s *SugaredLogger) Fatalf(template string, args ...interface{}) {
+	output(s.l, 'F', fmt.Sprintf(template, args...))
+}
+
+func (s *SugaredLogger) Fatalln(args ...interface{}) {
+	output(s.l, 'F', sprintln(args))
+}
+
+func (s *SugaredLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	output(s.l.With(keysAndValues...), 'F', msg)
+}
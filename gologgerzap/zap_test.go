@@ -0,0 +1,47 @@
+package gologgerzap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danielwiratman/gologger"
+	"github.com/danielwiratman/gologger/gologgertest"
+)
+
+func TestSugaredLogger(t *testing.T) {
+	sink := gologgertest.Attach(t, gologger.L)
+
+	sugar := New(gologger.L).Named("api").With("region", "eu")
+	sugar.Infow("request served", "path", "/", "status", 200)
+	sugar.Warnf("slow by %dms", 30)
+	sugar.DPanic("odd", 1)
+	sugar.Errorln("failed", 2)
+
+	entries := sink.Entries()
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4", len(entries))
+	}
+	want := []struct {
+		level   byte
+		message string
+	}{{'I', "request served"}, {'W', "slow by 30ms"}, {'E', "odd1"}, {'E', "failed 2"}}
+	for i, w := range want {
+		e := entries[i]
+		if e.Level != w.level || e.Message != w.message {
+			t.Errorf("entry %d is %c %q, want %c %q", i, e.Level, e.Message, w.level, w.message)
+		}
+		if e.Logger != "api" {
+			t.Errorf("entry %d has logger %q, want api", i, e.Logger)
+		}
+		if !strings.HasSuffix(e.Func, ".TestSugaredLogger") {
+			t.Errorf("entry %d has caller %q, want TestSugaredLogger", i, e.Func)
+		}
+	}
+	var keys []string
+	for _, f := range entries[0].Fields {
+		keys = append(keys, f.Key)
+	}
+	if got := strings.Join(keys, ","); got != "region,path,status" {
+		t.Errorf("fields are %s, want region,path,status", got)
+	}
+}
@@ -0,0 +1,33 @@
+package gologger
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// SetGoroutineID tags every entry with the ID of the goroutine logging it,
+// as the goroutine field, to follow one worker through interleaved output.
+// It costs a runtime.Stack call per entry.
+func (l *Log) SetGoroutineID(on bool) {
+	l.core().goroutineID.Store(on)
+}
+
+// WithTask returns a child logger tagging its entries with task=id, for work
+// handed between goroutines or followed across restarts of its worker.
+func (l *Log) WithTask(id interface{}) *Log {
+	return l.child([]Field{{"task", id}})
+}
+
+// goroutineID parses the ID from the header runtime.Stack writes, e.g.
+// "goroutine 18 [running]:". The runtime has no cheaper way to get it.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
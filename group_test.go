@@ -0,0 +1,55 @@
+package gologger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGroupConcurrentLines(t *testing.T) {
+	l, sink := newTestLog(t)
+	g := l.Group("report")
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				g.Line("line %d", i).With("worker", i)
+			}
+		}()
+	}
+	wg.Wait()
+	g.Emit()
+	g.Emit()
+	l.Flush()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.entries) != 1 {
+		t.Fatalf("%d entries, want 1", len(sink.entries))
+	}
+	entry := sink.entries[0]
+	if lines := strings.Split(entry.Message, "\n"); len(lines) != 81 || lines[0] != "report" {
+		t.Errorf("message of %d lines starting with %q, want the title and 80 lines", len(lines), lines[0])
+	}
+	if len(entry.Fields) != 80 {
+		t.Errorf("%d fields, want 80", len(entry.Fields))
+	}
+}
+
+func TestGroupLevel(t *testing.T) {
+	l, sink := newTestLog(t)
+	l.SetPriority(LOG_INFO)
+
+	l.Group("hidden").Level('D').Line("debug").Emit()
+	l.Group("shown").Level('W').Line("warning").Emit()
+	l.Flush()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.entries) != 1 || sink.entries[0].Level != 'W' || sink.entries[0].Message != "shown\nwarning" {
+		t.Errorf("got %+v, want only the warning", sink.entries)
+	}
+}
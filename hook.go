@@ -0,0 +1,95 @@
+package gologger
+
+import "slices"
+
+// Hook sees every entry the daemon handles, in the order hooks were added.
+// Like sinks, hooks are only ever called from the daemon goroutine.
+type Hook interface {
+	// Before runs ahead of the sinks and may modify entry, including the
+	// elements of its Fields, which are the entry's own. Returning false drops it.
+	Before(entry *Entry) bool
+
+	// After runs once every sink has had the entry, err joins the errors of the sinks that failed.
+	After(entry Entry, err error)
+}
+
+// AddHook registers h for entries logged through l and its children.
+func (l *Log) AddHook(h Hook) {
+	l = l.core()
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+
+	hooks := make([]Hook, 0, len(l.hooks)+1)
+	hooks = append(hooks, l.hooks...)
+	l.hooks = append(hooks, h)
+}
+
+// RemoveHook unregisters h, once it returns the daemon no longer calls it.
+func (l *Log) RemoveHook(h Hook) {
+	l = l.core()
+
+	l.sinksMu.Lock()
+	hooks := make([]Hook, 0, len(l.hooks))
+	for _, existing := range l.hooks {
+		if existing != h {
+			hooks = append(hooks, existing)
+		}
+	}
+	l.hooks = hooks
+	l.sinksMu.Unlock()
+
+	// wait for an entry going through the old snapshot
+	l.flush(false)
+}
+
+func (l *Log) currentHooks() []Hook {
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+	return l.hooks
+}
+
+// dispatch runs entries through the hooks and writes them to the sinks.
+func (l *Log) dispatch(entries []Entry) {
+	hooks := l.currentHooks()
+	enrichments := l.currentEnrichments()
+
+	kept := entries[:0]
+	for _, entry := range entries {
+		l.addMetadata(&entry, enrichments)
+		if len(hooks) > 0 {
+			var ok bool
+			entry, ok = runBeforeHooks(hooks, entry)
+			if !ok {
+				continue
+			}
+		}
+		kept = append(kept, entry)
+	}
+
+	errs := l.writeSinks(kept)
+
+	if len(hooks) == 0 {
+		return
+	}
+	for i, entry := range kept {
+		var err error
+		if errs != nil {
+			err = errs[i]
+		}
+		for _, h := range hooks {
+			h.After(entry, err)
+		}
+	}
+}
+
+// runBeforeHooks is separate so that entry only escapes to the heap when there are hooks.
+func runBeforeHooks(hooks []Hook, entry Entry) (Entry, bool) {
+	// the fields may be the logger's own, shared by every entry
+	entry.Fields = slices.Clone(entry.Fields)
+	for _, h := range hooks {
+		if !h.Before(&entry) {
+			return entry, false
+		}
+	}
+	return entry, true
+}
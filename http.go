@@ -0,0 +1,76 @@
+package gologger
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type levelPayload struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler reports (GET) and changes (PUT) Priority at runtime. PUT takes
+// either a JSON body like {"level":"debug"} or a level form/query parameter.
+func (l *Log) LevelHandler() http.Handler {
+	l = l.core()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var payload levelPayload
+			if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+				err := json.NewDecoder(r.Body).Decode(&payload)
+				if err != nil {
+					writeLevelError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+					return
+				}
+			} else {
+				payload.Level = r.FormValue("level")
+			}
+
+			priority, err := ParseLevel(payload.Level)
+			if err != nil {
+				writeLevelError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			l.readPriorityField()
+			old := Priority(l.priority.Swap(int32(priority)))
+			if old != priority {
+				l.WRN("Log level changed from %s to %s by %s", priorityName(old), priorityName(priority), r.RemoteAddr)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeLevelError(w, http.StatusMethodNotAllowed, "only GET and PUT are supported")
+			return
+		}
+
+		json.NewEncoder(w).Encode(levelPayload{Level: priorityName(l.CurrentPriority())})
+	})
+}
+
+func writeLevelError(w http.ResponseWriter, code int, msg string) {
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+func priorityName(p Priority) string {
+	switch {
+	case p >= LOG_TRACE:
+		return "trace"
+	case p >= LOG_DEBUG:
+		return "debug"
+	case p >= LOG_INFO:
+		return "info"
+	case p >= LOG_WARNING:
+		return "warning"
+	case p >= LOG_ERR:
+		return "error"
+	default:
+		return "fatal"
+	}
+}
@@ -0,0 +1,60 @@
+package gologger
+
+// SetInline makes every log call write its entry and flush the sinks before
+// it returns, with no daemon in between, for AWS Lambda functions and CLI
+// tools that get frozen or exit right after. Set it before logging anything
+// and the daemon goroutine is never started. Logging then takes as long as
+// the slowest sink, and sinks and hooks must not log themselves.
+func (l *Log) SetInline(inline bool) {
+	l = l.core()
+	if inline && !l.inline.Load() && l.started.Load() {
+		// what the daemon has queued goes first
+		l.Flush()
+	}
+	l.inline.Store(inline)
+}
+
+// Inline reports whether SetInline turned the daemon off.
+func (l *Log) Inline() bool {
+	return l.core().inline.Load()
+}
+
+// startDaemon starts the daemon on first use rather than on import, so
+// programs logging inline never have it.
+func (l *Log) startDaemon() {
+	if !l.started.Load() && l.started.CompareAndSwap(false, true) {
+		go l.daemon()
+	}
+}
+
+// send hands a control message to the daemon, or runs it right away inline.
+func (l *Log) send(m logMsg) {
+	if l.inline.Load() {
+		l.handleInline(m)
+		return
+	}
+	l.startDaemon()
+	l.logChan <- m
+}
+
+// handleInline does what the daemon does with m, in the caller's goroutine.
+func (l *Log) handleInline(m logMsg) {
+	l.writeMu.Lock()
+	defer l.writeMu.Unlock()
+
+	if !m.isControl() {
+		l.queued(m.entry.Level)
+		l.dispatch([]Entry{m.entry})
+		l.flushSinks(true)
+	}
+	if m.flushed != nil {
+		l.flushSinks(true)
+		if m.sync {
+			l.syncSinks()
+		}
+		close(m.flushed)
+	}
+	if m.ctrl != nil {
+		m.ctrl()
+	}
+}
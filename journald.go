@@ -0,0 +1,143 @@
+//go:build linux
+
+package gologger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const journaldSocket = "/run/systemd/journal/socket"
+
+// journaldSink speaks journald's native datagram protocol, so fields end up
+// indexed instead of flattened into the syslog message.
+type journaldSink struct {
+	identifier string
+	conn       *net.UnixConn
+	addr       *net.UnixAddr
+	buf        bytes.Buffer
+}
+
+// NewJournaldSink connects to the local journald. identifier becomes SYSLOG_IDENTIFIER,
+// the program name if empty, for entries of loggers without WithSyslogTag.
+// Fields are sent uppercased, e.g. request_id as REQUEST_ID.
+func NewJournaldSink(identifier string) (Sink, error) {
+	if _, err := os.Stat(journaldSocket); err != nil {
+		return nil, fmt.Errorf("journald is not available: %w", err)
+	}
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("creating journald socket: %w", err)
+	}
+	return &journaldSink{
+		identifier: identifier,
+		conn:       conn,
+		addr:       &net.UnixAddr{Name: journaldSocket, Net: "unixgram"},
+	}, nil
+}
+
+func (s *journaldSink) Write(entry Entry) error {
+	s.buf.Reset()
+
+	message := entry.Message
+	if entry.Logger != "" {
+		message = "[" + entry.Logger + "] " + message
+	}
+	appendJournalField(&s.buf, "MESSAGE", message)
+	appendJournalField(&s.buf, "PRIORITY", strconv.Itoa(int(levelSeverity(entry.Level))))
+	identifier := s.identifier
+	if entry.SyslogTag != "" {
+		identifier = entry.SyslogTag
+	}
+	appendJournalField(&s.buf, "SYSLOG_IDENTIFIER", identifier)
+	appendJournalField(&s.buf, "CODE_FUNC", entry.Func)
+	appendJournalField(&s.buf, "CODE_LINE", strconv.Itoa(entry.Line))
+	appendJournalField(&s.buf, "GOLOGGER_LEVEL", string(entry.Level))
+	if entry.Logger != "" {
+		appendJournalField(&s.buf, "GOLOGGER_NAME", entry.Logger)
+	}
+	for _, f := range entry.Fields {
+		name := journalFieldName(f.Key)
+		if name != "" {
+			appendJournalField(&s.buf, name, fmt.Sprint(f.Value))
+		}
+	}
+
+	_, _, err := s.conn.WriteMsgUnix(s.buf.Bytes(), nil, s.addr)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return err
+	}
+	return s.writeViaFile(s.buf.Bytes())
+}
+
+// writeViaFile passes entries too large for a datagram as a file descriptor, like sd_journal_send does.
+func (s *journaldSink) writeViaFile(payload []byte) error {
+	f, err := os.CreateTemp("/dev/shm", "gologger-journal-")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	os.Remove(f.Name())
+
+	_, err = f.Write(payload)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), s.addr)
+	return err
+}
+
+func (s *journaldSink) Close() error {
+	return s.conn.Close()
+}
+
+func appendJournalField(buf *bytes.Buffer, name, value string) {
+	if !strings.ContainsRune(value, '\n') {
+		buf.WriteString(name)
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	// multi-line values are sent as NAME\n<little endian uint64 length><value>\n
+	buf.WriteString(name)
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName converts key to what journald accepts: uppercase letters,
+// digits and underscores, not starting with an underscore, at most 64 characters.
+func journalFieldName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			name[i] = '_'
+		}
+	}
+	s := strings.TrimLeft(string(name), "_")
+	if s != "" && s[0] >= '0' && s[0] <= '9' {
+		s = "F_" + s
+	}
+	if len(s) > 64 {
+		s = s[:64]
+	}
+	return s
+}
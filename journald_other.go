@@ -0,0 +1,10 @@
+//go:build !linux
+
+package gologger
+
+import "errors"
+
+// NewJournaldSink is only available on Linux.
+func NewJournaldSink(identifier string) (Sink, error) {
+	return nil, errors.New("journald is only available on linux")
+}
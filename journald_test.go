@@ -0,0 +1,39 @@
+//go:build linux
+
+package gologger
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJournaldIdentifier(t *testing.T) {
+	addr := &net.UnixAddr{Name: filepath.Join(t.TempDir(), "socket"), Net: "unixgram"}
+	journal, err := net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer journal.Close()
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &journaldSink{identifier: "prog", conn: conn, addr: addr}
+	defer s.Close()
+
+	buf := make([]byte, 4096)
+	for tag, want := range map[string]string{"": "prog", "web": "web"} {
+		if err := s.Write(Entry{Level: 'I', Message: "m", SyslogTag: tag}); err != nil {
+			t.Fatal(err)
+		}
+		n, err := journal.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(buf[:n]), "\nSYSLOG_IDENTIFIER="+want+"\n") {
+			t.Errorf("tag %q sent as %q, want SYSLOG_IDENTIFIER=%s", tag, buf[:n], want)
+		}
+	}
+}
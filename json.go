@@ -0,0 +1,99 @@
+package gologger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// JSONEncoder writes one JSON object per line:
+// {"ts":"...","level":"info","logger":"db","caller":"main.main:12","msg":"...","msg_id":"...","key":"value","stacktrace":"..."}
+type JSONEncoder struct{}
+
+func (JSONEncoder) Encode(buf []byte, entry Entry) []byte {
+	buf = append(buf, `{"ts":"`...)
+	buf = entry.Time.AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, '"')
+	buf = append(buf, `,"level":`...)
+	buf = strconv.AppendQuote(buf, levelName(entry.Level))
+	if entry.Logger != "" {
+		buf = append(buf, `,"logger":`...)
+		buf = appendJSONString(buf, entry.Logger)
+	}
+	if entry.Func != "" {
+		buf = append(buf, `,"caller":`...)
+		buf = appendJSONLocation(buf, entry.Func, entry.Line)
+	}
+	if entry.File != "" {
+		buf = append(buf, `,"file":`...)
+		buf = appendJSONLocation(buf, entry.File, entry.Line)
+	}
+	buf = append(buf, `,"msg":`...)
+	buf = appendJSONString(buf, entry.Message)
+	if entry.MessageID != "" {
+		buf = append(buf, `,"msg_id":`...)
+		buf = appendJSONString(buf, entry.MessageID)
+	}
+
+	for _, f := range entry.Fields {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, f.Key)
+		buf = append(buf, ':')
+		buf = appendJSONValue(buf, f.Value)
+	}
+
+	if entry.Stack != "" {
+		buf = append(buf, `,"stacktrace":`...)
+		buf = appendJSONString(buf, entry.Stack)
+	}
+	return append(buf, "}\n"...)
+}
+
+// appendJSONLocation writes "name:line" without concatenating the string first.
+func appendJSONLocation(buf []byte, name string, line int) []byte {
+	buf = appendJSONString(buf, name)
+	buf = append(buf[:len(buf)-1], ':')
+	buf = strconv.AppendInt(buf, int64(line), 10)
+	return append(buf, '"')
+}
+
+func appendJSONString(buf []byte, s string) []byte {
+	// plain ASCII needs no escaping, spare the allocation of json.Marshal
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c >= 0x80 || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			b, _ := json.Marshal(s)
+			return append(buf, b...)
+		}
+	}
+	buf = append(buf, '"')
+	buf = append(buf, s...)
+	return append(buf, '"')
+}
+
+func appendJSONValue(buf []byte, v interface{}) []byte {
+	switch t := v.(type) {
+	case string:
+		return appendJSONString(buf, t)
+	case int:
+		return strconv.AppendInt(buf, int64(t), 10)
+	case int64:
+		return strconv.AppendInt(buf, t, 10)
+	case bool:
+		return strconv.AppendBool(buf, t)
+	case json.Marshaler:
+	case error:
+		return appendJSONString(buf, t.Error())
+	case time.Duration:
+		return appendJSONString(buf, t.String())
+	case fmt.Stringer:
+		return appendJSONString(buf, t.String())
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return appendJSONString(buf, fmt.Sprint(v))
+	}
+	return append(buf, b...)
+}
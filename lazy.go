@@ -0,0 +1,46 @@
+package gologger
+
+import "fmt"
+
+// DBGf is DBG with the message built by fn, which is only called once the
+// entry has passed the level checks, so expensive dumps cost nothing when debug is off.
+func (l *Log) DBGf(fn func() string) {
+	l.logLazy(LOG_DEBUG, 'D', fn)
+}
+
+// TRCf is DBGf at trace level.
+func (l *Log) TRCf(fn func() string) {
+	l.logLazy(LOG_TRACE, 'T', fn)
+}
+
+// INFf is DBGf at info level.
+func (l *Log) INFf(fn func() string) {
+	l.logLazy(LOG_INFO, 'I', fn)
+}
+
+// WRNf is DBGf at warning level.
+func (l *Log) WRNf(fn func() string) {
+	l.logLazy(LOG_WARNING, 'W', fn)
+}
+
+func (l *Log) logLazy(priority Priority, level byte, fn func() string) {
+	if !l.enabled(priority) {
+		return
+	}
+	pc, line := l.caller(2)
+
+	l.outputLazy(pc, Entry{Level: level, Line: line}, fn)
+}
+
+// Lazy defers computing a format argument until the message is formatted,
+// e.g. l.DBG("state %v", gologger.Lazy(dumpState)). DBG and friends skip the
+// formatting when the level is off. As a field value fn runs in the daemon.
+type Lazy func() interface{}
+
+func (f Lazy) String() string {
+	return fmt.Sprint(f())
+}
+
+func (f Lazy) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, fmt.FormatString(s, verb), f())
+}
@@ -0,0 +1,344 @@
+package gologger
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+type levelRule struct {
+	pattern  string // without the trailing "*"
+	prefix   bool
+	priority Priority
+}
+
+func (r levelRule) matches(s string) bool {
+	if r.prefix {
+		return strings.HasPrefix(s, r.pattern)
+	}
+	return s == r.pattern
+}
+
+// levelRules is replaced as a whole on every change, so readers never lock.
+type levelRules struct {
+	rules []levelRule
+	max   Priority
+}
+
+// Level describes a level registered with RegisterLevel.
+type Level struct {
+	Tag      byte     // shown in text output and used in level masks, e.g. 'A'
+	Name     string   // lowercase name for structured encoders, e.g. "audit"
+	Priority Priority // orders it among the built-in levels, and its syslog severity
+
+	// Logged regardless of Priority, overrides, sampling, rate limits and
+	// DropNewest/DropOldest, e.g. for audit or security events.
+	Always bool
+}
+
+// customLevels is indexed by tag, nil for tags that aren't registered.
+var customLevels [256]atomic.Pointer[Level]
+
+const builtinLevelTags = "FPEWIDTA"
+
+// RegisterLevel adds a level to log at with Logf, e.g.
+// Level{Tag: 'N', Name: "notice", Priority: LOG_NOTICE}. Registering a tag
+// again replaces it, the built-in tags can't be.
+func RegisterLevel(level Level) error {
+	if level.Tag <= ' ' || level.Tag > '~' || strings.IndexByte(builtinLevelTags, level.Tag) >= 0 {
+		return fmt.Errorf("invalid level tag %q, it must be a printable character other than %s", level.Tag, builtinLevelTags)
+	}
+	if level.Name == "" {
+		return fmt.Errorf("level %q needs a name", level.Tag)
+	}
+	level.Name = strings.ToLower(level.Name)
+	customLevels[level.Tag].Store(&level)
+	return nil
+}
+
+func customLevel(tag byte) *Level {
+	return customLevels[tag].Load()
+}
+
+// alwaysLogged reports whether level bypasses filtering, see Level.Always.
+func alwaysLogged(level byte) bool {
+	c := customLevel(level)
+	return c != nil && c.Always
+}
+
+// StandardLevel maps a registered level to the built-in tag of the same
+// priority, for sinks that know only those. Other tags are returned unchanged.
+func StandardLevel(level byte) byte {
+	if customLevel(level) == nil {
+		return level
+	}
+	return priorityLevel(levelPriority(level))
+}
+
+// LOG_TRACE is below every syslog severity, enabling TRC as well as DBG.
+// Syslog and other sinks with a fixed set of severities get it as debug.
+const LOG_TRACE = LOG_DEBUG + 1
+
+// ParseLevel accepts level names like "trace", "debug", "info", "warn", "error", "fatal" or their tags "T", "D", "I", "W", "E", "F".
+func ParseLevel(s string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace", "trc", "t":
+		return LOG_TRACE, nil
+	case "debug", "dbg", "d":
+		return LOG_DEBUG, nil
+	case "info", "inf", "i":
+		return LOG_INFO, nil
+	case "warn", "warning", "wrn", "w":
+		return LOG_WARNING, nil
+	case "error", "err", "e":
+		return LOG_ERR, nil
+	case "fatal", "ftl", "panic", "crit", "f", "p":
+		return LOG_CRIT, nil
+	default:
+		for i := range customLevels {
+			if c := customLevels[i].Load(); c != nil && (c.Name == strings.ToLower(strings.TrimSpace(s)) || s == string(c.Tag)) {
+				return c.Priority, nil
+			}
+		}
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+}
+
+// levelName is the lowercase name of a level tag, as used by structured encoders.
+func levelName(level byte) string {
+	switch level {
+	case 'P':
+		return "panic"
+	case 'F':
+		return "fatal"
+	case 'E':
+		return "error"
+	case 'W':
+		return "warning"
+	case 'I':
+		return "info"
+	case 'D':
+		return "debug"
+	case 'T':
+		return "trace"
+	default:
+		if c := customLevel(level); c != nil {
+			return c.Name
+		}
+		return string(level)
+	}
+}
+
+// levelTag is the tag of a level name of levelName, 0 if unknown.
+func levelTag(name string) byte {
+	for _, tag := range []byte("PFEWIDT") {
+		if levelName(tag) == name {
+			return tag
+		}
+	}
+	for i := range customLevels {
+		if c := customLevels[i].Load(); c != nil && c.Name == name {
+			return c.Tag
+		}
+	}
+	return 0
+}
+
+// SetPriority sets the least severe priority logged, LOG_DEBUG by default.
+// Safe to call while logging, e.g. from a signal handler or LevelHandler.
+func (l *Log) SetPriority(p Priority) {
+	core := l.core()
+	core.readPriorityField()
+	core.priority.Store(int32(p))
+}
+
+// CurrentPriority returns the least severe priority logged, see SetPriority.
+func (l *Log) CurrentPriority() Priority {
+	return l.core().loadPriority()
+}
+
+// loadPriority returns the priority of the core logger l.
+func (l *Log) loadPriority() Priority {
+	if !l.priorityRead.Load() {
+		l.readPriorityField()
+	}
+	return Priority(l.priority.Load())
+}
+
+// readPriorityField takes the deprecated Priority field, once.
+func (l *Log) readPriorityField() {
+	l.priorityOnce.Do(func() {
+		l.priority.Store(int32(l.Priority))
+		l.priorityRead.Store(true)
+	})
+}
+
+// SetLevel overrides Priority for a named logger or a caller package.
+// A trailing "*" matches by prefix, e.g. "net/*" or "db.*"; the longest matching pattern wins
+// and rules matching the logger name take precedence over those matching the package.
+func (l *Log) SetLevel(pattern string, priority Priority) {
+	l = l.core()
+	l.levelsMu.Lock()
+	defer l.levelsMu.Unlock()
+
+	rule := levelRule{pattern: pattern, priority: priority}
+	if strings.HasSuffix(pattern, "*") {
+		rule.pattern = strings.TrimSuffix(pattern, "*")
+		rule.prefix = true
+	}
+
+	var rules []levelRule
+	if current := l.levels.Load(); current != nil {
+		for _, r := range current.rules {
+			if r.pattern != rule.pattern || r.prefix != rule.prefix {
+				rules = append(rules, r)
+			}
+		}
+	}
+	l.storeLevels(append(rules, rule))
+}
+
+// UnsetLevel removes an override added by SetLevel.
+func (l *Log) UnsetLevel(pattern string) {
+	l = l.core()
+	l.levelsMu.Lock()
+	defer l.levelsMu.Unlock()
+
+	current := l.levels.Load()
+	if current == nil {
+		return
+	}
+	prefix := strings.HasSuffix(pattern, "*")
+	pattern = strings.TrimSuffix(pattern, "*")
+
+	var rules []levelRule
+	for _, r := range current.rules {
+		if r.pattern != pattern || r.prefix != prefix {
+			rules = append(rules, r)
+		}
+	}
+	l.storeLevels(rules)
+}
+
+// SetLevels applies a comma separated list of overrides, e.g. "net/*=debug,db=warn".
+func (l *Log) SetLevels(spec string) error {
+	overrides, err := parseLevelOverrides(spec)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range overrides {
+		l.SetLevel(o.pattern, o.priority)
+	}
+	return nil
+}
+
+type levelOverride struct {
+	pattern  string
+	priority Priority
+}
+
+func parseLevelOverrides(spec string) ([]levelOverride, error) {
+	var overrides []levelOverride
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		pattern, level, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("invalid level override %q, expected pattern=level", part)
+		}
+		priority, err := ParseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, levelOverride{strings.TrimSpace(pattern), priority})
+	}
+	return overrides, nil
+}
+
+func (l *Log) storeLevels(rules []levelRule) {
+	if len(rules) == 0 {
+		l.levels.Store(nil)
+		return
+	}
+	lr := &levelRules{rules: rules}
+	for _, r := range rules {
+		if r.priority > lr.max {
+			lr.max = r.priority
+		}
+	}
+	l.levels.Store(lr)
+}
+
+// Enabled reports whether messages at level ('E', 'W', 'I', 'D', 'T', ...) pass Priority or
+// any of the overrides, for callers that want to skip expensive work.
+func (l *Log) Enabled(level byte) bool {
+	return l.enabled(levelPriority(level))
+}
+
+// enabled is the cheap check done before formatting: could any logger log at p?
+func (l *Log) enabled(p Priority) bool {
+	core := l.core()
+	if p <= core.loadPriority() {
+		return true
+	}
+	if lr := core.levels.Load(); lr != nil && p <= lr.max {
+		return true
+	}
+	if r := core.recent.Load(); r != nil && p <= r.priority {
+		return true
+	}
+	a := core.autoDebug.Load()
+	return a != nil && a.Context > 0 && p <= a.Priority
+}
+
+// enabledAt resolves the overrides for this logger's name and the package of pc.
+func (l *Log) enabledAt(pc uintptr, p Priority) bool {
+	core := l.core()
+	lr := core.levels.Load()
+	if lr == nil {
+		return p <= core.loadPriority()
+	}
+
+	// a rule for the logger's name beats one for the package it is called from
+	priority, ok := lr.resolve(l.name)
+	if !ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			priority, ok = lr.resolve(funcPackage(fn.Name()))
+		}
+	}
+	if !ok {
+		priority = core.loadPriority()
+	}
+	return p <= priority
+}
+
+// resolve returns the priority of the longest rule matching s.
+func (lr *levelRules) resolve(s string) (Priority, bool) {
+	if s == "" {
+		return 0, false
+	}
+	best := -1
+	var priority Priority
+	for _, r := range lr.rules {
+		if len(r.pattern) > best && r.matches(s) {
+			best = len(r.pattern)
+			priority = r.priority
+		}
+	}
+	return priority, best >= 0
+}
+
+// funcPackage extracts the import path from a runtime function name,
+// e.g. "github.com/a/b.(*T).F" -> "github.com/a/b".
+func funcPackage(name string) string {
+	slash := strings.LastIndexByte(name, '/')
+	dot := strings.IndexByte(name[slash+1:], '.')
+	if dot < 0 {
+		return name
+	}
+	return name[:slash+1+dot]
+}
@@ -0,0 +1,15 @@
+package gologger
+
+import "testing"
+
+func TestDeprecatedPriorityField(t *testing.T) {
+	l, _ := newTestLog(t)
+	l.Priority = LOG_WARNING
+	if l.Enabled('I') || !l.Enabled('W') {
+		t.Error("Priority field not taken on first use")
+	}
+	l.SetPriority(LOG_DEBUG)
+	if got := l.CurrentPriority(); got != LOG_DEBUG {
+		t.Errorf("CurrentPriority() = %v after SetPriority, want debug", got)
+	}
+}
@@ -0,0 +1,58 @@
+package gologger
+
+import (
+	"strconv"
+	"time"
+)
+
+// LogfmtEncoder writes logfmt lines, as Grafana Agent, Heroku style routers and
+// similar tools expect:
+// ts=2006-01-02T15:04:05.999Z level=info logger=db caller=main.main:12 msg="..." key=value
+type LogfmtEncoder struct{}
+
+func (LogfmtEncoder) Encode(buf []byte, entry Entry) []byte {
+	buf = append(buf, "ts="...)
+	buf = entry.Time.AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, " level="...)
+	buf = append(buf, levelName(entry.Level)...)
+	if entry.Logger != "" {
+		buf = append(buf, " logger="...)
+		buf = appendFieldString(buf, entry.Logger)
+	}
+	if entry.Func != "" {
+		buf = append(buf, " caller="...)
+		buf = appendLogfmtLocation(buf, entry.Func, entry.Line)
+	}
+	if entry.File != "" {
+		buf = append(buf, " file="...)
+		buf = appendLogfmtLocation(buf, entry.File, entry.Line)
+	}
+	buf = append(buf, " msg="...)
+	buf = appendFieldString(buf, entry.Message)
+	if entry.MessageID != "" {
+		buf = append(buf, " msg_id="...)
+		buf = appendFieldString(buf, entry.MessageID)
+	}
+	buf = appendFields(buf, entry.Fields)
+	if entry.Stack != "" {
+		buf = append(buf, " stacktrace="...)
+		buf = strconv.AppendQuote(buf, entry.Stack)
+	}
+	return append(buf, '\n')
+}
+
+func appendLogfmtLocation(buf []byte, name string, line int) []byte {
+	start := len(buf)
+	buf = append(buf, name...)
+	buf = append(buf, ':')
+	buf = strconv.AppendInt(buf, int64(line), 10)
+
+	// names rarely need quoting, only then pay for the string
+	for _, c := range buf[start:] {
+		if c == ' ' || c == '"' || c == '=' {
+			location := string(buf[start:])
+			return strconv.AppendQuote(buf[:start], location)
+		}
+	}
+	return buf
+}
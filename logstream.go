@@ -0,0 +1,168 @@
+package gologger
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HTTPLogStream serves the entries kept by KeepRecent and those logged from
+// then on, for looking into a service without a shell on its host. Browsers
+// get a page following the log, EventSource clients (Accept:
+// text/event-stream) a Server-Sent Event per entry, everyone else the recent
+// entries as JSON lines. The query narrows them down: level=warn for that
+// severity and more, filter= an expression of ParseFilter, n=100 for at most
+// that many recent entries. Mount it behind authentication, logs tend to hold
+// what outsiders shouldn't see.
+func (l *Log) HTTPLogStream() http.Handler {
+	l = l.core()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		filter, err := logStreamFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		accept := r.Header.Get("Accept")
+		switch {
+		case strings.Contains(accept, "text/event-stream"):
+			l.streamEvents(w, r, filter)
+		case strings.Contains(accept, "text/html"):
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(logStreamPage))
+		default:
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			var buf []byte
+			for _, entry := range l.recentEntries(r, filter) {
+				buf = JSONEncoder{}.Encode(buf, entry)
+			}
+			w.Write(buf)
+		}
+	})
+}
+
+// logStreamFilter makes a filter of the level and filter parameters, nil for neither.
+func logStreamFilter(r *http.Request) (Filter, error) {
+	var filters []Filter
+	if level := r.FormValue("level"); level != "" {
+		priority, err := ParseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, func(e Entry) bool { return levelPriority(e.Level) <= priority })
+	}
+	if expr := r.FormValue("filter"); expr != "" {
+		f, err := ParseFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+
+	switch len(filters) {
+	case 0:
+		return nil, nil
+	case 1:
+		return filters[0], nil
+	default:
+		return func(e Entry) bool { return filters[0](e) && filters[1](e) }, nil
+	}
+}
+
+// recentEntries returns the last n entries kept by KeepRecent that filter keeps,
+// redacted.
+func (l *Log) recentEntries(r *http.Request, filter Filter) []Entry {
+	buffer := l.recent.Load()
+	if buffer == nil {
+		return nil
+	}
+	var entries []Entry
+	for _, entry := range l.redactRecent(buffer.snapshot()) {
+		if filter == nil || filter(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if n, err := strconv.Atoi(r.FormValue("n")); err == nil && n >= 0 && n < len(entries) {
+		entries = entries[len(entries)-n:]
+	}
+	return entries
+}
+
+// streamEvents sends the recent, then every new entry, as JSON in Server-Sent
+// Events until the client goes away.
+func (l *Log) streamEvents(w http.ResponseWriter, r *http.Request, filter Filter) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	// subscribe first, an entry logged meanwhile may then come twice but isn't lost
+	live, cancel := l.Subscribe(filter)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	var buf []byte
+	send := func(entry Entry) bool {
+		buf = append(buf[:0], "data: "...)
+		buf = JSONEncoder{}.Encode(buf, entry)
+		buf = append(bytes.TrimSuffix(buf, []byte("\n")), "\n\n"...)
+		_, err := w.Write(buf)
+		flusher.Flush()
+		return err == nil
+	}
+
+	for _, entry := range l.recentEntries(r, filter) {
+		if !send(entry) {
+			return
+		}
+	}
+	flusher.Flush()
+	for {
+		select {
+		case entry := <-live:
+			if !send(entry) {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// logStreamPage follows the log from the handler it is served by, keeping the query.
+const logStreamPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Log</title>
+<style>
+body { margin: 0; font: 13px monospace; background: #111; color: #ddd; }
+div { padding: 1px 8px; white-space: pre-wrap; }
+.error, .fatal, .panic { color: #f66; } .warning { color: #fc3; } .debug, .trace { color: #888; }
+</style>
+</head>
+<body>
+<script>
+const source = new EventSource(location.href);
+source.onmessage = (event) => {
+	const e = JSON.parse(event.data);
+	const fields = Object.entries(e).filter(([k]) => !["ts", "level", "logger", "caller", "file", "msg", "msg_id", "stacktrace"].includes(k));
+	const line = document.createElement("div");
+	line.className = e.level;
+	line.textContent = [e.ts, e.level.toUpperCase(), e.caller || "", e.logger ? "[" + e.logger + "]" : "", e.msg,
+		...fields.map(([k, v]) => k + "=" + JSON.stringify(v))].filter(Boolean).join(" ") + (e.stacktrace ? "\n" + e.stacktrace : "");
+	const atBottom = innerHeight + scrollY >= document.body.scrollHeight - 4;
+	document.body.appendChild(line);
+	if (atBottom) scrollTo(0, document.body.scrollHeight);
+};
+</script>
+</body>
+</html>
+`
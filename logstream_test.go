@@ -0,0 +1,27 @@
+package gologger
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecentEntriesRedacted(t *testing.T) {
+	l, _ := newTestLog(t)
+	l.KeepRecent(10, LOG_DEBUG)
+	l.AddHook(NewRedactor())
+	l.With("password", "hunter2").INF("login with token Bearer abc.def.ghi")
+
+	entries := l.recentEntries(httptest.NewRequest("GET", "/", nil), nil)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if strings.Contains(entries[0].Message, "abc.def.ghi") {
+		t.Errorf("message %q not redacted", entries[0].Message)
+	}
+	for _, f := range entries[0].Fields {
+		if f.Value == "hunter2" {
+			t.Errorf("field %s not redacted", f.Key)
+		}
+	}
+}
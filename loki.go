@@ -0,0 +1,138 @@
+package gologger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LokiOptions configures NewLokiSink, the zero value works.
+type LokiOptions struct {
+	// Stream labels, job set to the program name and host to the hostname if nil.
+	Labels map[string]string
+
+	// Entries get a label with this name holding the level, e.g. level="error".
+	// "level" if empty, "-" to leave it out.
+	LevelLabel string
+
+	Encoder Encoder // renders the log line, JSONEncoder if nil
+
+	BearerToken        string
+	Username, Password string // basic auth, e.g. for Grafana Cloud
+	TenantID           string // sent as X-Scope-OrgID for multi-tenant Loki
+
+	Client *http.Client // one with a 30s timeout if nil
+	Batch  BatchOptions
+}
+
+type lokiSink struct {
+	*batcher
+
+	url  string
+	opts LokiOptions
+	buf  []byte
+}
+
+// NewLokiSink returns a sink pushing batches of entries to the Loki at pushURL,
+// e.g. "http://loki:3100". The push path is added unless pushURL already ends with it.
+func NewLokiSink(pushURL string, opts *LokiOptions) (Sink, error) {
+	s := &lokiSink{url: pushURL}
+	if opts != nil {
+		s.opts = *opts
+	}
+
+	if !strings.HasSuffix(s.url, "/loki/api/v1/push") {
+		s.url = strings.TrimSuffix(s.url, "/") + "/loki/api/v1/push"
+	}
+	if s.opts.Labels == nil {
+		hostname, _ := os.Hostname()
+		s.opts.Labels = map[string]string{"job": filepath.Base(os.Args[0]), "host": hostname}
+	}
+	if s.opts.LevelLabel == "" {
+		s.opts.LevelLabel = "level"
+	}
+	if s.opts.Encoder == nil {
+		s.opts.Encoder = JSONEncoder{}
+	}
+	if s.opts.Client == nil {
+		s.opts.Client = defaultHTTPClient
+	}
+
+	_, err := url.Parse(s.url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Loki URL: %w", err)
+	}
+
+	s.batcher = newBatcher(s.opts.Batch, s.push)
+	return s, nil
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (s *lokiSink) push(entries []Entry) error {
+	streams := map[byte]*lokiStream{}
+	var order []byte
+	for _, entry := range entries {
+		key := entry.Level
+		if s.opts.LevelLabel == "-" {
+			key = 0
+		}
+		stream, ok := streams[key]
+		if !ok {
+			labels := make(map[string]string, len(s.opts.Labels)+1)
+			for k, v := range s.opts.Labels {
+				labels[k] = v
+			}
+			if key != 0 {
+				labels[s.opts.LevelLabel] = levelName(entry.Level)
+			}
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+			order = append(order, key)
+		}
+
+		s.buf = s.opts.Encoder.Encode(s.buf[:0], entry)
+		line := strings.TrimSuffix(string(s.buf), "\n")
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(entry.Time.UnixNano(), 10), line})
+	}
+
+	body := struct {
+		Streams []*lokiStream `json:"streams"`
+	}{}
+	for _, key := range order {
+		body.Streams = append(body.Streams, streams[key])
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return permanentError{err}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.opts.BearerToken)
+	} else if s.opts.Username != "" {
+		req.SetBasicAuth(s.opts.Username, s.opts.Password)
+	}
+	if s.opts.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", s.opts.TenantID)
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	return checkHTTPResponse(resp)
+}
@@ -1,196 +1,765 @@
 package gologger
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
-	"log/syslog"
 	"os"
-	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+type logMsg struct {
+	entry Entry
+
+	// non-nil for flush markers, closed by the daemon once everything queued before it is written
+	flushed chan struct{}
+	sync    bool
+
+	// run by the daemon instead of writing an entry, for state only the daemon may touch
+	ctrl func()
+
+	// when it was queued, for merging shards back in order
+	order uint64
+}
+
+func (m logMsg) isControl() bool {
+	return m.flushed != nil || m.ctrl != nil
+}
+
 type Log struct {
-	logChan      chan string
-	syslogWriter *syslog.Writer
-	fileWriter   *os.File
+	logChan     chan logMsg
+	urgent      chan logMsg                   // the priority lane, see enqueue
+	shards      atomic.Pointer[[]chan logMsg] // nil unless SetShards was called, see shard.go
+	wake        chan struct{}
+	started     atomic.Bool // the daemon, see startDaemon
+	inline      atomic.Bool // see SetInline
+	writeMu     sync.Mutex  // held by whoever writes to the sinks, the daemon or an inline log call
+	stopped     atomic.Bool // by Shutdown, entries logged since are discarded
+	recent      atomic.Pointer[recentBuffer]
+	autoDebug   atomic.Pointer[autoDebugState] // see SetAutoDebug
+	filter      atomic.Pointer[Filter]
+	goroutineID atomic.Bool   // see SetGoroutineID
+	timerLevel  atomic.Uint32 // see SetTimerLevel
+	dumpLimit   atomic.Int64  // see SetDumpLimit
+	callSites   sync.Map      // of Once and Every, pc to *atomic.Int64
+	cooldowns   sync.Map      // of Cooled, key to *cooldown
+	stdoutSink  Sink
+	stderrSink  Sink
+	syslogSink  Sink
+	fileSink    Sink
+	counters    *counters
+
+	catalog   atomic.Pointer[localizedCatalog] // see SetMessageCatalog
+	clock     atomic.Pointer[Clock]            // see SetClock
+	monotonic atomic.Bool                      // see SetMonotonic
+	machine   atomic.Bool                      // see SetMode
+	startTime time.Time                        // with its monotonic reading, for SetMonotonic
+
+	shutdownMu sync.Mutex
+	onShutdown []func()
+
+	scheduleMu   sync.Mutex
+	stopSchedule func() // of the goroutine following SetLevelSchedule
+
+	archiveMu sync.Mutex     // held by the upload of LogfileArchive
+	archiving sync.WaitGroup // of the uploads, waited for by Shutdown
+
+	budgetMu    sync.Mutex
+	budgets     atomic.Pointer[budgets] // see SetBudget
+	stopBudgets func()                  // of the goroutine started by watchBudgets
+
+	schemaMu    sync.Mutex
+	schemas     atomic.Pointer[map[string]*Schema] // see SetSchema
+	schemaCheck atomic.Int32                       // see SetSchemaCheck
+
+	sinksMu sync.Mutex
+	sinks   []Sink
+	onError func(err error, entry Entry)
+	hooks   []Hook
+
+	extractors  []func(ctx context.Context) []Field
+	enrichments []*enrichment // see Enrich
+
+	configMu   sync.Mutex
+	configured configured // see Apply
+
+	root   *Log
+	name   string
+	fields []Field
+	every  int64    // log every nth entry of a call site, -1 only the first, see Every
+	tags   []string // see Tagged
+
+	cooled *cooledKey // see Cooled
+
+	syslogTag string // see WithSyslogTag
+
+	// only touched by the daemon
+	seq           uint64 // of the last entry written
+	selected      []Entry
+	selectedIndex []int
+	orderKeys     []orderKey
+
+	callerSkip  int
+	synchronous bool // see Sync
+
+	samplingMu sync.Mutex
+	sampling   atomic.Pointer[samplers]
+
+	rateMu          sync.Mutex
+	rateBuckets     map[uintptr]*callerBucket
+	rateLastSummary time.Time
 
 	SyslogTag     string
-	Priority      syslog.Priority
+	priority      atomic.Int32 // see SetPriority
 	SendToStdout  bool
 	SendToSyslog  bool
 	SendToLogfile bool
 	CloseDelay    time.Duration
+
+	// Deprecated: use SetPriority, which is safe while logging. Priority is
+	// read once, when the logger is first used; setting it later does nothing.
+	Priority     Priority
+	priorityRead atomic.Bool
+	priorityOnce sync.Once
+
+	// Write F/E/W to stderr and I/D to stdout, unless StdoutLevels or StderrLevels say otherwise.
+	SendToStderr bool
+
+	// Levels written to each built-in sink, e.g. []byte{'F', 'E', 'W'}. All if empty.
+	StdoutLevels  []byte
+	StderrLevels  []byte
+	SyslogLevels  []byte
+	LogfileLevels []byte
+
+	SyslogNetwork   string // "" for the local daemon, otherwise "udp", "tcp" or "tls"
+	SyslogAddr      string // host:port of the remote syslog server
+	SyslogTLSConfig *tls.Config
+	SyslogFacility  Priority // LOG_USER, LOG_DAEMON, LOG_LOCAL0..7, ...
+	SyslogEncoder   Encoder  // renders the messages, e.g. CEFEncoder for a SIEM. gologger's text format if nil
+
+	// SyslogStructured sends RFC 5424 messages with the entry's time, its
+	// logger name as MSGID and its fields as structured data under SyslogSDID,
+	// rather than everything in the message text. Also to the local daemon.
+	SyslogStructured bool
+	SyslogSDID       string // "fields@32473" if empty; use your own enterprise number
+
+	// Entries held while syslog is unreachable, redialed with backoff. The
+	// oldest are dropped beyond it. 1000 if zero, negative for none.
+	SyslogBufferSize int
+
+	levelsMu sync.Mutex
+	levels   atomic.Pointer[levelRules]
+
+	LogfileDir     string      // created if missing, the working directory if empty
+	LogfileName    string      // template with {program}, {date}, {pid} and {hostname} placeholders, and {level} or {logger} for a file each
+	LogfileLink    string      // kept pointing at the current logfile, e.g. "myapp.log" for tail -F, with {level} or {logger} for split files
+	LogfileMode    os.FileMode // permissions of new logfiles
+	LogfileDirMode os.FileMode // permissions of a created LogfileDir
+
+	LogfileBufferSize int                // 0 writes every message straight to the file
+	LogfileLock       bool               // lock the file around every write, for several processes sharing it. No buffering then
+	LogfileSync       SyncPolicy         // when to fsync the logfile, never by default
+	LogfileEncryption *LogfileEncryption // encrypts the logfile, not with LogfileLock
+	LogfileChecksums  bool               // keep a manifest with the SHA-256 of each logfile, see VerifyLogfile. Not with LogfileLock
+	LogfileMinFree    int64              // bytes free on its file system below which the logfile keeps only W and worse, 0 to not check
+	LogfileArchive    *LogfileArchive    // uploads logfiles rotation moved on from, e.g. to S3
+	FlushInterval     time.Duration      // how long buffered sinks may hold on to written messages
+
+	StackTraceLevel Priority // attach a stack trace to entries at this level or more severe, e.g. LOG_ERR
+	StackTraceDepth int      // maximum number of frames in a stack trace
+
+	CallerRateLimit          float64       // messages per second allowed from a single call site, 0 for no limit
+	CallerRateBurst          int           // messages a call site may log at once before the rate applies
+	RateLimitSummaryInterval time.Duration // how often suppressed counts are reported
+
+	overflowPolicy atomic.Int32 // see SetOverflowPolicy
+	delivery       atomic.Int32 // see SetDelivery
+
+	// Gets the entries a sink failed to write, along with the error. Stderr by default, nil to drop them.
+	FallbackSink Sink
+
+	// Metadata prepended to the fields of every entry, to tell apart and order merged logs of replicas
+	SeqField   bool   // seq, see Entry.Seq
+	HostField  bool   // host, the hostname
+	PIDField   bool   // pid
+	AppVersion string // version, left out if empty
+
+	disableCaller atomic.Bool  // see SetDisableCaller
+	callerPath    atomic.Int32 // see SetCallerPath
+
+	// Timestamps on stdout, stderr and in the logfile: a time layout, TimeEpochMillis
+	// or TimeNone. DefaultTimeFormat, the time of day, if empty.
+	TimeFormat string
+	TimeUTC    bool
+
+	maxMessageSize atomic.Int64 // see SetMaxMessageSize
+	truncateHash   atomic.Bool
+	sanitize       atomic.Int32 // see SetSanitize
+
+	Multiline       Multiline // how stdout, stderr and the logfile render multi-line messages
+	SyslogMultiline Multiline // the same for syslog, whose receivers often take only one line
+
+	// Render stdout and stderr, and the logfile, e.g. JSONEncoder for a collector
+	// reading them. The text format if nil, settings like TimeFormat and
+	// Multiline then apply.
+	StdoutEncoder  Encoder
+	LogfileEncoder Encoder
+
+	// Colored, aligned stdout output, unless StdoutEncoder is set. Only takes effect when stdout
+	// is a terminal and NO_COLOR is unset; on Windows that is a console taking escape sequences,
+	// which gologger switches on.
+	PrettyConsole bool
+
+	// Context keys whose values are attached as fields by WithContext/FromContext, mapped to the field name
+	ContextKeys map[interface{}]string
 }
 
+// Most entries the daemon takes off the channel at once to write as a batch.
+const maxBatch = 256
+
 func (l *Log) daemon() {
 	runtime.LockOSThread()
-	_, _, oldDate := time.Now().Date()
 
-	for {
-		message := <-l.logChan
-		messageWithTimestamp := time.Now().Format("15:04:05.0000") + message
+	// armed after a write so buffered sinks get flushed FlushInterval later
+	var flushTimer <-chan time.Time
 
-		if l.SendToStdout {
-			fmt.Print(messageWithTimestamp)
+	batch := make([]Entry, 0, maxBatch)
+	var pending []logMsg
+	for {
+		var msg logMsg
+		urgent := false
+		select {
+		case msg = <-l.logChan:
+		case msg = <-l.urgent:
+			urgent = true
+		case <-l.wake:
+		case <-flushTimer:
+			flushTimer = nil
+			l.writeMu.Lock()
+			l.flushSinks(false)
+			l.writeMu.Unlock()
+			continue
 		}
 
-		if l.SendToSyslog {
-			if l.syslogWriter == nil {
-				syslogWriter, err := syslog.New(syslog.LOG_INFO, l.SyslogTag)
-				if err != nil {
-					l.ERR(err, "Error creating syslog")
-					return
-				}
-				l.syslogWriter = syslogWriter
+		// inline log calls write to the sinks too once SetInline is on
+		l.writeMu.Lock()
+
+		// the priority lane goes ahead of whatever is congesting the channel
+		if urgent || len(l.urgent) > 0 {
+			batch = l.drainUrgent(batch[:0], msg, urgent)
+			l.dispatch(batch)
+			if flushTimer == nil && l.FlushInterval > 0 {
+				flushTimer = time.After(l.FlushInterval)
 			}
-			_, err := l.syslogWriter.Write([]byte(message))
-			if err != nil {
-				l.ERR(err, "Error writing to syslog")
-				return
+			if urgent {
+				msg = logMsg{}
 			}
 		}
 
-		if l.SendToLogfile {
-			if l.fileWriter == nil {
-				l.newFile()
+		if shards := l.shards.Load(); shards != nil {
+			pending = pending[:0]
+			if !msg.isControl() && msg.entry.Level != 0 {
+				pending = append(pending, msg)
+				msg = logMsg{}
 			}
-			_, _, newDate := time.Now().Date()
-			if newDate != oldDate {
-				l.newFile()
-				oldDate = newDate
+			pending, msg = l.drainShards(*shards, pending, msg)
+
+			batch = l.inOrder(batch[:0], pending)
+			if len(batch) > 0 {
+				l.dispatch(batch)
+				if flushTimer == nil && l.FlushInterval > 0 {
+					flushTimer = time.After(l.FlushInterval)
+				}
+			}
+			if queued, _ := l.queueLen(); queued > 0 {
+				// stopped at the batch limit, come back for the rest
+				l.wakeDaemon()
+			}
+		} else if !msg.isControl() && msg.entry.Level != 0 {
+			// take what else is queued already, up to a control message,
+			// so sinks can write it all with one syscall
+			batch = append(batch[:0], msg.entry)
+			msg = logMsg{}
+		drain:
+			for len(batch) < maxBatch {
+				select {
+				case next := <-l.logChan:
+					if next.isControl() {
+						msg = next
+						break drain
+					}
+					batch = append(batch, next.entry)
+				default:
+					break drain
+				}
 			}
 
-			_, err := l.fileWriter.Write([]byte(messageWithTimestamp))
-			if err != nil {
-				l.ERR(err, "Error writing to logfile")
-				return
+			l.dispatch(batch)
+			if flushTimer == nil && l.FlushInterval > 0 {
+				flushTimer = time.After(l.FlushInterval)
 			}
 		}
-	}
-}
 
-func (l *Log) newFile() {
-	if l.fileWriter != nil {
-		err := l.fileWriter.Close()
-		if err != nil {
-			l.ERR(err, "Error closing logfile")
-			return
+		if msg.isControl() && len(l.urgent) > 0 {
+			// queued before the control message, possibly after the lane was drained above
+			l.dispatch(l.drainUrgent(batch[:0], logMsg{}, false))
 		}
+		if msg.flushed != nil {
+			l.flushSinks(true)
+			if msg.sync {
+				l.syncSinks()
+			}
+			close(msg.flushed)
+		}
+		if msg.ctrl != nil {
+			msg.ctrl()
+		}
+		l.writeMu.Unlock()
 	}
-
-	fileName := filepath.Base(os.Args[0]) + "_" + time.Now().Format("2006-01-02") + ".log"
-	fileWriter, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-	if err != nil {
-		l.ERR(err, "Error creating logfile")
-		return
-	}
-	l.fileWriter = fileWriter
 }
 
 func (l *Log) anyErrToString(e interface{}, prompt string) string {
 	switch t := e.(type) {
+	case nil:
+		return prompt
 	case error:
 		return fmt.Sprintf("%s err{%s}", prompt, e.(error).Error())
 	case string:
 		return fmt.Sprintf("%s err{%s}", prompt, e.(string))
 	case byte, int:
-		return fmt.Sprintf("%s RC:%02d", prompt, e.(byte))
+		return fmt.Sprintf("%s RC:%02d", prompt, t)
 	default:
 		return fmt.Sprintf("%s ???{type(%v)=%v}", prompt, t, e)
 	}
 }
 
-func (l *Log) Log(stackTraceDepth int, level byte, message string) {
-	var funcName string
+// levelPriority maps a level tag to the syslog priority it is filtered by.
+func levelPriority(level byte) Priority {
+	switch level {
+	case 'F', 'P':
+		return LOG_CRIT
+	case 'E':
+		return LOG_ERR
+	case 'W':
+		return LOG_WARNING
+	case 'I':
+		return LOG_INFO
+	case 'T':
+		return LOG_TRACE
+	default:
+		if c := customLevel(level); c != nil {
+			return c.Priority
+		}
+		return LOG_DEBUG
+	}
+}
+
+// levelSeverity is levelPriority limited to the severities syslog knows.
+func levelSeverity(level byte) Priority {
+	if p := levelPriority(level); p < LOG_DEBUG {
+		return p
+	}
+	return LOG_DEBUG
+}
 
+func (l *Log) Log(stackTraceDepth int, level byte, message string) {
 	// 2 + stackTraceDepth because first layer is Log(), second layer is ERR/INF/DBG()
-	pc, _, line, ok := runtime.Caller(2 + stackTraceDepth)
-	if !ok {
-		funcName = "<nf>"
-	} else {
-		funcName = runtime.FuncForPC(pc).Name()
-
-		// print struct func as regular func
-		// for example: main.(*Test).exampleFunc() -> Test.exampleFunc()
-		// match will be array of {"(*Test).exampleFunc", "Test", "exampleFunc"}
-		match := regexp.MustCompile(`\(\*([0-z_]+)\)\.([0-z_\(\)]+)$`).FindStringSubmatch(funcName)
-		if match != nil {
-			funcName = match[1]
-			if len(match) > 2 {
-				funcName += "." + match[len(match)-1]
-			}
+	pc, line := l.caller(2 + stackTraceDepth)
+
+	l.output(pc, line, level, message, nil)
+}
+
+// SetDisableCaller skips looking up the caller, which costs noticeably. Entries
+// then have no Func and Line, and package level rules and CallerRateLimit don't apply.
+func (l *Log) SetDisableCaller(disable bool) {
+	l.core().disableCaller.Store(disable)
+}
+
+// CallerDisabled reports whether SetDisableCaller switched the caller off.
+func (l *Log) CallerDisabled() bool {
+	return l.core().disableCaller.Load()
+}
+
+// SetCallerPath sets whether to report the caller's file next to its function.
+func (l *Log) SetCallerPath(style CallerPathStyle) {
+	l.core().callerPath.Store(int32(style))
+}
+
+// CallerPath returns the style set by SetCallerPath, CallerFuncOnly by default.
+func (l *Log) CallerPath() CallerPathStyle {
+	return CallerPathStyle(l.core().callerPath.Load())
+}
+
+// caller is runtime.Caller(skip) of its caller, shifted by CallerSkip. Zero
+// values when SetDisableCaller is set.
+func (l *Log) caller(skip int) (uintptr, int) {
+	if l.core().disableCaller.Load() {
+		return 0, 0
+	}
+
+	// runtime.Caller allocates, Callers into an array doesn't
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2+l.callerSkip, pcs[:]) == 0 {
+		return 0, 0
+	}
+	// the call instruction rather than the return address, like runtime.Caller
+	pc := pcs[0] - 1
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return 0, 0
+	}
+	_, line := fn.FileLine(pc)
+	return pc, line
+}
+
+// CallerSkip returns a child logger reporting the caller n frames further up,
+// for wrapper packages that shouldn't show up as the caller themselves.
+func (l *Log) CallerSkip(n int) *Log {
+	c := l.child(nil)
+	c.callerSkip += n
+	return c
+}
+
+// Sync returns a child logger whose calls return only once the message is
+// written and flushed, for short-lived tools and tests that may exit right after.
+func (l *Log) Sync() *Log {
+	c := l.child(nil)
+	c.synchronous = true
+	return c
+}
+
+// structFuncRe matches methods, e.g. main.(*Test).exampleFunc
+var structFuncRe = regexp.MustCompile(`\(\*([0-z_]+)\)\.([0-z_\(\)]+)$`)
+
+// funcNames caches funcNameForPC, there are only so many call sites.
+var (
+	funcNamesMu sync.RWMutex
+	funcNames   = map[uintptr]string{}
+)
+
+func funcNameForPC(pc uintptr) string {
+	funcNamesMu.RLock()
+	name, ok := funcNames[pc]
+	funcNamesMu.RUnlock()
+	if ok {
+		return name
+	}
+
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "<nf>"
+	}
+	funcName := fn.Name()
+
+	// print struct func as regular func
+	// for example: main.(*Test).exampleFunc() -> Test.exampleFunc()
+	// match will be array of {"(*Test).exampleFunc", "Test", "exampleFunc"}
+	match := structFuncRe.FindStringSubmatch(funcName)
+	if match != nil {
+		funcName = match[1]
+		if len(match) > 2 {
+			funcName += "." + match[len(match)-1]
 		}
 	}
+	funcNamesMu.Lock()
+	funcNames[pc] = funcName
+	funcNamesMu.Unlock()
+	return funcName
+}
 
-	s := fmt.Sprintf("|%c|%s():%d %s\n", level, funcName, line, message)
+// output queues an entry with the logger's fields plus extra for the daemon.
+func (l *Log) output(pc uintptr, line int, level byte, message string, extra []Field) {
+	l.outputEntry(pc, Entry{Level: level, Line: line, Message: message, Fields: extra})
+}
 
-	l.logChan <- s
+// outputEntry completes and queues entry. Callers set Level, Line, Message and
+// their extra Fields, plus Stack when it was captured already, e.g. for a
+// recovered panic, and Err when there is an error value.
+func (l *Log) outputEntry(pc uintptr, entry Entry) {
+	l.outputLazy(pc, entry, nil)
 }
 
-func (l *Log) ERR(e interface{}, prompt string, v ...interface{}) {
-	if l.Priority < syslog.LOG_ERR {
+// outputLazy is outputEntry with the message built by message, if not nil, once
+// the entry has passed the level checks.
+func (l *Log) outputLazy(pc uintptr, entry Entry, message func() string) {
+	core := l.core()
+	if l.cooled != nil {
+		l.cool(&entry)
+	}
+	always := entry.always || alwaysLogged(entry.Level)
+	enabled := always || l.enabledAt(pc, levelPriority(entry.Level))
+
+	// entries below the level still go to the KeepRecent buffer
+	recent := core.recent.Load()
+	if recent != nil && levelPriority(entry.Level) > recent.priority {
+		recent = nil
+	}
+	// and those the next error spike of SetAutoDebug logs
+	autoDebug := core.autoDebug.Load()
+	keep := !enabled && autoDebug != nil && autoDebug.Context > 0 && levelPriority(entry.Level) <= autoDebug.Priority
+	if !enabled && recent == nil && !keep {
+		return
+	}
+	if message != nil {
+		entry.Message = message()
+	}
+
+	now := core.now()
+	if enabled && !always && (l.skipCallSite(pc) || !l.sampled(entry.Level, entry.Message, now) || l.rateLimited(pc, entry.Line, now)) {
+		enabled = false
+		if recent == nil {
+			return
+		}
+	}
+	if core.stopped.Load() {
+		return
+	}
+	entry.Time = now
+	entry.Logger = l.name
+	entry.Tags = l.tags
+	entry.SyslogTag = l.syslogTag
+	if pc != 0 {
+		entry.Func = funcNameForPC(pc)
+		if style := CallerPathStyle(core.callerPath.Load()); style != CallerFuncOnly {
+			entry.File = callerFile(pc, style)
+		}
+	}
+	switch {
+	case len(entry.Fields) == 0:
+		// the logger's fields never change, share them; appending copies
+		entry.Fields = l.fields[:len(l.fields):len(l.fields)]
+	case len(l.fields) > 0:
+		extra := entry.Fields
+		entry.Fields = make([]Field, 0, len(l.fields)+len(extra))
+		entry.Fields = append(entry.Fields, l.fields...)
+		entry.Fields = append(entry.Fields, extra...)
+	}
+	if core.goroutineID.Load() {
+		// full slice expression, the fields may be the logger's own
+		entry.Fields = append(entry.Fields[:len(entry.Fields):len(entry.Fields)], Field{"goroutine", goroutineID()})
+	}
+	if core.monotonic.Load() {
+		entry.Fields = append(entry.Fields[:len(entry.Fields):len(entry.Fields)], Field{"mono_ns", time.Since(core.startTime).Nanoseconds()})
+	}
+
+	if core.sanitize.Load() != int32(SanitizeOff) {
+		core.sanitizeEntry(&entry)
+	}
+	if core.maxMessageSize.Load() > 0 {
+		core.truncateEntry(&entry)
+	}
+
+	if recent != nil {
+		recent.add(entry)
+	}
+	if keep {
+		autoDebug.keep(entry)
+	}
+	if !enabled {
+		return
+	}
+	if f := core.filter.Load(); f != nil && !always && !(*f)(entry) {
+		return
+	}
+
+	if entry.Err != nil {
+		describeError(&entry)
+	}
+	if entry.Stack == "" && levelPriority(entry.Level) <= core.StackTraceLevel {
+		entry.Stack = captureStack(pc, core.StackTraceDepth)
+	}
+	var mismatch *Entry
+	if core.schemaCheck.Load() != int32(SchemaOff) {
+		mismatch = l.checkSchema(&entry)
+	}
+	if core.budgets.Load() != nil && !always && l.overBudget(&entry, now) {
+		core.counters.overBudget.Add(1)
 		return
 	}
-	if v != nil {
-		prompt = fmt.Sprintf(prompt, v...)
+
+	if autoDebug != nil && levelPriority(entry.Level) <= LOG_ERR {
+		for _, e := range autoDebug.observe(now) {
+			core.enqueue(logMsg{entry: e})
+		}
+	}
+	core.enqueue(logMsg{entry: entry})
+	if mismatch != nil {
+		core.enqueue(logMsg{entry: *mismatch})
+	}
+	if l.synchronous {
+		core.Flush()
+	}
+}
+
+func (l *Log) ERR(e interface{}, prompt string, v ...interface{}) {
+	if !l.enabled(LOG_ERR) {
+		return
 	}
+	message, template, fields := formatMessage(prompt, v)
 
-	l.Log(0, 'E', l.anyErrToString(e, prompt))
+	err, _ := e.(error)
+	pc, line := l.caller(1)
+	l.outputEntry(pc, Entry{Level: 'E', Line: line, Message: l.anyErrToString(e, message), Template: template, Fields: fields, Err: err})
 }
 
 func (l *Log) WRN(prompt string, v ...interface{}) {
-	if l.Priority < syslog.LOG_WARNING {
+	if !l.enabled(LOG_WARNING) {
 		return
 	}
-	if v != nil {
-		prompt = fmt.Sprintf(prompt, v...)
-	}
-	l.Log(0, 'W', prompt)
+	l.logTemplate('W', prompt, v)
 }
 
 func (l *Log) INF(prompt string, v ...interface{}) {
-	if l.Priority < syslog.LOG_INFO {
+	if !l.enabled(LOG_INFO) {
 		return
 	}
-	if v != nil {
-		prompt = fmt.Sprintf(prompt, v...)
-	}
-	l.Log(0, 'I', prompt)
+	l.logTemplate('I', prompt, v)
 }
 
 func (l *Log) DBG(prompt string, v ...interface{}) {
-	if l.Priority < syslog.LOG_DEBUG {
+	if !l.enabled(LOG_DEBUG) {
 		return
 	}
-	if v != nil {
-		prompt = fmt.Sprintf(prompt, v...)
+	l.logTemplate('D', prompt, v)
+}
+
+// Logf logs at any level, including those added with RegisterLevel.
+func (l *Log) Logf(level byte, prompt string, v ...interface{}) {
+	if !alwaysLogged(level) && !l.enabled(levelPriority(level)) {
+		return
+	}
+	l.logTemplate(level, prompt, v)
+}
+
+// TRC logs below DBG, for per-packet or per-iteration detail. Off unless
+// Priority or an override is LOG_TRACE.
+func (l *Log) TRC(prompt string, v ...interface{}) {
+	if !l.enabled(LOG_TRACE) {
+		return
 	}
-	l.Log(0, 'D', prompt)
+	l.logTemplate('T', prompt, v)
 }
 
 func (l *Log) Close() {
+	l = l.core()
 	time.Sleep(l.CloseDelay)
-	lenLogChan := len(l.logChan)
-	for lenLogChan > 0 {
+	queued, _ := l.queueLen()
+	for queued > 0 {
 		time.Sleep(100 * time.Millisecond)
-		lenLogChan = len(l.logChan)
+		queued, _ = l.queueLen()
+	}
+	l.Flush()
+}
+
+// Shutdown stops taking new messages, writes the ones still queued, then
+// flushes, syncs and closes every sink. It gives up when ctx is done, returning
+// ctx.Err(), so it fits into a service's graceful shutdown sequence. The
+// callbacks of OnShutdown run first, within three quarters of the time ctx
+// leaves; when they take longer the sinks are still flushed and closed, and
+// the error says so.
+func (l *Log) Shutdown(ctx context.Context) error {
+	l = l.core()
+	callbacksCtx, cancel := ctx, context.CancelFunc(func() {})
+	if deadline, ok := ctx.Deadline(); ok {
+		callbacksCtx, cancel = context.WithDeadline(ctx, deadline.Add(-time.Until(deadline)/4))
+	}
+	var callbacksErr error
+	if err := l.runOnShutdown(callbacksCtx); err != nil {
+		callbacksErr = fmt.Errorf("OnShutdown callbacks: %w", err)
+	}
+	cancel()
+	l.stopped.Store(true)
+
+	var errs []error
+	done := make(chan struct{})
+	msg := logMsg{ctrl: func() {
+		defer close(done)
+		l.flushSinks(true)
+		l.syncSinks()
+		_ = l.forEachSink(func(s Sink) error {
+			err := s.Close()
+			if err != nil {
+				errs = append(errs, err)
+			}
+			return nil
+		})
+	}}
+
+	if l.inline.Load() {
+		l.handleInline(msg)
+	} else {
+		l.startDaemon()
+		select {
+		case l.logChan <- msg:
+		case <-ctx.Done():
+			return errors.Join(callbacksErr, ctx.Err())
+		}
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return errors.Join(callbacksErr, ctx.Err())
+	}
+	if err := l.waitArchiving(ctx); err != nil {
+		return errors.Join(callbacksErr, err)
 	}
+	return errors.Join(append([]error{callbacksErr}, errs...)...)
 }
 
 var L *Log
 
+// packagePath is this package's import path, used to skip our own frames.
+var packagePath = reflect.TypeOf(Log{}).PkgPath()
+
 func init() {
-	L = &Log{
-		logChan: make(chan string, 1000),
+	L = newLog()
+	L.modeFromEnv()
+}
+
+// newLog returns a logger with the defaults of L, for tests needing one of
+// their own.
+func newLog() *Log {
+	l := &Log{
+		logChan:   make(chan logMsg, 1000),
+		urgent:    make(chan logMsg, urgentCapacity),
+		wake:      make(chan struct{}, 1),
+		counters:  &counters{},
+		startTime: time.Now(),
 
 		SendToStdout:  true, // The logger prints to stdout as a default, though can be easily changed.
 		SendToSyslog:  false,
 		SendToLogfile: false,
-		Priority:      syslog.LOG_DEBUG,
+		SendToStderr:  false,
 		SyslogTag:     "GOLOGGER",
 		CloseDelay:    time.Millisecond,
+		Priority:      LOG_DEBUG,
+
+		SyslogFacility: LOG_USER,
+
+		LogfileName:    defaultLogfileName,
+		LogfileMode:    0600,
+		LogfileDirMode: 0750,
+
+		LogfileBufferSize: 0,
+		FlushInterval:     time.Second,
+
+		StackTraceLevel: LOG_EMERG, // none of the levels, it's opt-in
+		StackTraceDepth: defaultStackTraceDepth,
+
+		RateLimitSummaryInterval: time.Minute,
 	}
 
-	go L.daemon()
+	l.priority.Store(int32(LOG_DEBUG))
+	l.stdoutSink = newConsoleSink(l, os.Stdout)
+	l.stderrSink = newConsoleSink(l, os.Stderr)
+	l.FallbackSink = l.stderrSink
+	l.syslogSink = &syslogSink{l: l}
+	l.fileSink = &fileSink{l: l}
+	return l
 }
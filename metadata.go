@@ -0,0 +1,44 @@
+package gologger
+
+import "os"
+
+var (
+	hostname, _ = os.Hostname()
+	pid         = os.Getpid()
+)
+
+// addMetadata numbers entry and prepends the metadata fields switched on in l,
+// then those of enrichments whose keys aren't among them already.
+// Runs in the daemon, so sequence numbers follow the order entries are written in.
+func (l *Log) addMetadata(entry *Entry, enrichments []*enrichment) {
+	l.seq++
+	entry.Seq = l.seq
+
+	var meta []Field
+	if l.SeqField {
+		meta = append(meta, Field{"seq", entry.Seq})
+	}
+	if l.HostField {
+		meta = append(meta, Field{"host", hostname})
+	}
+	if l.PIDField {
+		meta = append(meta, Field{"pid", pid})
+	}
+	if l.AppVersion != "" {
+		meta = append(meta, Field{"version", l.AppVersion})
+	}
+	for _, e := range enrichments {
+		fields := e.fields.Load()
+		if fields == nil {
+			continue
+		}
+		for _, f := range *fields {
+			if !hasField(meta, f.Key) {
+				meta = append(meta, f)
+			}
+		}
+	}
+	if meta != nil {
+		entry.Fields = append(meta, entry.Fields...)
+	}
+}
@@ -0,0 +1,208 @@
+package gologger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+type HTTPLogFormat int
+
+const (
+	HTTPStructured HTTPLogFormat = iota // "GET /path 200" with method, path, status, ... fields
+	HTTPCommonLog                       // Common Log Format, as written by Apache and nginx
+)
+
+type httpMiddleware struct {
+	l               *Log
+	next            http.Handler
+	format          HTTPLogFormat
+	requestIDHeader string
+	correlation     bool
+
+	pc uintptr // of the wrapped handler, reported as the caller
+}
+
+type HTTPOption func(*httpMiddleware)
+
+// HTTPFormat selects between structured and Common Log Format lines.
+func HTTPFormat(format HTTPLogFormat) HTTPOption {
+	return func(m *httpMiddleware) {
+		m.format = format
+	}
+}
+
+// HTTPRequestID logs the given request header (e.g. X-Request-ID) as request_id and
+// attaches it to the request context, so FromContext(r.Context()) includes it too.
+func HTTPRequestID(header string) HTTPOption {
+	return func(m *httpMiddleware) {
+		m.requestIDHeader = header
+	}
+}
+
+// HTTPCorrelationID takes the correlation ID of a request from its
+// X-Request-ID or traceparent header, or makes a new one, and stores it in the
+// request context for FromContext(r.Context()) and CorrelationTransport. It is
+// logged as correlation_id and sent back in the X-Request-ID response header.
+func HTTPCorrelationID() HTTPOption {
+	return func(m *httpMiddleware) {
+		m.correlation = true
+	}
+}
+
+// HTTPMiddleware logs every request handled by next with its method, path, status,
+// latency, response size and remote address. 5xx responses are logged at E, 4xx at W, the rest at I.
+func HTTPMiddleware(l *Log, opts ...HTTPOption) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		m := &httpMiddleware{l: l, next: next}
+		for _, opt := range opts {
+			opt(m)
+		}
+		m.pc = handlerPC(next)
+		return m
+	}
+}
+
+func handlerPC(h http.Handler) uintptr {
+	v := reflect.ValueOf(h)
+	if v.Kind() == reflect.Func {
+		return v.Pointer()
+	}
+	if method := v.MethodByName("ServeHTTP"); method.IsValid() {
+		return method.Pointer()
+	}
+	return 0
+}
+
+func (m *httpMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	var requestID string
+	if m.requestIDHeader != "" {
+		requestID = r.Header.Get(m.requestIDHeader)
+		if requestID != "" {
+			r = r.WithContext(ContextWithFields(r.Context(), "request_id", requestID))
+		}
+	}
+
+	var correlationID string
+	if m.correlation {
+		correlationID = CorrelationIDFromHeader(r.Header)
+		if correlationID == "" {
+			correlationID = NewCorrelationID()
+		}
+		r = r.WithContext(ContextWithCorrelationID(r.Context(), correlationID))
+		w.Header().Set(RequestIDHeader, correlationID)
+	}
+
+	rw := &responseWriter{ResponseWriter: w}
+	m.next.ServeHTTP(rw, r)
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+
+	level := byte('I')
+	switch {
+	case rw.status >= 500:
+		level = 'E'
+	case rw.status >= 400:
+		level = 'W'
+	}
+	if !m.l.enabled(levelPriority(level)) {
+		return
+	}
+
+	var line int
+	if fn := runtime.FuncForPC(m.pc); fn != nil {
+		_, line = fn.FileLine(m.pc)
+	}
+
+	if m.format == HTTPCommonLog {
+		user := "-"
+		if r.URL.User != nil && r.URL.User.Username() != "" {
+			user = r.URL.User.Username()
+		} else if u, _, ok := r.BasicAuth(); ok && u != "" {
+			user = u
+		}
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+
+		message := fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %d",
+			host, user, start.Format("02/Jan/2006:15:04:05 -0700"),
+			r.Method, r.URL.RequestURI(), r.Proto, rw.status, rw.bytes)
+		var extra []Field
+		if requestID != "" {
+			extra = []Field{{"request_id", requestID}}
+		}
+		if correlationID != "" {
+			extra = append(extra, Field{"correlation_id", correlationID})
+		}
+		m.l.output(m.pc, line, level, message, extra)
+		return
+	}
+
+	extra := []Field{
+		{"method", r.Method},
+		{"path", r.URL.Path},
+		{"status", rw.status},
+		{"latency", time.Since(start)},
+		{"bytes", rw.bytes},
+		{"remote_addr", r.RemoteAddr},
+	}
+	if requestID != "" {
+		extra = append(extra, Field{"request_id", requestID})
+	}
+	if correlationID != "" {
+		extra = append(extra, Field{"correlation_id", correlationID})
+	}
+	m.l.output(m.pc, line, level, r.Method+" "+r.URL.Path+" "+strconv.Itoa(rw.status), extra)
+}
+
+// responseWriter records the status code and body size written by the handler.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("gologger: ResponseWriter does not implement http.Hijacker")
+}
+
+// Unwrap lets http.ResponseController reach the original writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
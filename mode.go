@@ -0,0 +1,69 @@
+package gologger
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Mode switches all console output between a format for people and one for
+// machines, see SetMode.
+type Mode int
+
+const (
+	HumanMode   Mode = iota // the text format, colored with PrettyConsole (default)
+	MachineMode             // one JSON object per line, nothing else
+)
+
+// SetMode switches what goes to stdout and stderr, so the same binary can run
+// in a terminal and under an orchestrator collecting its output. MachineMode
+// writes every entry as single-line JSON, overriding StdoutEncoder and
+// PrettyConsole, and keeps gologger's own notices, like the dump of recent
+// entries on a crash, to the same format. The GOLOGGER_MODE environment
+// variable, "human" or "machine", sets it on startup.
+func (l *Log) SetMode(m Mode) {
+	l.core().machine.Store(m == MachineMode)
+}
+
+// Mode returns the mode set by SetMode.
+func (l *Log) Mode() Mode {
+	if l.core().machine.Load() {
+		return MachineMode
+	}
+	return HumanMode
+}
+
+// ParseMode parses "human" or "machine".
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "human":
+		return HumanMode, nil
+	case "machine":
+		return MachineMode, nil
+	default:
+		return 0, fmt.Errorf("unknown mode %q, expected human or machine", s)
+	}
+}
+
+// modeFromEnv applies GOLOGGER_MODE, ignoring values it doesn't know.
+func (l *Log) modeFromEnv() {
+	if m, err := ParseMode(os.Getenv("GOLOGGER_MODE")); err == nil {
+		l.SetMode(m)
+	}
+}
+
+// notice writes a message of gologger itself to stderr, as an entry in
+// MachineMode.
+func (l *Log) notice(level byte, message string, fields ...Field) {
+	l = l.core()
+	if l.machine.Load() {
+		os.Stderr.Write(JSONEncoder{}.Encode(nil, Entry{Time: l.now(), Level: level, Func: "gologger", Message: message, Fields: fields}))
+		return
+	}
+	buf := append([]byte("gologger: "), message...)
+	for _, f := range fields {
+		buf = append(buf, ": "...)
+		buf = fmt.Append(buf, f.Value)
+	}
+	os.Stderr.Write(append(buf, '\n'))
+}
@@ -0,0 +1,284 @@
+package gologger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// MQTTOptions configures NewMQTTSink, the zero value works.
+type MQTTOptions struct {
+	// Topic template with {program}, {hostname}, {logger} and {level}
+	// placeholders, e.g. "logs/{hostname}/{level}". "logs/{program}" if empty.
+	// + and # in the values filled in become _.
+	Topic   string
+	QoS     byte    // 0 unacknowledged, 1 at least once, 2 without duplicates while a connection lasts
+	Retain  bool    // have the broker keep the last entry of each topic
+	Encoder Encoder // renders each message, JSONEncoder if nil
+
+	ClientID           string // "gologger-{hostname}-{pid}" if empty
+	Username, Password string
+	TLS                *tls.Config   // connect with TLS, e.g. to port 8883
+	KeepAlive          time.Duration // a connection idle this long is replaced before use, 60s if zero
+
+	// Set Batch.MaxQueued to bound what is held while the broker is down.
+	Batch BatchOptions
+}
+
+type mqttSink struct {
+	*batcher
+
+	addr  string
+	opts  MQTTOptions
+	topic routeTemplate
+
+	conn     net.Conn
+	r        *bufio.Reader
+	lastUsed time.Time
+	packetID uint16
+	buf      []byte
+	msg      []byte
+}
+
+// NewMQTTSink returns a sink publishing every entry as a message to the MQTT
+// 3.1.1 broker at addr, host:port. With QoS 1 and 2 a batch counts as sent
+// once the broker acknowledged all of it, and is retried over a new
+// connection otherwise. It connects with the first batch, so a broker down at
+// startup only holds up the entries, as Batch says.
+func NewMQTTSink(addr string, opts *MQTTOptions) (Sink, error) {
+	s := &mqttSink{addr: addr}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.QoS > 2 {
+		return nil, fmt.Errorf("invalid MQTT QoS %d, expected 0, 1 or 2", s.opts.QoS)
+	}
+	if s.opts.Topic == "" {
+		s.opts.Topic = "logs/{program}"
+	}
+	if s.opts.ClientID == "" {
+		s.opts.ClientID = "gologger-" + hostname + "-" + strconv.Itoa(os.Getpid())
+	}
+	if s.opts.KeepAlive <= 0 {
+		s.opts.KeepAlive = time.Minute
+	}
+	if s.opts.Encoder == nil {
+		s.opts.Encoder = JSONEncoder{}
+	}
+	// wildcards can't be published to
+	s.topic = newRouteTemplate(s.opts.Topic, "/", "+#\x00")
+	s.batcher = newBatcher(s.opts.Batch, s.push)
+	return s, nil
+}
+
+func (s *mqttSink) connect() error {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if s.opts.TLS != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.addr, s.opts.TLS)
+	} else {
+		conn, err = dialer.Dial("tcp", s.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to MQTT broker: %w", err)
+	}
+
+	flags := byte(0x02) // clean session
+	payload := appendMQTTString(nil, s.opts.ClientID)
+	if s.opts.Username != "" {
+		flags |= 0x80
+		payload = appendMQTTString(payload, s.opts.Username)
+		if s.opts.Password != "" {
+			flags |= 0x40
+			payload = appendMQTTString(payload, s.opts.Password)
+		}
+	}
+	variable := appendMQTTString(nil, "MQTT")
+	variable = append(variable, 4, flags) // protocol level 3.1.1
+	variable = binary.BigEndian.AppendUint16(variable, uint16(s.opts.KeepAlive/time.Second))
+
+	packet := appendMQTTHeader(nil, 0x10, len(variable)+len(payload))
+	packet = append(append(packet, variable...), payload...)
+
+	s.conn, s.r = conn, bufio.NewReader(conn)
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	_, err = conn.Write(packet)
+	if err == nil {
+		err = s.readConnack()
+	}
+	if err != nil {
+		s.disconnect()
+		return fmt.Errorf("connecting to MQTT broker: %w", err)
+	}
+	s.lastUsed = time.Now()
+	return nil
+}
+
+func (s *mqttSink) readConnack() error {
+	typ, body, err := readMQTTPacket(s.r)
+	if err != nil {
+		return err
+	}
+	if typ != 0x20 || len(body) != 2 {
+		return fmt.Errorf("expected CONNACK, got packet type %d", typ>>4)
+	}
+	switch code := body[1]; code {
+	case 0:
+		return nil
+	case 4, 5:
+		return Permanent(fmt.Errorf("broker refused the connection: not authorized (code %d)", code))
+	default:
+		return fmt.Errorf("broker refused the connection with code %d", code)
+	}
+}
+
+func (s *mqttSink) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func (s *mqttSink) push(entries []Entry) error {
+	// the broker drops connections idle past the keep alive, writes to one
+	// may still seem to succeed
+	if s.conn != nil && time.Since(s.lastUsed) >= s.opts.KeepAlive {
+		s.disconnect()
+	}
+	if s.conn == nil {
+		err := s.connect()
+		if err != nil {
+			return err
+		}
+	}
+
+	pending := map[uint16]bool{}
+	b := s.buf[:0]
+	for _, entry := range entries {
+		s.msg = bytes.TrimSuffix(s.opts.Encoder.Encode(s.msg[:0], entry), []byte{'\n'})
+		topic := s.topic.expand(entry)
+
+		header := byte(0x30) | s.opts.QoS<<1
+		if s.opts.Retain {
+			header |= 0x01
+		}
+		length := 2 + len(topic) + len(s.msg)
+		if s.opts.QoS > 0 {
+			length += 2
+		}
+		b = appendMQTTHeader(b, header, length)
+		b = appendMQTTString(b, topic)
+		if s.opts.QoS > 0 {
+			s.packetID++
+			if s.packetID == 0 {
+				s.packetID = 1
+			}
+			pending[s.packetID] = true
+			b = binary.BigEndian.AppendUint16(b, s.packetID)
+		}
+		b = append(b, s.msg...)
+	}
+	s.buf = b
+
+	_ = s.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	_, err := s.conn.Write(b)
+	if err == nil {
+		err = s.awaitAcks(pending)
+	}
+	if err != nil {
+		// the whole batch is resent, part of it may arrive twice
+		s.disconnect()
+		return fmt.Errorf("publishing to MQTT broker: %w", err)
+	}
+	s.lastUsed = time.Now()
+	return nil
+}
+
+// awaitAcks reads the broker's PUBACKs for QoS 1, or PUBRECs and PUBCOMPs
+// for QoS 2, until every packet in pending is done.
+func (s *mqttSink) awaitAcks(pending map[uint16]bool) error {
+	for len(pending) > 0 {
+		typ, body, err := readMQTTPacket(s.r)
+		if err != nil {
+			return err
+		}
+		if len(body) < 2 {
+			continue
+		}
+		id := binary.BigEndian.Uint16(body)
+		switch typ & 0xf0 {
+		case 0x40, 0x70: // PUBACK, PUBCOMP
+			delete(pending, id)
+		case 0x50: // PUBREC, release it
+			if _, err := s.conn.Write([]byte{0x62, 0x02, byte(id >> 8), byte(id)}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *mqttSink) Close() error {
+	err := s.batcher.Close()
+	if s.conn != nil {
+		_, _ = s.conn.Write([]byte{0xe0, 0x00}) // DISCONNECT
+	}
+	s.disconnect()
+	return err
+}
+
+// appendMQTTHeader writes the fixed header of a packet with length bytes after it.
+func appendMQTTHeader(b []byte, typeAndFlags byte, length int) []byte {
+	b = append(b, typeAndFlags)
+	for {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if length == 0 {
+			return b
+		}
+	}
+}
+
+func appendMQTTString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// readMQTTPacket reads a packet, returning its first byte and what follows the fixed header.
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7f) * multiplier
+		multiplier *= 128
+		if digit&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return 0, nil, errors.New("malformed MQTT packet length")
+		}
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return typ, body, err
+}
@@ -0,0 +1,394 @@
+package gologger
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Just enough MessagePack for the sinks speaking it, see https://github.com/msgpack/msgpack/blob/master/spec.md.
+
+func appendMsgpackNil(b []byte) []byte {
+	return append(b, 0xc0)
+}
+
+func appendMsgpackBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+func appendMsgpackInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendMsgpackUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
+
+func appendMsgpackUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+	}
+}
+
+func appendMsgpackFloat(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n <= 31:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendMsgpackBinary(b []byte, v []byte) []byte {
+	n := len(v)
+	switch {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, v...)
+}
+
+func appendMsgpackArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+func appendMsgpackMapHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+	}
+}
+
+// appendMsgpackEventTime writes t as Fluentd's EventTime extension, type 0 with seconds and nanoseconds.
+func appendMsgpackEventTime(b []byte, t time.Time) []byte {
+	b = append(b, 0xd7, 0x00)
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+}
+
+// appendMsgpackValue writes field values, rendering what MessagePack has no type for like the text encoder does.
+func appendMsgpackValue(b []byte, v interface{}) []byte {
+	switch t := v.(type) {
+	case nil:
+		return appendMsgpackNil(b)
+	case string:
+		return appendMsgpackString(b, t)
+	case []byte:
+		return appendMsgpackBinary(b, t)
+	case bool:
+		return appendMsgpackBool(b, t)
+	case time.Time:
+		return appendMsgpackString(b, t.Format(time.RFC3339Nano))
+	case time.Duration:
+		return appendMsgpackString(b, t.String())
+	case error:
+		return appendMsgpackString(b, t.Error())
+	case fmt.Stringer:
+		return appendMsgpackString(b, t.String())
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendMsgpackInt(b, rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendMsgpackUint(b, rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return appendMsgpackFloat(b, rv.Float())
+	case reflect.Slice, reflect.Array:
+		b = appendMsgpackArrayHeader(b, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			b = appendMsgpackValue(b, rv.Index(i).Interface())
+		}
+		return b
+	case reflect.Map:
+		b = appendMsgpackMapHeader(b, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			b = appendMsgpackString(b, fmt.Sprint(iter.Key().Interface()))
+			b = appendMsgpackValue(b, iter.Value().Interface())
+		}
+		return b
+	}
+	return appendMsgpackString(b, fmt.Sprint(v))
+}
+
+// MsgpackEncoder writes each entry as a MessagePack map with the keys of
+// JSONEncoder, the time as the timestamp extension. Entries take about half
+// the space of JSON, Reader reads them back.
+type MsgpackEncoder struct{}
+
+func (MsgpackEncoder) Encode(buf []byte, entry Entry) []byte {
+	n := 3 + len(entry.Fields)
+	for _, s := range []string{entry.Logger, entry.Func, entry.File, entry.MessageID, entry.Stack} {
+		if s != "" {
+			n++
+		}
+	}
+
+	buf = appendMsgpackMapHeader(buf, n)
+	buf = appendMsgpackString(buf, "ts")
+	buf = appendMsgpackTimestamp(buf, entry.Time)
+	buf = appendMsgpackString(buf, "level")
+	buf = appendMsgpackString(buf, levelName(entry.Level))
+	if entry.Logger != "" {
+		buf = appendMsgpackString(buf, "logger")
+		buf = appendMsgpackString(buf, entry.Logger)
+	}
+	if entry.Func != "" {
+		buf = appendMsgpackString(buf, "caller")
+		buf = appendMsgpackString(buf, entry.Func+":"+strconv.Itoa(entry.Line))
+	}
+	if entry.File != "" {
+		buf = appendMsgpackString(buf, "file")
+		buf = appendMsgpackString(buf, entry.File+":"+strconv.Itoa(entry.Line))
+	}
+	buf = appendMsgpackString(buf, "msg")
+	buf = appendMsgpackString(buf, entry.Message)
+	if entry.MessageID != "" {
+		buf = appendMsgpackString(buf, "msg_id")
+		buf = appendMsgpackString(buf, entry.MessageID)
+	}
+	for _, f := range entry.Fields {
+		buf = appendMsgpackString(buf, f.Key)
+		buf = appendMsgpackValue(buf, f.Value)
+	}
+	if entry.Stack != "" {
+		buf = appendMsgpackString(buf, "stacktrace")
+		buf = appendMsgpackString(buf, entry.Stack)
+	}
+	return buf
+}
+
+// appendMsgpackTimestamp writes t as the timestamp extension, type -1, in its 96 bit form.
+func appendMsgpackTimestamp(b []byte, t time.Time) []byte {
+	b = append(b, 0xc7, 12, 0xff)
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+	return binary.BigEndian.AppendUint64(b, uint64(t.Unix()))
+}
+
+// isMsgpackMap tells whether c starts a MessagePack map, which no text line does.
+func isMsgpackMap(c byte) bool {
+	return c&0xf0 == 0x80 || c == 0xde || c == 0xdf
+}
+
+// readMsgpackLen reads the length following a str, bin, array, map or ext
+// header of size bytes.
+func readMsgpackLen(r *bufio.Reader, size int) (int, error) {
+	var b [4]byte
+	if _, err := io.ReadFull(r, b[:size]); err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return int(b[0]), nil
+	case 2:
+		return int(binary.BigEndian.Uint16(b[:2])), nil
+	default:
+		return int(binary.BigEndian.Uint32(b[:4])), nil
+	}
+}
+
+// readMsgpack reads a value: integers as int64, or uint64 beyond its range,
+// maps as map[string]interface{}, timestamps as time.Time.
+func readMsgpack(r *bufio.Reader) (interface{}, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	fixed := func(n int) ([]byte, error) {
+		b := make([]byte, n)
+		_, err := io.ReadFull(r, b)
+		return b, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return readMsgpackMap(r, int(c&0x0f))
+	case c&0xf0 == 0x90:
+		return readMsgpackArray(r, int(c&0x0f))
+	case c&0xe0 == 0xa0:
+		b, err := fixed(int(c & 0x1f))
+		return string(b), err
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2, 0xc3:
+		return c == 0xc3, nil
+	case 0xc4, 0xc5, 0xc6:
+		return readMsgpackBytes(r, 1<<(c-0xc4))
+	case 0xd9, 0xda, 0xdb:
+		b, err := readMsgpackBytes(r, 1<<(c-0xd9))
+		return string(b), err
+	case 0xca:
+		b, err := fixed(4)
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), err
+	case 0xcb:
+		b, err := fixed(8)
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		b, err := fixed(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		var v uint64
+		for _, x := range b {
+			v = v<<8 | uint64(x)
+		}
+		if v > math.MaxInt64 {
+			return v, nil
+		}
+		return int64(v), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		b, err := fixed(size)
+		if err != nil {
+			return nil, err
+		}
+		var v uint64
+		for _, x := range b {
+			v = v<<8 | uint64(x)
+		}
+		shift := 64 - 8*size
+		return int64(v<<shift) >> shift, nil
+	case 0xdc, 0xdd:
+		n, err := readMsgpackLen(r, 2<<(c-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackArray(r, n)
+	case 0xde, 0xdf:
+		n, err := readMsgpackLen(r, 2<<(c-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return readMsgpackMap(r, n)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8, 0xc7, 0xc8, 0xc9:
+		var n int
+		if c >= 0xd4 {
+			n = 1 << (c - 0xd4)
+		} else if n, err = readMsgpackLen(r, 1<<(c-0xc7)); err != nil {
+			return nil, err
+		}
+		b, err := fixed(1 + n)
+		if err != nil {
+			return nil, err
+		}
+		return msgpackExt(int8(b[0]), b[1:])
+	}
+	return nil, fmt.Errorf("unsupported MessagePack type 0x%02x", c)
+}
+
+// readMsgpackBytes reads the data of a str or bin with a length of lenSize bytes.
+func readMsgpackBytes(r *bufio.Reader, lenSize int) ([]byte, error) {
+	n, err := readMsgpackLen(r, lenSize)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+func readMsgpackArray(r *bufio.Reader, n int) ([]interface{}, error) {
+	a := make([]interface{}, n)
+	for i := range a {
+		v, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		a[i] = v
+	}
+	return a, nil
+}
+
+func readMsgpackMap(r *bufio.Reader, n int) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := readMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(k)] = v
+	}
+	return m, nil
+}
+
+// msgpackExt decodes the timestamp extension in its three forms, other
+// extensions stay raw bytes.
+func msgpackExt(typ int8, b []byte) (interface{}, error) {
+	if typ != -1 {
+		return b, nil
+	}
+	switch len(b) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(b)), 0), nil
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(b[4:])), int64(binary.BigEndian.Uint32(b))), nil
+	}
+	return nil, fmt.Errorf("invalid MessagePack timestamp of %d bytes", len(b))
+}
@@ -0,0 +1,43 @@
+package gologger
+
+import "strconv"
+
+// Multiline decides how text output renders messages spanning several lines,
+// e.g. with a pretty-printed payload.
+type Multiline int
+
+const (
+	MultilineKeep   Multiline = iota // as they are
+	MultilineEscape                  // newlines written as \n, one line per entry for line based parsers
+	MultilineIndent                  // continuation lines prefixed with "    | ", telling them apart from new entries
+)
+
+const multilineIndent = "\n    | "
+
+func appendMessage(buf []byte, message string, multiline Multiline) []byte {
+	if multiline == MultilineKeep {
+		return append(buf, message...)
+	}
+
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		switch {
+		case c == '\n' && multiline == MultilineIndent:
+			buf = append(buf, multilineIndent...)
+		case c == '\n':
+			buf = append(buf, `\n`...)
+		case c == '\r' && multiline == MultilineEscape:
+			buf = append(buf, `\r`...)
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return buf
+}
+
+// appendStackLine is the stack trace of an entry as a quoted field, keeping
+// MultilineEscape output on one line.
+func appendStackLine(buf []byte, stack string) []byte {
+	buf = append(buf, " stacktrace="...)
+	return strconv.AppendQuote(buf, stack)
+}
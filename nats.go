@@ -0,0 +1,236 @@
+package gologger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NATSOptions configures NewNATSSink, the zero value works.
+type NATSOptions struct {
+	// Subject template with {program}, {hostname}, {logger} and {level}
+	// placeholders, e.g. "logs.{program}.{level}". "logs.{program}" if empty.
+	// Whitespace, * and > in the values filled in become _.
+	Subject string
+	Encoder Encoder // renders each message, JSONEncoder if nil
+
+	Token              string
+	Username, Password string
+	TLS                *tls.Config // for servers requiring TLS
+
+	// Set Batch.MaxQueued to bound what is held while the server is down.
+	Batch BatchOptions
+}
+
+type natsSink struct {
+	*batcher
+
+	addr    string
+	opts    NATSOptions
+	subject routeTemplate
+
+	conn net.Conn
+	r    *bufio.Reader
+	buf  []byte
+	msg  []byte
+}
+
+// NewNATSSink returns a sink publishing every entry as a message to the NATS
+// server at addr, host:port. Each batch ends with a PING, so a batch counts
+// as sent once the server answered, and is retried over a new connection
+// otherwise. It connects with the first batch, so a server down at startup
+// only holds up the entries, as Batch says.
+func NewNATSSink(addr string, opts *NATSOptions) (Sink, error) {
+	s := &natsSink{addr: addr}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Subject == "" {
+		s.opts.Subject = "logs.{program}"
+	}
+	if s.opts.Encoder == nil {
+		s.opts.Encoder = JSONEncoder{}
+	}
+	// whitespace ends a subject, wildcards can't be published to
+	s.subject = newRouteTemplate(s.opts.Subject, ".", " \t\r\n*>")
+	s.batcher = newBatcher(s.opts.Batch, s.push)
+	return s, nil
+}
+
+func (s *natsSink) connect() error {
+	conn, err := net.DialTimeout("tcp", s.addr, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("connecting to NATS: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	// the server speaks first, telling whether it wants TLS
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("connecting to NATS: no INFO from the server: %v", err)
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+	}
+	_ = json.Unmarshal([]byte(line[5:]), &info)
+	if info.TLSRequired || s.opts.TLS != nil {
+		config := s.opts.TLS
+		if config == nil {
+			config = &tls.Config{}
+		}
+		if config.ServerName == "" {
+			config = config.Clone()
+			config.ServerName, _, _ = net.SplitHostPort(s.addr)
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return fmt.Errorf("connecting to NATS: %w", err)
+		}
+		conn, r = tlsConn, bufio.NewReader(tlsConn)
+	}
+
+	connect, _ := json.Marshal(map[string]interface{}{
+		"verbose":      false,
+		"pedantic":     false,
+		"tls_required": info.TLSRequired || s.opts.TLS != nil,
+		"name":         filepath.Base(os.Args[0]),
+		"lang":         "go",
+		"version":      "gologger",
+		"protocol":     1,
+		"auth_token":   s.opts.Token,
+		"user":         s.opts.Username,
+		"pass":         s.opts.Password,
+	})
+	s.conn, s.r = conn, r
+	_, err = fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect)
+	if err == nil {
+		err = s.awaitPong()
+	}
+	if err != nil {
+		s.disconnect()
+		return fmt.Errorf("connecting to NATS: %w", err)
+	}
+	return nil
+}
+
+func (s *natsSink) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// awaitPong reads up to the PONG answering our PING, answering the server's.
+func (s *natsSink) awaitPong() error {
+	_ = s.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("NATS: " + strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK and INFO updates need nothing
+	}
+}
+
+func (s *natsSink) push(entries []Entry) error {
+	if s.conn == nil {
+		err := s.connect()
+		if err != nil {
+			return err
+		}
+	}
+
+	b := s.buf[:0]
+	for _, entry := range entries {
+		s.msg = bytes.TrimSuffix(s.opts.Encoder.Encode(s.msg[:0], entry), []byte{'\n'})
+		b = append(b, "PUB "...)
+		b = append(b, s.subject.expand(entry)...)
+		b = append(b, ' ')
+		b = strconv.AppendInt(b, int64(len(s.msg)), 10)
+		b = append(b, "\r\n"...)
+		b = append(b, s.msg...)
+		b = append(b, "\r\n"...)
+	}
+	b = append(b, "PING\r\n"...)
+	s.buf = b
+
+	_ = s.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	_, err := s.conn.Write(b)
+	if err == nil {
+		err = s.awaitPong()
+	}
+	if err != nil {
+		// the whole batch is resent, part of it may arrive twice
+		s.disconnect()
+		return fmt.Errorf("publishing to NATS: %w", err)
+	}
+	return nil
+}
+
+func (s *natsSink) Close() error {
+	err := s.batcher.Close()
+	s.disconnect()
+	return err
+}
+
+// routeTemplate fills in a subject or topic template, with {program} and
+// {hostname} once, {logger} and {level} per entry.
+type routeTemplate struct {
+	template  string
+	separator string // of the template's levels, empty ones are dropped
+	invalid   string // characters the values filled in can't have, replaced by '_'
+}
+
+func newRouteTemplate(template, separator, invalid string) routeTemplate {
+	t := routeTemplate{separator: separator, invalid: invalid}
+	t.template = strings.NewReplacer("{program}", t.clean(filepath.Base(os.Args[0])), "{hostname}", t.clean(hostname)).Replace(template)
+	return t
+}
+
+func (t routeTemplate) expand(entry Entry) string {
+	if !strings.Contains(t.template, "{") {
+		return t.template
+	}
+	route := strings.NewReplacer("{logger}", t.clean(entry.Logger), "{level}", t.clean(levelName(entry.Level))).Replace(t.template)
+	double := t.separator + t.separator
+	for strings.Contains(route, double) {
+		route = strings.ReplaceAll(route, double, t.separator)
+	}
+	return strings.Trim(route, t.separator)
+}
+
+// clean replaces the characters of value that are invalid in a route.
+func (t routeTemplate) clean(value string) string {
+	if !strings.ContainsAny(value, t.invalid) {
+		return value
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(t.invalid, r) {
+			return '_'
+		}
+		return r
+	}, value)
+}
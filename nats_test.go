@@ -0,0 +1,26 @@
+package gologger
+
+import "testing"
+
+func TestNewSinksServerDown(t *testing.T) {
+	nats, err := NewNATSSink("127.0.0.1:1", nil)
+	if err != nil {
+		t.Fatalf("NATS: %v", err)
+	}
+	nats.Close()
+	mqtt, err := NewMQTTSink("127.0.0.1:1", nil)
+	if err != nil {
+		t.Fatalf("MQTT: %v", err)
+	}
+	mqtt.Close()
+}
+
+func TestRouteTemplateClean(t *testing.T) {
+	entry := Entry{Level: 'I', Logger: "http server.*>+#"}
+	if got := newRouteTemplate("logs.{logger}.{level}", ".", " \t\r\n*>").expand(entry); got != "logs.http_server.__+#.info" {
+		t.Errorf("NATS subject %q", got)
+	}
+	if got := newRouteTemplate("logs/{logger}", "/", "+#\x00").expand(entry); got != "logs/http server.*>__" {
+		t.Errorf("MQTT topic %q", got)
+	}
+}
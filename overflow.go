@@ -0,0 +1,143 @@
+package gologger
+
+// OverflowPolicy decides what Log() does when the log channel is full. Under
+// Block, TryINF and LogWithTimeout give up on their entries instead.
+type OverflowPolicy int
+
+const (
+	Block      OverflowPolicy = iota // wait until the daemon catches up (default)
+	DropNewest                       // discard the message being logged
+	DropOldest                       // discard the oldest queued message to make room
+)
+
+// SetOverflowPolicy sets what logging does when the log channel is full,
+// Block by default.
+func (l *Log) SetOverflowPolicy(p OverflowPolicy) {
+	l.core().overflowPolicy.Store(int32(p))
+}
+
+// OverflowPolicy returns the policy set by SetOverflowPolicy.
+func (l *Log) OverflowPolicy() OverflowPolicy {
+	return OverflowPolicy(l.core().overflowPolicy.Load())
+}
+
+// urgentCapacity is the room in the priority lane.
+const urgentCapacity = 100
+
+// enqueue hands m to the daemon as the OverflowPolicy says. Entries at E or
+// more severe take the priority lane while the channel is half full or more,
+// which the daemon empties before anything else, so they aren't held up, or
+// dropped, behind a flood of debug entries. They may then be written ahead of
+// entries logged before them. With the lane full too, they queue as usual.
+// Ordered delivery does without the lane and the shards.
+func (l *Log) enqueue(m logMsg) {
+	done := m.entry.done
+	m.entry.done = nil
+	if l.inline.Load() {
+		l.handleInline(m)
+		return
+	}
+	l.startDaemon()
+
+	ordered := Delivery(l.delivery.Load()) == Ordered
+	ch := l.logChan
+	if !ordered {
+		ch = l.queueFor()
+	}
+	if !ordered && levelPriority(m.entry.Level) <= LOG_ERR && len(ch) >= cap(ch)/2 {
+		select {
+		case l.urgent <- m:
+			l.queued(m.entry.Level)
+			return
+		default:
+		}
+	}
+	if l.shards.Load() != nil {
+		// Ordered entries too, the daemon merges them with those of the shards
+		m.order = queueOrder()
+	}
+	if ch != l.logChan {
+		defer l.wakeDaemon()
+	}
+
+	if m.undroppable() {
+		ch <- m
+		l.queued(m.entry.Level)
+		return
+	}
+
+	switch OverflowPolicy(l.overflowPolicy.Load()) {
+	case DropNewest:
+		select {
+		case ch <- m:
+			l.queued(m.entry.Level)
+		default:
+			l.counters.dropped.Add(1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case ch <- m:
+				l.queued(m.entry.Level)
+				return
+			default:
+			}
+
+			// make room, but the daemon may have emptied the slot already
+			select {
+			case old := <-ch:
+				if old.isControl() || old.undroppable() {
+					// someone is waiting on this marker, or it mustn't be lost,
+					// requeue it behind us
+					ch <- old
+					continue
+				}
+				l.counters.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		if done == nil {
+			ch <- m
+			l.queued(m.entry.Level)
+			return
+		}
+
+		// a closed done mustn't win over a free slot
+		select {
+		case ch <- m:
+			l.queued(m.entry.Level)
+			return
+		default:
+		}
+		select {
+		case ch <- m:
+			l.queued(m.entry.Level)
+		case <-done:
+			l.counters.dropped.Add(1)
+		}
+	}
+}
+
+// undroppable reports whether m is an entry no OverflowPolicy drops: fatal
+// messages are the ones that matter most, and Always ones are asked for.
+func (m logMsg) undroppable() bool {
+	return m.entry.Level == 'F' || m.entry.Level == 'P' || m.entry.always || alwaysLogged(m.entry.Level)
+}
+
+// drainUrgent appends the entries waiting in the priority lane to batch, m
+// first when the daemon took it from there.
+func (l *Log) drainUrgent(batch []Entry, m logMsg, urgent bool) []Entry {
+	if urgent {
+		batch = append(batch, m.entry)
+	}
+	for len(batch) < maxBatch {
+		select {
+		case m := <-l.urgent:
+			batch = append(batch, m.entry)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
@@ -0,0 +1,59 @@
+package gologger
+
+import "testing"
+
+func TestDropOldestKeepsFatal(t *testing.T) {
+	l := newLog()
+	l.logChan = make(chan logMsg, 2)
+	l.started.Store(true) // no daemon, the channel stays as filled
+	l.SetOverflowPolicy(DropOldest)
+
+	l.enqueue(logMsg{entry: Entry{Level: 'F', Message: "fatal"}})
+	l.enqueue(logMsg{entry: Entry{Level: 'D', Message: "old"}})
+	l.enqueue(logMsg{entry: Entry{Level: 'I', Message: "new"}})
+
+	var got []string
+	for len(l.logChan) > 0 {
+		got = append(got, (<-l.logChan).entry.Message)
+	}
+	if len(got) != 2 || got[0] != "fatal" || got[1] != "new" {
+		t.Errorf("queued %v, want [fatal new]", got)
+	}
+	if dropped := l.counters.dropped.Load(); dropped != 1 {
+		t.Errorf("%d dropped, want 1", dropped)
+	}
+}
+
+func TestPriorityLane(t *testing.T) {
+	l, _ := newTestLog(t)
+	gate := newGateSink()
+	l.AddSink(gate)
+
+	l.DBG("first")
+	<-gate.waiting
+	for i := 0; i < 600; i++ {
+		l.DBG("debug")
+	}
+	// 100 fit in the lane, the rest queue behind the debug entries
+	for i := 0; i < urgentCapacity+50; i++ {
+		l.ERR(nil, "error")
+	}
+	if len(l.urgent) != urgentCapacity || len(l.logChan) != 650 {
+		t.Fatalf("%d in the lane and %d in the channel, want %d and 650", len(l.urgent), len(l.logChan), urgentCapacity)
+	}
+	close(gate.open)
+	l.Flush()
+
+	messages := gate.messages()
+	if len(messages) != 751 {
+		t.Fatalf("%d entries written by Flush, want 751", len(messages))
+	}
+	for i, m := range messages[1 : 1+urgentCapacity] {
+		if m != "error" {
+			t.Fatalf("entry %d is %s, want the errors of the lane first", i+1, m)
+		}
+	}
+	if messages[1+urgentCapacity] != "debug" || messages[750] != "error" {
+		t.Error("errors beyond the lane not queued behind the debug entries")
+	}
+}
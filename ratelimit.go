@@ -0,0 +1,121 @@
+package gologger
+
+import (
+	"fmt"
+	"time"
+)
+
+// callerBucket is a token bucket for one call site.
+type callerBucket struct {
+	tokens     float64
+	last       time.Time
+	line       int
+	suppressed uint64
+}
+
+// How often the call sites are checked for a summary that is due and for
+// buckets that can go.
+const rateTick = time.Second
+
+// rateLimited reports whether the call site pc is over CallerRateLimit. Every
+// RateLimitSummaryInterval the suppressed counts are logged as warnings.
+func (l *Log) rateLimited(pc uintptr, line int, now time.Time) bool {
+	core := l.core()
+	if core.CallerRateLimit <= 0 || pc == 0 {
+		return false
+	}
+
+	core.rateMu.Lock()
+	defer core.rateMu.Unlock()
+	if core.rateBuckets == nil {
+		core.rateBuckets = make(map[uintptr]*callerBucket)
+		core.rateLastSummary = now
+		go core.watchRateLimits()
+	}
+
+	burst := core.rateBurst()
+	b, ok := core.rateBuckets[pc]
+	if !ok {
+		b = &callerBucket{tokens: burst, last: now, line: line}
+		core.rateBuckets[pc] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * core.CallerRateLimit
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+
+	limited := b.tokens < 1
+	if limited {
+		b.suppressed++
+		core.counters.rateLimited.Add(1)
+	} else {
+		b.tokens--
+	}
+	return limited
+}
+
+func (l *Log) rateBurst() float64 {
+	return max(float64(l.CallerRateBurst), 1)
+}
+
+// watchRateLimits logs what rateSummaries returns every rateTick, until no
+// call site has a bucket left. It runs while l.rateBuckets isn't nil.
+func (l *Log) watchRateLimits() {
+	ticker := time.NewTicker(rateTick)
+	defer ticker.Stop()
+	for range ticker.C {
+		summaries, done := l.rateSummaries(l.now())
+		for _, entry := range summaries {
+			if !l.stopped.Load() {
+				l.enqueue(logMsg{entry: entry})
+			}
+		}
+		if done {
+			return
+		}
+	}
+}
+
+// rateSummaries returns the warnings about the suppressed counts when
+// RateLimitSummaryInterval passed by now, and drops the buckets that would be
+// full again, which are no different from new ones. It reports whether none
+// are left, clearing l.rateBuckets then.
+func (l *Log) rateSummaries(now time.Time) ([]Entry, bool) {
+	l.rateMu.Lock()
+	defer l.rateMu.Unlock()
+
+	var summaries []Entry
+	interval := l.RateLimitSummaryInterval
+	if interval > 0 && now.Sub(l.rateLastSummary) >= interval {
+		for sitePC, site := range l.rateBuckets {
+			if site.suppressed == 0 {
+				continue
+			}
+			summaries = append(summaries, Entry{
+				Time:    now,
+				Level:   'W',
+				Func:    funcNameForPC(sitePC),
+				Line:    site.line,
+				Message: fmt.Sprintf("Rate limited %d messages from this call site in the last %s", site.suppressed, now.Sub(l.rateLastSummary).Round(100*time.Millisecond)),
+				Fields:  []Field{{"suppressed", site.suppressed}},
+			})
+			site.suppressed = 0
+		}
+		l.rateLastSummary = now
+	}
+
+	// what is still suppressed waits for its summary, if there is one
+	burst := l.rateBurst()
+	for sitePC, site := range l.rateBuckets {
+		if (site.suppressed == 0 || interval <= 0) && (l.CallerRateLimit <= 0 || site.tokens+now.Sub(site.last).Seconds()*l.CallerRateLimit >= burst) {
+			delete(l.rateBuckets, sitePC)
+		}
+	}
+	if len(l.rateBuckets) == 0 {
+		l.rateBuckets = nil
+		return summaries, true
+	}
+	return summaries, false
+}
@@ -0,0 +1,42 @@
+package gologger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateSummaries(t *testing.T) {
+	l := newLog()
+	l.CallerRateLimit, l.RateLimitSummaryInterval = 0.001, time.Minute
+	now := time.Now()
+
+	l.rateLimited(2, 20, now.Add(-time.Hour))
+	for i := 0; i < 3; i++ {
+		l.rateLimited(1, 10, now)
+	}
+
+	summaries, done := l.rateSummaries(now.Add(time.Minute))
+	if done || len(summaries) != 1 {
+		t.Fatalf("got %d summaries, done %v, want 1 summary", len(summaries), done)
+	}
+	if summaries[0].Line != 10 || summaries[0].Fields[0].Value != uint64(2) {
+		t.Errorf("summary %+v, want 2 suppressed at line 10", summaries[0])
+	}
+
+	// site 2 has its token back, site 1 not yet
+	l.rateMu.Lock()
+	_, kept := l.rateBuckets[1]
+	_, dropped := l.rateBuckets[2]
+	l.rateMu.Unlock()
+	if !kept || dropped {
+		t.Errorf("site 1 kept %v, site 2 dropped %v", kept, !dropped)
+	}
+
+	summaries, done = l.rateSummaries(now.Add(time.Hour))
+	l.rateMu.Lock()
+	left := l.rateBuckets
+	l.rateMu.Unlock()
+	if len(summaries) != 0 || !done || left != nil {
+		t.Errorf("got %d summaries, done %v, want the buckets gone", len(summaries), done)
+	}
+}
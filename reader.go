@@ -0,0 +1,410 @@
+package gologger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReaderOptions configures NewReader, the zero value reads a logfile written
+// with the default settings, every entry of it.
+type ReaderOptions struct {
+	TimeFormat string         // as the file was written with, see Log.TimeFormat
+	Location   *time.Location // of times without a zone, time.Local if nil
+	Date       time.Time      // the day of times without a date, like DefaultTimeFormat's; today if zero
+
+	From, To time.Time // only entries logged from From and before To, if set
+	Levels   []byte    // only entries at these levels, e.g. []byte{'E', 'W'}, all if empty
+	Filter   Filter    // only entries it keeps, e.g. of ParseFilter
+}
+
+// Reader parses what the file sink and encoders wrote back into entries: the
+// text format of the logfile and stdout, JSONEncoder lines and MsgpackEncoder
+// maps, even mixed.
+// Text can't tell a message ending in key=value from a field, so that is
+// taken for one, and field values come back as strings.
+type Reader struct {
+	opts ReaderOptions
+	br   *bufio.Reader
+	line []byte // read ahead, the first line of the next entry
+	eof  bool
+}
+
+// NewReader returns a Reader of the entries in r.
+func NewReader(r io.Reader, opts *ReaderOptions) *Reader {
+	rd := &Reader{br: bufio.NewReader(r)}
+	if opts != nil {
+		rd.opts = *opts
+	}
+	if rd.opts.Location == nil {
+		rd.opts.Location = time.Local
+	}
+	if rd.opts.Date.IsZero() {
+		rd.opts.Date = time.Now()
+	}
+	return rd
+}
+
+// Next returns the next entry selected by the options, io.EOF after the last.
+func (r *Reader) Next() (Entry, error) {
+	for {
+		entry, err := r.next()
+		if err != nil {
+			return Entry{}, err
+		}
+		if r.selected(entry) {
+			return entry, nil
+		}
+	}
+}
+
+// ReadAll returns the remaining entries selected by the options.
+func (r *Reader) ReadAll() ([]Entry, error) {
+	var entries []Entry
+	for {
+		entry, err := r.Next()
+		if err == io.EOF {
+			return entries, nil
+		} else if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func (r *Reader) selected(e Entry) bool {
+	o := &r.opts
+	if !o.From.IsZero() && e.Time.Before(o.From) || !o.To.IsZero() && !e.Time.Before(o.To) {
+		return false
+	}
+	if len(o.Levels) > 0 && bytes.IndexByte(o.Levels, e.Level) < 0 {
+		return false
+	}
+	return o.Filter == nil || o.Filter(e)
+}
+
+// readLine returns the next line without its newline, the one read ahead first.
+func (r *Reader) readLine() ([]byte, error) {
+	if r.line != nil {
+		line := r.line
+		r.line = nil
+		return line, nil
+	}
+	if r.eof {
+		return nil, io.EOF
+	}
+	line, err := r.br.ReadBytes('\n')
+	if err == io.EOF {
+		r.eof = true
+		if len(line) == 0 {
+			return nil, io.EOF
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r")), nil
+}
+
+func (r *Reader) next() (Entry, error) {
+	for {
+		if r.line == nil {
+			if b, err := r.br.Peek(1); err == nil && isMsgpackMap(b[0]) {
+				entry, err := r.parseMsgpack()
+				if err != nil {
+					return Entry{}, fmt.Errorf("parsing MessagePack entry: %w", err)
+				}
+				return entry, nil
+			}
+		}
+		line, err := r.readLine()
+		if err != nil {
+			return Entry{}, err
+		}
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] == '{' {
+			entry, err := r.parseJSON(line)
+			if err != nil {
+				return Entry{}, fmt.Errorf("parsing %.40q: %w", line, err)
+			}
+			return entry, nil
+		}
+
+		entry, start, ok := r.parseHeader(line)
+		if !ok {
+			return Entry{}, fmt.Errorf("parsing %.40q: not an entry", line)
+		}
+		// the message may go on, followed by a stack trace
+		message := []string{string(line[start:])}
+		var stack []string
+		for {
+			more, err := r.readLine()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return Entry{}, err
+			}
+			if len(more) > 0 && more[0] == '{' {
+				r.line = more
+				break
+			}
+			if _, _, ok := r.parseHeader(more); ok {
+				r.line = more
+				break
+			}
+			switch {
+			case bytes.HasPrefix(more, []byte(multilineIndent[1:])):
+				message = append(message, string(more[len(multilineIndent)-1:]))
+			case bytes.HasPrefix(more, []byte("    ")) || len(stack) > 0:
+				stack = append(stack, string(bytes.TrimPrefix(more, []byte("    "))))
+			default:
+				message = append(message, string(more))
+			}
+		}
+		r.parseCallerAndMessage(&entry, strings.Join(message, "\n"))
+		if len(stack) > 0 {
+			entry.Stack = strings.Join(stack, "\n") + "\n"
+		}
+		return entry, nil
+	}
+}
+
+// parseHeader parses the time and level of a text line, "15:04:05.0000|I|",
+// and returns where the caller starts.
+func (r *Reader) parseHeader(line []byte) (Entry, int, bool) {
+	i := bytes.IndexByte(line, '|')
+	if i < 0 || len(line) < i+3 || line[i+2] != '|' {
+		return Entry{}, 0, false
+	}
+	t, ok := r.parseTime(string(line[:i]))
+	if !ok {
+		return Entry{}, 0, false
+	}
+	return Entry{Time: t, Level: line[i+1]}, i + 3, true
+}
+
+func (r *Reader) parseTime(s string) (time.Time, bool) {
+	switch r.opts.TimeFormat {
+	case TimeNone:
+		return time.Time{}, s == ""
+	case TimeEpochMillis:
+		ms, err := strconv.ParseInt(s, 10, 64)
+		return time.UnixMilli(ms), err == nil
+	}
+	layout := r.opts.TimeFormat
+	if layout == "" {
+		layout = DefaultTimeFormat
+	}
+	t, err := time.ParseInLocation(layout, s, r.opts.Location)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if t.Year() == 0 {
+		y, m, d := r.opts.Date.Date()
+		t = time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	}
+	return t, true
+}
+
+// parseCallerAndMessage parses the rest of a text entry,
+// "func():12 [logger] message key=value".
+func (r *Reader) parseCallerAndMessage(e *Entry, s string) {
+	caller, rest, _ := strings.Cut(s, " ")
+	if fn, line, ok := strings.Cut(caller, "():"); ok {
+		e.Func = fn
+		e.Line, _ = strconv.Atoi(line)
+	} else if strings.HasSuffix(caller, "()") {
+		// with a path, "func() dir/file.go:12"
+		e.Func = strings.TrimSuffix(caller, "()")
+		var file string
+		file, rest, _ = strings.Cut(rest, " ")
+		if i := strings.LastIndexByte(file, ':'); i >= 0 {
+			e.File = file[:i]
+			e.Line, _ = strconv.Atoi(file[i+1:])
+		}
+	} else if caller != "" {
+		rest = s // no caller
+	}
+
+	if strings.HasPrefix(rest, "[") {
+		if logger, after, ok := strings.Cut(rest[1:], "] "); ok {
+			e.Logger, rest = logger, after
+		}
+	}
+
+	e.Message, e.Fields = splitTextFields(rest)
+	for i, f := range e.Fields {
+		if f.Key == "stacktrace" {
+			// MultilineEscape keeps it on the line
+			e.Stack, _ = f.Value.(string)
+			e.Fields = append(e.Fields[:i:i], e.Fields[i+1:]...)
+			break
+		}
+	}
+}
+
+// splitTextFields splits the fields off the end of a message, from the first
+// space after which everything parses as key=value pairs.
+func splitTextFields(s string) (string, []Field) {
+	for i := 0; i < len(s); i++ {
+		if s[i] != ' ' {
+			continue
+		}
+		if fields, ok := parseTextFields(s[i:]); ok {
+			return s[:i], fields
+		}
+	}
+	return s, nil
+}
+
+// parseTextFields parses " key=value key="quoted value"".
+func parseTextFields(s string) ([]Field, bool) {
+	var fields []Field
+	for s != "" {
+		if s[0] != ' ' {
+			return nil, false
+		}
+		eq := strings.IndexByte(s, '=')
+		if eq < 2 || strings.ContainsAny(s[1:eq], " \"") {
+			return nil, false
+		}
+		key := s[1:eq]
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, false
+			}
+			value, _ = strconv.Unquote(quoted)
+			s = s[len(quoted):]
+		} else {
+			end := strings.IndexByte(s, ' ')
+			if end < 0 {
+				end = len(s)
+			}
+			value, s = s[:end], s[end:]
+			if value == "" || strings.ContainsAny(value, "=\"\n") {
+				return nil, false
+			}
+		}
+		fields = append(fields, Field{key, value})
+	}
+	return fields, true
+}
+
+// parseJSON parses a line of JSONEncoder, keeping the order of the fields.
+func (r *Reader) parseJSON(line []byte) (Entry, error) {
+	var e Entry
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if _, err := dec.Token(); err != nil {
+		return e, err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return e, err
+		}
+		key, _ := t.(string)
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return e, err
+		}
+		if err := setEntryKey(&e, key, value); err != nil {
+			return e, err
+		}
+	}
+	if e.Level == 0 {
+		return e, errors.New("no level")
+	}
+	return e, nil
+}
+
+// parseMsgpack reads an entry of MsgpackEncoder, keeping the order of the fields.
+func (r *Reader) parseMsgpack() (Entry, error) {
+	var e Entry
+	c, err := r.br.ReadByte()
+	if err != nil {
+		return e, err
+	}
+	n := int(c & 0x0f)
+	if c == 0xde || c == 0xdf {
+		if n, err = readMsgpackLen(r.br, 2<<(c-0xde)); err != nil {
+			return e, err
+		}
+	}
+	for i := 0; i < n; i++ {
+		k, err := readMsgpack(r.br)
+		if err != nil {
+			return e, err
+		}
+		value, err := readMsgpack(r.br)
+		if err != nil {
+			return e, err
+		}
+		key, _ := k.(string)
+		if err := setEntryKey(&e, key, value); err != nil {
+			return e, err
+		}
+	}
+	if e.Level == 0 {
+		return e, errors.New("no level")
+	}
+	return e, nil
+}
+
+// setEntryKey sets what key holds in an entry of JSONEncoder or MsgpackEncoder.
+func setEntryKey(e *Entry, key string, value interface{}) error {
+	s, isString := value.(string)
+	switch {
+	case key == "ts" && isString:
+		var err error
+		e.Time, err = time.Parse(time.RFC3339Nano, s)
+		return err
+	case key == "ts":
+		if t, ok := value.(time.Time); ok {
+			e.Time = t
+			return nil
+		}
+		e.Fields = append(e.Fields, Field{key, value})
+	case key == "level" && isString:
+		e.Level = levelTag(s)
+	case key == "logger" && isString:
+		e.Logger = s
+	case key == "caller" && isString:
+		e.Func, e.Line = splitLocation(s)
+	case key == "file" && isString:
+		e.File, e.Line = splitLocation(s)
+	case key == "msg" && isString:
+		e.Message = s
+	case key == "msg_id" && isString:
+		e.MessageID = s
+	case key == "stacktrace" && isString:
+		e.Stack = s
+	default:
+		e.Fields = append(e.Fields, Field{key, value})
+	}
+	return nil
+}
+
+// splitLocation splits "name:12" into the name and the line.
+func splitLocation(s string) (string, int) {
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return s, 0
+	}
+	line, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return s, 0
+	}
+	return s[:i], line
+}
@@ -0,0 +1,127 @@
+package gologger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReaderRoundTrip(t *testing.T) {
+	day := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return day.Add(10*time.Hour + time.Duration(s)*time.Second) }
+	entries := []Entry{
+		{Time: at(1), Level: 'I', Func: "main.main", Line: 12, Message: "started", Fields: []Field{{"port", "8080"}, {"name", "my app"}}},
+		{Time: at(2), Level: 'W', Func: "db.(*Pool).Get", Line: 40, Logger: "db", Message: "slow query x=1"},
+		{Time: at(3), Level: 'E', Func: "main.run", File: "cmd/app/main.go", Line: 7, Message: "failed\nsecond line", Fields: []Field{{"err", "disk full"}},
+			Stack: "main.run()\n\t/src/main.go:7\n"},
+		{Time: at(4), Level: 'D', Message: "no caller"},
+	}
+
+	for _, multiline := range []Multiline{MultilineKeep, MultilineEscape, MultilineIndent} {
+		encoder := TextEncoder{UTC: true, Multiline: multiline}
+		var buf []byte
+		for _, e := range entries {
+			buf = encoder.Encode(buf, e)
+		}
+		got, err := NewReader(strings.NewReader(string(buf)), &ReaderOptions{Location: time.UTC, Date: day}).ReadAll()
+		if err != nil {
+			t.Fatalf("multiline %d: %v", multiline, err)
+		}
+		want := append([]Entry(nil), entries...)
+		// fields end where the message can't be told apart
+		want[1].Message, want[1].Fields = "slow query", []Field{{"x", "1"}}
+		if multiline == MultilineEscape {
+			want[2].Message = `failed\nsecond line`
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("multiline %d:\n got %+v\nwant %+v", multiline, got, want)
+		}
+	}
+
+	var buf []byte
+	for _, e := range entries {
+		buf = JSONEncoder{}.Encode(buf, e)
+	}
+	got, err := NewReader(strings.NewReader(string(buf)), nil).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range got {
+		got[i].Time = got[i].Time.UTC()
+	}
+	if !reflect.DeepEqual(got, entries) {
+		t.Errorf("JSON:\n got %+v\nwant %+v", got, entries)
+	}
+}
+
+func TestReaderMsgpack(t *testing.T) {
+	at := time.Date(2024, 3, 5, 10, 0, 0, 123456789, time.UTC)
+	entries := []Entry{
+		{Time: at, Level: 'I', Func: "main.main", Line: 12, Message: "started", MessageID: "app.start", Fields: []Field{
+			{"port", int64(8080)}, {"big", uint64(1 << 63)}, {"neg", int64(-40000)}, {"ratio", 0.5}, {"ok", true}, {"none", nil},
+			{"tags", []interface{}{"a", int64(1)}}, {"user", map[string]interface{}{"name": "bob"}}, {"long", strings.Repeat("x", 300)},
+		}},
+		{Time: at.Add(time.Second), Level: 'E', Logger: "db", File: "db/pool.go", Func: "db.Get", Line: 40, Message: "failed", Stack: "db.Get()\n"},
+	}
+	var buf []byte
+	for _, e := range entries {
+		buf = MsgpackEncoder{}.Encode(buf, e)
+	}
+	// mixed with text
+	buf = TextEncoder{UTC: true}.Encode(buf, Entry{Time: at, Level: 'W', Message: "text"})
+
+	got, err := NewReader(strings.NewReader(string(buf)), &ReaderOptions{Location: time.UTC, Date: at}).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[2].Message != "text" {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range got[:2] {
+		got[i].Time = got[i].Time.UTC()
+	}
+	if !reflect.DeepEqual(got[:2], entries) {
+		t.Errorf("MessagePack:\n got %+v\nwant %+v", got[:2], entries)
+	}
+}
+
+func TestReaderSelection(t *testing.T) {
+	var buf []byte
+	start := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+	for i, level := range []byte("IWEDI") {
+		buf = JSONEncoder{}.Encode(buf, Entry{Time: start.Add(time.Duration(i) * time.Minute), Level: level, Message: fmt.Sprint(i)})
+	}
+	filter, _ := ParseFilter(`msg!="2"`)
+
+	tests := []struct {
+		opts ReaderOptions
+		want string
+	}{
+		{ReaderOptions{}, "01234"},
+		{ReaderOptions{From: start.Add(time.Minute), To: start.Add(3 * time.Minute)}, "12"},
+		{ReaderOptions{Levels: []byte("EW")}, "12"},
+		{ReaderOptions{Filter: filter}, "0134"},
+	}
+	for _, tt := range tests {
+		entries, err := NewReader(strings.NewReader(string(buf)), &tt.opts).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		for _, e := range entries {
+			got += e.Message
+		}
+		if got != tt.want {
+			t.Errorf("%+v: %s, want %s", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestReaderNotAnEntry(t *testing.T) {
+	_, err := NewReader(strings.NewReader("hello\n"), nil).Next()
+	if err == nil || !strings.Contains(err.Error(), "not an entry") {
+		t.Errorf("Next = %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package gologger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// recentBuffer is a ring of the last entries logged at priority or more severe.
+type recentBuffer struct {
+	priority Priority
+
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+func (r *recentBuffer) add(entry Entry) {
+	r.mu.Lock()
+	r.entries[r.next] = entry
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+	r.mu.Unlock()
+}
+
+// snapshot returns the entries oldest first.
+func (r *recentBuffer) snapshot() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]Entry(nil), r.entries[:r.next]...)
+	}
+	entries := make([]Entry, 0, len(r.entries))
+	entries = append(entries, r.entries[r.next:]...)
+	return append(entries, r.entries[:r.next]...)
+}
+
+// redactRecent runs entries, kept before the daemon's hooks saw them, through
+// the Redactor and Scrubber hooks, so what is served or dumped is masked as
+// in the sinks. Those two are safe to call outside the daemon, other hooks
+// aren't called.
+func (l *Log) redactRecent(entries []Entry) []Entry {
+	var hooks []Hook
+	for _, h := range l.core().currentHooks() {
+		switch h.(type) {
+		case *Redactor, *Scrubber:
+			hooks = append(hooks, h)
+		}
+	}
+	if len(hooks) == 0 {
+		return entries
+	}
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry, ok := runBeforeHooks(hooks, entry); ok {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// KeepRecent keeps the last n entries at p or more severe in memory, whether
+// or not they pass Priority, e.g. LOG_DEBUG while logging only errors. They
+// are written by DumpRecent, and to stderr by FTL, PANIC and the Recover
+// functions, giving post-mortem context at little cost. n 0 stops it.
+func (l *Log) KeepRecent(n int, p Priority) {
+	l = l.core()
+	if n <= 0 {
+		l.recent.Store(nil)
+		return
+	}
+	l.recent.Store(&recentBuffer{priority: p, entries: make([]Entry, n)})
+}
+
+// DumpRecent writes the entries kept by KeepRecent, oldest first.
+func (l *Log) DumpRecent(w io.Writer) error {
+	r := l.core().recent.Load()
+	if r == nil {
+		return nil
+	}
+
+	encoder := TextEncoder{TimeFormat: l.core().TimeFormat, UTC: l.core().TimeUTC}
+	var buf []byte
+	for _, entry := range l.redactRecent(r.snapshot()) {
+		buf = encoder.Encode(buf, entry)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// dumpRecentOnCrash is DumpRecent to stderr, for FTL, PANIC and recovered panics.
+func (l *Log) dumpRecentOnCrash() {
+	r := l.core().recent.Load()
+	if r == nil {
+		return
+	}
+	if l.core().machine.Load() {
+		var buf []byte
+		for _, entry := range l.redactRecent(r.snapshot()) {
+			buf = JSONEncoder{}.Encode(buf, entry)
+		}
+		_, _ = os.Stderr.Write(buf)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "--- last %d log entries ---\n", len(r.entries))
+	_ = l.DumpRecent(os.Stderr)
+	fmt.Fprintln(os.Stderr, "---")
+}
@@ -0,0 +1,53 @@
+package gologger
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// Recover logs a panic with its stack at fatal level and flushes the sinks, then
+// lets the goroutine return normally. Use it as `defer l.Recover()`.
+func (l *Log) Recover() {
+	if r := recover(); r != nil {
+		l.logPanic(r)
+	}
+}
+
+// RecoverAndPanic is Recover followed by panicking again with the same value.
+func (l *Log) RecoverAndPanic() {
+	if r := recover(); r != nil {
+		l.logPanic(r)
+		panic(r)
+	}
+}
+
+// RecoverAndExit is Recover followed by exiting with status 2, like an unrecovered panic does.
+func (l *Log) RecoverAndExit() {
+	if r := recover(); r != nil {
+		l.logPanic(r)
+		os.Exit(2)
+	}
+}
+
+func (l *Log) logPanic(r interface{}) {
+	pcs := panicStack()
+
+	var pc uintptr
+	var line int
+	if len(pcs) > 0 {
+		frame, _ := runtime.CallersFrames(pcs).Next()
+		pc, line = frame.PC, frame.Line
+	}
+
+	err, _ := r.(error)
+	l.outputEntry(pc, Entry{
+		Level:   'F',
+		Line:    line,
+		Message: fmt.Sprintf("Recovered panic: %v", r),
+		Stack:   formatStack(pcs),
+		Err:     err,
+	})
+	l.flush(true)
+	l.dumpRecentOnCrash()
+}
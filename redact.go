@@ -0,0 +1,131 @@
+package gologger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Patterns for NewRedactor. When a pattern has a group named "secret" only
+// that group is masked, so the surrounding context stays readable.
+var (
+	CreditCardPattern  = regexp.MustCompile(`\b(?:4|5[1-5]|2[2-7]|3[47]|6(?:011|5))\d(?:[ -]?\d){11,17}\b`)
+	BearerTokenPattern = regexp.MustCompile(`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]+=*)`)
+	PasswordPattern    = regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret)\s*[=:]\s*(?P<secret>[^\s&,;]+)`)
+	EmailPattern       = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// SensitiveKeys are field keys NewRedactor masks regardless of their value.
+var SensitiveKeys = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "authorization", "cookie"}
+
+// Redactor is a Hook masking sensitive data in messages and fields before any sink sees it.
+type Redactor struct {
+	// Fields with these keys are masked entirely. Matched case-insensitively
+	// against the whole key and its last dotted segment, e.g. "req.password".
+	Keys []string
+
+	// Matches are masked in the message and in field values.
+	Patterns []*regexp.Regexp
+
+	Mask string // "[REDACTED]" if empty
+}
+
+// NewRedactor returns a Redactor for SensitiveKeys and the credit card,
+// bearer token, password and email patterns. Add it with AddHook.
+func NewRedactor() *Redactor {
+	return &Redactor{
+		Keys:     SensitiveKeys,
+		Patterns: []*regexp.Regexp{CreditCardPattern, BearerTokenPattern, PasswordPattern, EmailPattern},
+	}
+}
+
+func (r *Redactor) Before(entry *Entry) bool {
+	entry.Message = r.redact(entry.Message)
+
+	var fields []Field
+	for i, f := range entry.Fields {
+		value, changed := r.redactField(f)
+		if !changed {
+			continue
+		}
+		// the slice may be shared with the logger, copy before the first change
+		if fields == nil {
+			fields = make([]Field, len(entry.Fields))
+			copy(fields, entry.Fields)
+		}
+		fields[i].Value = value
+	}
+	if fields != nil {
+		entry.Fields = fields
+	}
+	return true
+}
+
+func (r *Redactor) After(entry Entry, err error) {}
+
+func (r *Redactor) mask() string {
+	if r.Mask == "" {
+		return "[REDACTED]"
+	}
+	return r.Mask
+}
+
+func (r *Redactor) sensitiveKey(key string) bool {
+	last := key[strings.LastIndexByte(key, '.')+1:]
+	for _, k := range r.Keys {
+		if strings.EqualFold(key, k) || strings.EqualFold(last, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) redactField(f Field) (interface{}, bool) {
+	if r.sensitiveKey(f.Key) {
+		return r.mask(), true
+	}
+
+	var s string
+	switch v := f.Value.(type) {
+	case string:
+		s = v
+	case error, fmt.Stringer:
+		s = fmt.Sprint(v)
+	default:
+		return nil, false
+	}
+	redacted := r.redact(s)
+	return redacted, redacted != s
+}
+
+func (r *Redactor) redact(s string) string {
+	for _, re := range r.Patterns {
+		s = redactPattern(re, s, r.mask())
+	}
+	return s
+}
+
+func redactPattern(re *regexp.Regexp, s, mask string) string {
+	secret := re.SubexpIndex("secret")
+	if secret < 0 {
+		return re.ReplaceAllLiteralString(s, mask)
+	}
+
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*secret], m[2*secret+1]
+		if start < 0 {
+			continue
+		}
+		sb.WriteString(s[last:start])
+		sb.WriteString(mask)
+		last = end
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
@@ -0,0 +1,115 @@
+package gologger
+
+import "errors"
+
+// BuiltinSink names one of the built-in sinks for Call.To.
+type BuiltinSink int
+
+const (
+	SinkStdout BuiltinSink = iota + 1
+	SinkStderr
+	SinkSyslog
+	SinkFile
+)
+
+// Write fails, a BuiltinSink only names a sink.
+func (BuiltinSink) Write(Entry) error {
+	return errors.New("gologger: a BuiltinSink can only be passed to To")
+}
+
+func (BuiltinSink) Close() error {
+	return nil
+}
+
+// Call is a single log call with its own destinations and level checks, for
+// the exceptional message that must get somewhere regardless of the
+// configuration, e.g.
+//
+//	l.To(gologger.SinkFile).Level('E').Always().Msg("license violation by %s", user)
+//
+// A Call is meant to be built and used in one statement.
+type Call struct {
+	l      *Log
+	level  byte
+	to     []Sink
+	always bool
+	fields []Field
+}
+
+// To starts a Call writing only to sinks, see Call.To.
+func (l *Log) To(sinks ...Sink) *Call {
+	return &Call{l: l, level: 'I', to: sinks}
+}
+
+// Always starts a Call bypassing the level checks, see Call.Always.
+func (l *Log) Always() *Call {
+	return &Call{l: l, level: 'I', always: true}
+}
+
+// To writes the entry to sinks only: those added with AddSink, the built-in
+// ones named by SinkStdout, SinkStderr, SinkSyslog and SinkFile, or any other.
+// They get it even when not enabled or added, regardless of the level tags
+// they are configured for.
+func (c *Call) To(sinks ...Sink) *Call {
+	c.to = append(c.to, sinks...)
+	return c
+}
+
+// Level sets the level tag of the entry, 'I' by default.
+func (c *Call) Level(level byte) *Call {
+	c.level = level
+	return c
+}
+
+// Always logs the entry whatever the logger's levels, sampling, rate limits
+// and filter say, like a level registered as Always. It is never dropped when
+// the queue is full either.
+func (c *Call) Always() *Call {
+	c.always = true
+	return c
+}
+
+// With adds fields to the entry, as alternating keys and values.
+func (c *Call) With(keyvals ...interface{}) *Call {
+	c.fields = append(c.fields, keyValsToFields(keyvals)...)
+	return c
+}
+
+// Msg logs the entry, prompt being a format or template as for INF.
+func (c *Call) Msg(prompt string, v ...interface{}) {
+	l := c.l
+	if !c.always && !alwaysLogged(c.level) && !l.enabled(levelPriority(c.level)) {
+		return
+	}
+	pc, line := l.caller(1)
+	entry := Entry{Level: c.level, Line: line, to: c.to, always: c.always}
+	var fields []Field
+	entry.Message, entry.Template, fields = formatMessage(prompt, v)
+	entry.Fields = append(c.fields, fields...)
+	l.outputEntry(pc, entry)
+}
+
+// routedTo reports whether an entry with destinations to is written to s.
+func (l *Log) routedTo(to []Sink, s Sink) bool {
+	for _, t := range to {
+		if l.resolveSink(t) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSink returns the sink named by a BuiltinSink, else s.
+func (l *Log) resolveSink(s Sink) Sink {
+	switch s {
+	case SinkStdout:
+		return l.stdoutSink
+	case SinkStderr:
+		return l.stderrSink
+	case SinkSyslog:
+		return l.syslogSink
+	case SinkFile:
+		return l.fileSink
+	}
+	return s
+}
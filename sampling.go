@@ -0,0 +1,102 @@
+package gologger
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+)
+
+// Sampling throttles identical messages: within every Tick the first Initial
+// occurrences are logged, then only every Thereafter-th (none if Thereafter is 0).
+type Sampling struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration // a second if zero
+}
+
+// Messages are hashed into a fixed number of counters, collisions only make sampling a bit stricter.
+const samplingCounters = 4096
+
+type samplingCounter struct {
+	resetAt atomic.Int64
+	n       atomic.Uint64
+}
+
+type sampler struct {
+	Sampling
+	counters [samplingCounters]samplingCounter
+}
+
+// allow counts message and reports whether it should be logged.
+func (s *sampler) allow(level byte, message string, now time.Time) bool {
+	h := fnv.New32a()
+	h.Write([]byte{level})
+	h.Write([]byte(message))
+	c := &s.counters[h.Sum32()%samplingCounters]
+
+	tick := s.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+
+	var n uint64
+	tn := now.UnixNano()
+	resetAt := c.resetAt.Load()
+	if resetAt > tn {
+		n = c.n.Add(1)
+	} else {
+		c.n.Store(1)
+		if c.resetAt.CompareAndSwap(resetAt, tn+tick.Nanoseconds()) {
+			n = 1
+		} else {
+			n = c.n.Add(1)
+		}
+	}
+
+	initial := uint64(s.Initial)
+	if n <= initial {
+		return true
+	}
+	return s.Thereafter > 0 && (n-initial)%uint64(s.Thereafter) == 0
+}
+
+// samplers is replaced as a whole on every change; child loggers start out sharing their parent's.
+type samplers map[byte]*sampler
+
+// SetSampling throttles messages at level logged through l and the child loggers
+// created from it afterwards. A nil s turns sampling off for that level.
+func (l *Log) SetSampling(level byte, s *Sampling) {
+	l.samplingMu.Lock()
+	defer l.samplingMu.Unlock()
+
+	next := samplers{}
+	if current := l.sampling.Load(); current != nil {
+		for k, v := range *current {
+			next[k] = v
+		}
+	}
+	if s == nil {
+		delete(next, level)
+	} else {
+		next[level] = &sampler{Sampling: *s}
+	}
+
+	if len(next) == 0 {
+		l.sampling.Store(nil)
+		return
+	}
+	l.sampling.Store(&next)
+}
+
+func (l *Log) sampled(level byte, message string, now time.Time) bool {
+	set := l.sampling.Load()
+	if set == nil {
+		return true
+	}
+	s, ok := (*set)[level]
+	if !ok || s.allow(level, message, now) {
+		return true
+	}
+	l.core().counters.sampledOut.Add(1)
+	return false
+}
@@ -0,0 +1,175 @@
+package gologger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sanitize is what happens to control characters in messages and fields, see
+// SetSanitize.
+type Sanitize int
+
+const (
+	SanitizeOff    Sanitize = iota // written as they are (default)
+	SanitizeEscape                 // written as Go escapes like \x1b, showing what was there
+	SanitizeStrip                  // removed, with whole ANSI escape sequences
+)
+
+// ParseSanitize parses "off", "escape" or "strip".
+func ParseSanitize(s string) (Sanitize, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "off":
+		return SanitizeOff, nil
+	case "escape":
+		return SanitizeEscape, nil
+	case "strip":
+		return SanitizeStrip, nil
+	default:
+		return 0, fmt.Errorf("unknown sanitize mode %q, expected off, escape or strip", s)
+	}
+}
+
+// SetSanitize guards against log injection through user input that ends up
+// in messages, field keys and field values, strings as well as the text of
+// errors, fmt.Stringers and byte slices: newlines forging entries, ANSI
+// escape sequences recoloring or clearing the terminal of whoever reads the
+// log, carriage returns and backspaces hiding what came before them on a
+// line, C1 controls and the Unicode bidirectional overrides reordering what
+// is shown. Tabs are kept. Multi-line messages, e.g. of Group, then end up on
+// one line, whatever Multiline says. It applies before SetMaxMessageSize, to
+// every sink.
+func (l *Log) SetSanitize(s Sanitize) {
+	l.core().sanitize.Store(int32(s))
+}
+
+// Sanitize returns what SetSanitize set.
+func (l *Log) Sanitize() Sanitize {
+	return Sanitize(l.core().sanitize.Load())
+}
+
+// unsafeRune reports whether r is a control character SetSanitize takes care of.
+func unsafeRune(r rune) bool {
+	switch {
+	case r == '\t':
+		return false
+	case r < 0x20 || r == 0x7f:
+		return true
+	case r >= 0x80 && r <= 0x9f:
+		return true
+	case r >= 0x202a && r <= 0x202e, r >= 0x2066 && r <= 0x2069:
+		return true
+	}
+	return r == utf8.RuneError
+}
+
+// sanitize applies s to text.
+func sanitize(text string, s Sanitize) string {
+	clean := true
+	for _, r := range text {
+		if unsafeRune(r) {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return text
+	}
+
+	b := make([]byte, 0, len(text)+16)
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case !unsafeRune(r) || r == utf8.RuneError && size == 3:
+			// U+FFFD itself, as written, is harmless
+			b = append(b, text[i:i+size]...)
+		case s == SanitizeStrip:
+			if r == 0x1b {
+				size = escapeSequenceLen(text[i:])
+			}
+		case r == utf8.RuneError:
+			b = append(b, `\x`...)
+			b = append(b, "0123456789abcdef"[text[i]>>4], "0123456789abcdef"[text[i]&0xf])
+		default:
+			quoted := strconv.QuoteRune(r)
+			b = append(b, quoted[1:len(quoted)-1]...)
+		}
+		i += size
+	}
+	return string(b)
+}
+
+// escapeSequenceLen is the length of the escape sequence text starts with:
+// a CSI sequence like "\x1b[31m", an OSC one like a terminal title up to BEL
+// or ST, or else the escape and the character after it.
+func escapeSequenceLen(text string) int {
+	if len(text) < 2 {
+		return len(text)
+	}
+	switch text[1] {
+	case '[':
+		for i := 2; i < len(text); i++ {
+			if text[i] >= 0x40 && text[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(text)
+	case ']':
+		for i := 2; i < len(text); i++ {
+			if text[i] == 0x07 {
+				return i + 1
+			}
+			if text[i] == 0x1b && i+1 < len(text) && text[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(text)
+	}
+	_, size := utf8.DecodeRuneInString(text[1:])
+	return 1 + size
+}
+
+// sanitizeEntry applies SetSanitize to the message and the fields.
+func (l *Log) sanitizeEntry(entry *Entry) {
+	s := Sanitize(l.sanitize.Load())
+	entry.Message = sanitize(entry.Message, s)
+
+	copied := false
+	for i, f := range entry.Fields {
+		key := sanitize(f.Key, s)
+		value, changed := sanitizeValue(f.Value, s)
+		if key == f.Key && !changed {
+			continue
+		}
+		if !copied {
+			// the fields may be the logger's own, shared by every entry
+			entry.Fields = append([]Field(nil), entry.Fields...)
+			copied = true
+		}
+		entry.Fields[i] = Field{key, value}
+	}
+}
+
+// sanitizeValue applies s to the text of v, a string, error, fmt.Stringer or
+// byte slice. When that has to change, the value becomes the sanitized text.
+func sanitizeValue(v interface{}, s Sanitize) (interface{}, bool) {
+	var text string
+	switch v := v.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	case error:
+		text = v.Error()
+	case fmt.Stringer:
+		text = v.String()
+	default:
+		return v, false
+	}
+	clean := sanitize(text, s)
+	if clean == text {
+		return v, false
+	}
+	return clean, true
+}
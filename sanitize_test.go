@@ -0,0 +1,62 @@
+package gologger
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		in, escaped, stripped string
+	}{
+		{"plain text\twith tab", "plain text\twith tab", "plain text\twith tab"},
+		{"user=ann\nI| root logged in", `user=ann\nI| root logged in`, "user=annI| root logged in"},
+		{"\x1b[31mred\x1b[0m", `\x1b[31mred\x1b[0m`, "red"},
+		{"\x1b]0;pwned\x07title", `\x1b]0;pwned\atitle`, "title"},
+		{"user=ann\rroot login ok", `user=ann\rroot login ok`, "user=annroot login ok"},
+		{"abc\bd", `abc\bd`, "abcd"},
+		{"file\u202etxt.exe", `file\u202etxt.exe`, "filetxt.exe"},
+		{"8-bit \xc2\x9b CSI", `8-bit \u009b CSI`, "8-bit  CSI"},
+		{"raw \x9b31m", `raw \x9b31m`, "raw 31m"},
+		{"unicode ok: ü �", "unicode ok: ü �", "unicode ok: ü �"},
+	}
+	for _, tt := range tests {
+		if got := sanitize(tt.in, SanitizeEscape); got != tt.escaped {
+			t.Errorf("escape %q = %q, want %q", tt.in, got, tt.escaped)
+		}
+		if got := sanitize(tt.in, SanitizeStrip); got != tt.stripped {
+			t.Errorf("strip %q = %q, want %q", tt.in, got, tt.stripped)
+		}
+	}
+}
+
+func TestSanitizeEntry(t *testing.T) {
+	l := &Log{}
+	l.SetSanitize(SanitizeEscape)
+	shared := []Field{{"user", "\x1b[2J"}, {"n", 1}}
+	entry := Entry{Message: "login \x1b[1m", Fields: shared}
+	l.sanitizeEntry(&entry)
+	if entry.Message != `login \x1b[1m` || entry.Fields[0].Value != `\x1b[2J` {
+		t.Errorf("sanitized %q %v", entry.Message, entry.Fields)
+	}
+
+	entry = Entry{Fields: []Field{
+		{"key\nforged", 1},
+		{"err", errors.New("bad input\nE| forged")},
+		{"raw", []byte("\x1b[2J")},
+		{"ok", errors.New("fine")},
+	}}
+	l.sanitizeEntry(&entry)
+	want := []Field{{`key\nforged`, 1}, {"err", `bad input\nE| forged`}, {"raw", `\x1b[2J`}}
+	for i, w := range want {
+		if entry.Fields[i] != w {
+			t.Errorf("field %d sanitized to %v, want %v", i, entry.Fields[i], w)
+		}
+	}
+	if _, ok := entry.Fields[3].Value.(error); !ok {
+		t.Error("a clean error was replaced")
+	}
+	if shared[0].Value != "\x1b[2J" {
+		t.Error("the logger's fields were changed")
+	}
+}
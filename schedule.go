@@ -0,0 +1,148 @@
+package gologger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LevelSchedule is a daily window logging at its own level, e.g. debug during
+// maintenance from 02:00 to 04:00, see SetLevelSchedule.
+type LevelSchedule struct {
+	From, To string         // local time of day, "02:00" or "02:00:30"; To before From spans midnight
+	Days     []time.Weekday // the days the window starts on, every day if empty
+	Level    Priority
+}
+
+type levelWindow struct {
+	from, length time.Duration
+	days         []time.Weekday
+	level        Priority
+}
+
+// SetLevelSchedule sets the priority to the Level of the first schedule whose
+// window it is in, and to base outside of them, switching by itself as the
+// windows begin and end. Every switch is logged. No schedules stop it,
+// leaving base set.
+func (l *Log) SetLevelSchedule(base Priority, schedules []LevelSchedule) error {
+	l = l.core()
+	windows := make([]levelWindow, len(schedules))
+	for i, s := range schedules {
+		from, err := parseTimeOfDay(s.From)
+		if err != nil {
+			return err
+		}
+		to, err := parseTimeOfDay(s.To)
+		if err != nil {
+			return err
+		}
+		length := to - from
+		if length <= 0 {
+			length += 24 * time.Hour
+		}
+		windows[i] = levelWindow{from: from, length: length, days: s.Days, level: s.Level}
+	}
+
+	l.scheduleMu.Lock()
+	defer l.scheduleMu.Unlock()
+	if l.stopSchedule != nil {
+		l.stopSchedule()
+		l.stopSchedule = nil
+	}
+	l.SetPriority(base)
+	if len(windows) == 0 {
+		return nil
+	}
+
+	quit := make(chan struct{})
+	l.stopSchedule = func() { close(quit) }
+	go func() {
+		current := base
+		for {
+			now := time.Now()
+			level, next := scheduledLevel(windows, base, now)
+			if level != current {
+				l.SetPriority(level)
+				// logged whatever the new level, e.g. when switching to warnings only
+				l.Always().Msg("Log level switched to %s by schedule", levelName(priorityLevel(level)))
+				current = level
+			}
+			// wake up at least hourly, in case the wall clock jumps
+			timer := time.NewTimer(min(next.Sub(now), time.Hour))
+			select {
+			case <-timer.C:
+			case <-quit:
+				timer.Stop()
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// scheduledLevel returns the level at now and when a window begins or ends next.
+func scheduledLevel(windows []levelWindow, base Priority, now time.Time) (Priority, time.Time) {
+	level, found := base, false
+	next := now.Add(24 * time.Hour)
+	y, m, d := now.Date()
+	for _, w := range windows {
+		for day := -1; day <= 1; day++ {
+			// by the wall clock, so windows keep their hours on days DST begins or ends
+			at := func(offset time.Duration) time.Time {
+				return time.Date(y, m, d+day, 0, 0, int(offset/time.Second), 0, now.Location())
+			}
+			start := at(w.from)
+			end := at(w.from + w.length)
+			if !w.onDay(start.Weekday()) {
+				continue
+			}
+			if start.After(now) && start.Before(next) {
+				next = start
+			}
+			if end.After(now) && end.Before(next) {
+				next = end
+			}
+			if !found && !now.Before(start) && now.Before(end) {
+				level, found = w.level, true
+			}
+		}
+	}
+	return level, next
+}
+
+func (w levelWindow) onDay(day time.Weekday) bool {
+	if len(w.days) == 0 {
+		return true
+	}
+	for _, d := range w.days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimeOfDay parses "15:04" or "15:04:05" into the time since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	layout := "15:04"
+	if strings.Count(s, ":") == 2 {
+		layout = "15:04:05"
+	}
+	t, err := time.Parse(layout, strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected e.g. 02:00", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+}
+
+// parseWeekday accepts English day names and their abbreviations, e.g. "mon" or "Monday".
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if len(s) >= 3 && strings.HasPrefix(name, s) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
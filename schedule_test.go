@@ -0,0 +1,86 @@
+package gologger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduledLevel(t *testing.T) {
+	maintenance := levelWindow{from: 2 * time.Hour, length: 2 * time.Hour, level: LOG_DEBUG}
+	// Saturday 22:00 to Sunday 06:00
+	weekend := levelWindow{from: 22 * time.Hour, length: 8 * time.Hour, days: []time.Weekday{time.Saturday}, level: LOG_TRACE}
+	windows := []levelWindow{maintenance, weekend}
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 6, day, hour, minute, 0, 0, time.UTC) // the 1st is a Saturday
+	}
+	tests := []struct {
+		now   time.Time
+		level Priority
+		next  time.Time
+	}{
+		{at(3, 1, 0), LOG_INFO, at(3, 2, 0)},
+		{at(3, 2, 0), LOG_DEBUG, at(3, 4, 0)},
+		{at(3, 3, 59), LOG_DEBUG, at(3, 4, 0)},
+		{at(3, 4, 0), LOG_INFO, at(4, 2, 0)},
+		{at(1, 23, 0), LOG_TRACE, at(2, 2, 0)},
+		{at(2, 5, 0), LOG_TRACE, at(2, 6, 0)},
+		{at(3, 23, 0), LOG_INFO, at(4, 2, 0)}, // Monday night, not the weekend
+	}
+	for _, tt := range tests {
+		level, next := scheduledLevel(windows, LOG_INFO, tt.now)
+		if level != tt.level || !next.Equal(tt.next) {
+			t.Errorf("at %v: %v until %v, want %v until %v", tt.now, level, next, tt.level, tt.next)
+		}
+	}
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	for s, want := range map[string]time.Duration{"02:00": 2 * time.Hour, "23:59:30": 23*time.Hour + 59*time.Minute + 30*time.Second} {
+		if got, err := parseTimeOfDay(s); err != nil || got != want {
+			t.Errorf("parseTimeOfDay(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "2", "25:00", "02:00pm"} {
+		if _, err := parseTimeOfDay(s); err == nil {
+			t.Errorf("parseTimeOfDay(%q) succeeded", s)
+		}
+	}
+}
+
+func TestScheduledLevelDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	// clocks go from 02:00 to 03:00 on March 10th 2024
+	windows := []levelWindow{{from: 4 * time.Hour, length: 2 * time.Hour, level: LOG_DEBUG}}
+	level, next := scheduledLevel(windows, LOG_INFO, time.Date(2024, 3, 10, 3, 30, 0, 0, loc))
+	if want := time.Date(2024, 3, 10, 4, 0, 0, 0, loc); level != LOG_INFO || !next.Equal(want) {
+		t.Errorf("got %v until %v, want info until %v", level, next, want)
+	}
+	level, next = scheduledLevel(windows, LOG_INFO, time.Date(2024, 3, 10, 4, 30, 0, 0, loc))
+	if want := time.Date(2024, 3, 10, 6, 0, 0, 0, loc); level != LOG_DEBUG || !next.Equal(want) {
+		t.Errorf("got %v until %v, want debug until %v", level, next, want)
+	}
+}
+
+func TestApplyRemovesLevelSchedule(t *testing.T) {
+	l, _ := newTestLog(t)
+	schedule := []LevelScheduleConfig{{From: "00:00", To: "23:59:59", Level: "debug"}}
+	if err := l.Apply(&Config{Level: "info", LevelSchedule: schedule}); err != nil {
+		t.Fatal(err)
+	}
+	if l.stopSchedule == nil {
+		t.Fatal("schedule not started")
+	}
+	if err := l.Apply(&Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if l.stopSchedule != nil {
+		t.Error("schedule kept after it was removed from the config")
+	}
+	if got := l.CurrentPriority(); got != LOG_INFO {
+		t.Errorf("priority %v, want the schedule's base", got)
+	}
+}
@@ -0,0 +1,221 @@
+package gologger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// EventKey is the field naming the event type of an entry logged without
+// LogID, whose message ID names it otherwise, see SetSchema.
+const EventKey = "event"
+
+// FieldKind is the type a Schema expects of a field's value.
+type FieldKind int
+
+const (
+	AnyKind      FieldKind = iota // any value, only the field's presence is checked
+	StringKind                    // string
+	IntKind                       // int and uint of any size
+	FloatKind                     // float32, float64, or an int
+	BoolKind                      // bool
+	TimeKind                      // time.Time
+	DurationKind                  // time.Duration
+)
+
+var fieldKindNames = []string{"any", "string", "int", "float", "bool", "time", "duration"}
+
+func (k FieldKind) String() string {
+	if k < 0 || int(k) >= len(fieldKindNames) {
+		return fmt.Sprintf("FieldKind(%d)", int(k))
+	}
+	return fieldKindNames[k]
+}
+
+// ParseFieldKind parses the name of a FieldKind, e.g. "string".
+func ParseFieldKind(s string) (FieldKind, error) {
+	for k, name := range fieldKindNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return FieldKind(k), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown field kind %q, expected one of %s", s, strings.Join(fieldKindNames, ", "))
+}
+
+func (k FieldKind) matches(value interface{}) bool {
+	switch value.(type) {
+	case string:
+		return k == AnyKind || k == StringKind
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr:
+		return k == AnyKind || k == IntKind || k == FloatKind
+	case float32, float64:
+		return k == AnyKind || k == FloatKind
+	case bool:
+		return k == AnyKind || k == BoolKind
+	case time.Time:
+		return k == AnyKind || k == TimeKind
+	case time.Duration:
+		return k == AnyKind || k == DurationKind
+	}
+	return k == AnyKind
+}
+
+// Schema declares the fields entries of an event type have, so dashboards and
+// alerts built on them don't silently break when a field is renamed.
+type Schema struct {
+	Required map[string]FieldKind
+	Optional map[string]FieldKind // checked when present
+}
+
+// check returns what is wrong with fields, nothing if they match.
+func (s *Schema) check(fields []Field) []string {
+	var problems []string
+	kindOf := func(key string, kind FieldKind, required bool) {
+		// the last one wins, as in the encoders
+		for i := len(fields) - 1; i >= 0; i-- {
+			if fields[i].Key == key {
+				if !kind.matches(fields[i].Value) {
+					problems = append(problems, fmt.Sprintf("%s is %T, want %s", key, fields[i].Value, kind))
+				}
+				return
+			}
+		}
+		if required {
+			problems = append(problems, "missing "+key)
+		}
+	}
+	for _, key := range schemaKeys(s.Required) {
+		kindOf(key, s.Required[key], true)
+	}
+	for _, key := range schemaKeys(s.Optional) {
+		kindOf(key, s.Optional[key], false)
+	}
+	return problems
+}
+
+// schemaKeys returns the keys of fields sorted, for problems in a stable order.
+func schemaKeys(fields map[string]FieldKind) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// SchemaCheck says what happens to entries not matching the Schema of their
+// event type, see SetSchemaCheck.
+type SchemaCheck int
+
+const (
+	SchemaOff   SchemaCheck = iota // entries aren't checked (default)
+	SchemaWarn                     // a warning follows the entry
+	SchemaPanic                    // the log call panics with a *SchemaError
+)
+
+// ParseSchemaCheck parses "off", "warn" or "panic".
+func ParseSchemaCheck(s string) (SchemaCheck, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "off":
+		return SchemaOff, nil
+	case "warn":
+		return SchemaWarn, nil
+	case "panic":
+		return SchemaPanic, nil
+	default:
+		return 0, fmt.Errorf("unknown schema check %q, expected off, warn or panic", s)
+	}
+}
+
+// SchemaError is what a log call panics with under SchemaPanic.
+type SchemaError struct {
+	Event    string
+	Problems []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("gologger: entry doesn't match the schema of event %q: %s", e.Event, strings.Join(e.Problems, "; "))
+}
+
+// SetSchema declares the fields of the entries of an event type, which is the
+// message ID for LogID and the EventKey field otherwise. SetSchemaCheck turns
+// checking them on. A nil s removes the schema.
+func (l *Log) SetSchema(event string, s *Schema) {
+	l = l.core()
+	l.schemaMu.Lock()
+	defer l.schemaMu.Unlock()
+
+	next := map[string]*Schema{}
+	if current := l.schemas.Load(); current != nil {
+		for k, v := range *current {
+			next[k] = v
+		}
+	}
+	if s != nil {
+		schema := *s
+		next[event] = &schema
+	} else {
+		delete(next, event)
+	}
+
+	if len(next) == 0 {
+		l.schemas.Store(nil)
+		return
+	}
+	l.schemas.Store(&next)
+}
+
+// SetSchemaCheck makes log calls check their entries against the schemas of
+// SetSchema, meant for development and tests, where SchemaPanic makes a
+// renamed field fail right away. Entries below the level aren't checked.
+func (l *Log) SetSchemaCheck(c SchemaCheck) {
+	l.core().schemaCheck.Store(int32(c))
+}
+
+// SchemaCheck returns what SetSchemaCheck set.
+func (l *Log) SchemaCheck() SchemaCheck {
+	return SchemaCheck(l.core().schemaCheck.Load())
+}
+
+// checkSchema checks entry against the schema of its event type, if any, and
+// returns the warning to log after it on a mismatch.
+func (l *Log) checkSchema(entry *Entry) *Entry {
+	core := l.core()
+	schemas := core.schemas.Load()
+	if schemas == nil {
+		return nil
+	}
+	event := entry.MessageID
+	if event == "" {
+		for i := len(entry.Fields) - 1; i >= 0; i-- {
+			if entry.Fields[i].Key == EventKey {
+				event, _ = entry.Fields[i].Value.(string)
+				break
+			}
+		}
+	}
+	schema, ok := (*schemas)[event]
+	if event == "" || !ok {
+		return nil
+	}
+	problems := schema.check(entry.Fields)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if SchemaCheck(core.schemaCheck.Load()) == SchemaPanic {
+		panic(&SchemaError{Event: event, Problems: problems})
+	}
+	// at the caller of the entry
+	return &Entry{
+		Time:    entry.Time,
+		Level:   'W',
+		Logger:  entry.Logger,
+		Func:    entry.Func,
+		File:    entry.File,
+		Line:    entry.Line,
+		Message: fmt.Sprintf("Entry doesn't match the schema of event %q", event),
+		Fields:  []Field{{"schema", event}, {"problems", strings.Join(problems, "; ")}},
+	}
+}
@@ -0,0 +1,64 @@
+package gologger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSchemaCheck(t *testing.T) {
+	schema := &Schema{
+		Required: map[string]FieldKind{"user": StringKind, "took": DurationKind, "amount": FloatKind},
+		Optional: map[string]FieldKind{"retries": IntKind},
+	}
+	tests := []struct {
+		fields   []Field
+		problems []string
+	}{
+		{[]Field{{"user", "ann"}, {"took", time.Second}, {"amount", 3}}, nil},
+		{[]Field{{"user", "ann"}, {"took", time.Second}, {"amount", 2.5}, {"retries", uint8(1)}}, nil},
+		{[]Field{{"username", "ann"}, {"took", time.Second}, {"amount", 2.5}}, []string{"missing user"}},
+		{[]Field{{"user", 7}, {"took", 1000}, {"amount", "2.5"}, {"retries", "1"}},
+			[]string{"amount is string, want float", "took is int, want duration", "user is int, want string", "retries is string, want int"}},
+		{[]Field{{"user", 7}, {"user", "ann"}, {"took", time.Second}, {"amount", 1.0}}, nil}, // the last one counts
+	}
+	for _, tt := range tests {
+		if got := schema.check(tt.fields); !reflect.DeepEqual(got, tt.problems) {
+			t.Errorf("check(%v) = %q, want %q", tt.fields, got, tt.problems)
+		}
+	}
+}
+
+func TestCheckSchemaEvent(t *testing.T) {
+	l := &Log{}
+	l.SetSchema("user.login", &Schema{Required: map[string]FieldKind{"user": StringKind}})
+
+	if w := l.checkSchema(&Entry{Fields: []Field{{EventKey, "user.login"}}}); w == nil || w.Fields[1].Value != "missing user" {
+		t.Errorf("event field: warning %v", w)
+	}
+	if w := l.checkSchema(&Entry{MessageID: "user.login", Fields: []Field{{"user", "ann"}}}); w != nil {
+		t.Errorf("message ID: warning %v", w)
+	}
+	if w := l.checkSchema(&Entry{Fields: []Field{{EventKey, "other"}}}); w != nil {
+		t.Errorf("event without schema: warning %v", w)
+	}
+
+	l.SetSchemaCheck(SchemaPanic)
+	defer func() {
+		if err, ok := recover().(*SchemaError); !ok || err.Event != "user.login" {
+			t.Errorf("recovered %v, want a *SchemaError", err)
+		}
+	}()
+	l.checkSchema(&Entry{MessageID: "user.login"})
+}
+
+func TestParseFieldKind(t *testing.T) {
+	for kind := AnyKind; kind <= DurationKind; kind++ {
+		if got, err := ParseFieldKind(kind.String()); err != nil || got != kind {
+			t.Errorf("ParseFieldKind(%q) = %v, %v", kind.String(), got, err)
+		}
+	}
+	if _, err := ParseFieldKind("integer"); err == nil {
+		t.Error("ParseFieldKind(integer) succeeded")
+	}
+}
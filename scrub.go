@@ -0,0 +1,179 @@
+package gologger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ScrubAction is what a Scrubber puts in place of a value.
+type ScrubAction int
+
+const (
+	ScrubMask ScrubAction = iota // the Scrubber's Mask
+	ScrubHash                    // "sha256:" and the start of its hash, still telling equal values apart
+)
+
+// ScrubRule selects values by key path: the field's key, then the keys of the
+// maps and structs nested in it, joined with dots, e.g. "request.headers.authorization".
+// Keys compare case-insensitively, "*" stands for any one key, and a path
+// running into a slice applies to each element.
+type ScrubRule struct {
+	Path   string
+	Action ScrubAction
+}
+
+// Scrubber is a Hook replacing the values at key paths in fields, for nested
+// objects where a Redactor's patterns can't tell what is what. Structs are
+// looked into by their JSON form, the field then holds that instead.
+type Scrubber struct {
+	Rules   []ScrubRule
+	Mask    string // "[REDACTED]" if empty
+	HashKey []byte // makes ScrubHash an HMAC, so values can't be guessed by hashing candidates
+}
+
+// NewScrubber returns a Scrubber masking the values at paths. Add it with AddHook.
+func NewScrubber(paths ...string) *Scrubber {
+	s := &Scrubber{}
+	for _, path := range paths {
+		s.Rules = append(s.Rules, ScrubRule{Path: path})
+	}
+	return s
+}
+
+func (s *Scrubber) Before(entry *Entry) bool {
+	var fields []Field
+	for i, f := range entry.Fields {
+		value, changed := f.Value, false
+		for _, rule := range s.Rules {
+			path := strings.Split(rule.Path, ".")
+			if !scrubKeyMatches(path[0], f.Key) {
+				continue
+			}
+			var c bool
+			value, c = s.scrub(value, path[1:], rule.Action)
+			changed = changed || c
+		}
+		if !changed {
+			continue
+		}
+		// the slice may be shared with the logger, copy before the first change
+		if fields == nil {
+			fields = make([]Field, len(entry.Fields))
+			copy(fields, entry.Fields)
+		}
+		fields[i].Value = value
+	}
+	if fields != nil {
+		entry.Fields = fields
+	}
+	return true
+}
+
+func (s *Scrubber) After(entry Entry, err error) {}
+
+func scrubKeyMatches(pattern, key string) bool {
+	return pattern == "*" || strings.EqualFold(pattern, key)
+}
+
+// scrub returns v with what path selects in it replaced, copying rather than
+// changing the maps and slices it goes through, they belong to the caller.
+func (s *Scrubber) scrub(v interface{}, path []string, action ScrubAction) (interface{}, bool) {
+	if len(path) == 0 {
+		return s.replacement(v, action), true
+	}
+	switch t := v.(type) {
+	case nil:
+		return v, false
+	case map[string]interface{}:
+		return s.scrubMap(t, path, action)
+	case Fields:
+		m, changed := s.scrubMap(t, path, action)
+		if changed {
+			return Fields(m), true
+		}
+		return v, false
+	case []interface{}:
+		var out []interface{}
+		for i, e := range t {
+			scrubbed, changed := s.scrub(e, path, action)
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = append([]interface{}(nil), t...)
+			}
+			out[i] = scrubbed
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map, reflect.Struct, reflect.Pointer, reflect.Slice, reflect.Array:
+		// e.g. http.Header or a request struct, look into its JSON form
+		b, err := json.Marshal(v)
+		if err != nil {
+			return v, false
+		}
+		var generic interface{}
+		if json.Unmarshal(b, &generic) != nil {
+			return v, false
+		}
+		if scrubbed, changed := s.scrub(generic, path, action); changed {
+			return scrubbed, true
+		}
+	}
+	return v, false
+}
+
+func (s *Scrubber) scrubMap(m map[string]interface{}, path []string, action ScrubAction) (map[string]interface{}, bool) {
+	var out map[string]interface{}
+	for key, value := range m {
+		if !scrubKeyMatches(path[0], key) {
+			continue
+		}
+		scrubbed, changed := s.scrub(value, path[1:], action)
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]interface{}, len(m))
+			for k, v := range m {
+				out[k] = v
+			}
+		}
+		out[key] = scrubbed
+	}
+	if out == nil {
+		return m, false
+	}
+	return out, true
+}
+
+func (s *Scrubber) replacement(v interface{}, action ScrubAction) string {
+	if action != ScrubHash {
+		if s.Mask == "" {
+			return "[REDACTED]"
+		}
+		return s.Mask
+	}
+
+	text, ok := v.(string)
+	if !ok {
+		text = fmt.Sprint(v)
+	}
+	var sum []byte
+	if s.HashKey != nil {
+		sum = hmacSHA256(s.HashKey, text)
+	} else {
+		h := sha256.Sum256([]byte(text))
+		sum = h[:]
+	}
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
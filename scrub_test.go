@@ -0,0 +1,46 @@
+package gologger
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestScrubber(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		SSN  string `json:"ssn"`
+	}
+	headers := http.Header{"Authorization": {"Bearer abc"}, "Accept": {"*/*"}}
+	request := map[string]interface{}{"path": "/login", "headers": headers}
+	items := []interface{}{map[string]interface{}{"card": "4111", "qty": 1}}
+	entry := Entry{Fields: []Field{
+		{"request", request},
+		{"user", user{"bob", "123-45-6789"}},
+		{"items", items},
+		{"email", "bob@example.com"},
+		{"other", "kept"},
+	}}
+	original := append([]Field(nil), entry.Fields...)
+
+	s := NewScrubber("request.headers.authorization", "user.ssn", "items.card")
+	s.Rules = append(s.Rules, ScrubRule{Path: "Email", Action: ScrubHash})
+	s.Before(&entry)
+
+	want := []Field{
+		{"request", map[string]interface{}{"path": "/login", "headers": map[string]interface{}{
+			"Authorization": "[REDACTED]", "Accept": []interface{}{"*/*"}}}},
+		{"user", map[string]interface{}{"name": "bob", "ssn": "[REDACTED]"}},
+		{"items", []interface{}{map[string]interface{}{"card": "[REDACTED]", "qty": 1}}},
+		{"email", "sha256:5ff860bf1190596c"},
+		{"other", "kept"},
+	}
+	if !reflect.DeepEqual(entry.Fields, want) {
+		t.Errorf("scrubbed fields\n%#v\nwant\n%#v", entry.Fields, want)
+	}
+	// the caller's values stay as they were
+	if !reflect.DeepEqual(original, []Field{{"request", request}, {"user", user{"bob", "123-45-6789"}}, {"items", items}, {"email", "bob@example.com"}, {"other", "kept"}}) ||
+		headers.Get("Authorization") != "Bearer abc" || items[0].(map[string]interface{})["card"] != "4111" {
+		t.Error("scrubbing changed the logged values")
+	}
+}
@@ -0,0 +1,47 @@
+package gologger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SelfTest writes an entry through every enabled sink and flushes it, so a
+// misconfigured file, syslog or collector shows at startup instead of with the
+// first error. It returns the failures, each naming the sink type. The entry
+// is an I with the field selftest=true; a LeveledSink without I drops it.
+func (l *Log) SelfTest() error {
+	l = l.core()
+	entry := Entry{
+		Time:    l.now(),
+		Level:   'I',
+		Func:    "gologger",
+		Message: "self-test",
+		Fields:  []Field{{"selftest", true}},
+	}
+
+	var errs []error
+	done := make(chan struct{})
+	l.send(logMsg{ctrl: func() {
+		defer close(done)
+		_ = l.forEachSink(func(s Sink) error {
+			err := s.Write(entry)
+			if err == nil {
+				if flusher, ok := s.(Flusher); ok {
+					err = flusher.Flush()
+				}
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%T: %w", s, err))
+			}
+			return nil
+		})
+	}})
+
+	select {
+	case <-done:
+	case <-time.After(flushTimeout):
+		return fmt.Errorf("timed out waiting for the log daemon to run the self-test")
+	}
+	return errors.Join(errs...)
+}
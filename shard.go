@@ -0,0 +1,167 @@
+package gologger
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+// shardEpoch is what the order of sharded entries is measured from, with its
+// monotonic reading.
+var shardEpoch = time.Now()
+
+// queueOrder is the order of an entry queued to a shard now: the monotonic
+// clock rather than a shared counter, which every logging goroutine would
+// contend for again. Within one shard, entries stay FIFO.
+func queueOrder() uint64 {
+	return uint64(time.Since(shardEpoch))
+}
+
+// SetShards spreads queued entries over n channels instead of one, so goroutines
+// logging at once on a many-core machine don't all contend for the same channel
+// lock. Each shard holds as many entries as the default channel. The daemon
+// merges the shards back in the order each goroutine logged its entries, unless
+// it falls so far behind that it has to write before it has emptied them, or
+// the entries were logged within the resolution of the monotonic clock.
+// The number of shards can only grow. Ordered delivery doesn't use them.
+func (l *Log) SetShards(n int) {
+	l = l.core()
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+
+	var shards []chan logMsg
+	if old := l.shards.Load(); old != nil {
+		shards = *old
+	}
+	if n <= len(shards) {
+		return
+	}
+
+	grown := make([]chan logMsg, 0, n)
+	grown = append(grown, shards...)
+	for len(grown) < n {
+		grown = append(grown, make(chan logMsg, cap(l.logChan)))
+	}
+	l.shards.Store(&grown)
+}
+
+// queueFor picks the channel an entry goes to, logChan when not sharded.
+func (l *Log) queueFor() chan logMsg {
+	shards := l.shards.Load()
+	if shards == nil {
+		return l.logChan
+	}
+	return (*shards)[rand.Intn(len(*shards))]
+}
+
+// queueLen is the number of entries queued and the room there is, over all
+// shards and the priority lane.
+func (l *Log) queueLen() (n, capacity int) {
+	n, capacity = len(l.logChan)+len(l.urgent), cap(l.logChan)+cap(l.urgent)
+	if shards := l.shards.Load(); shards != nil {
+		for _, ch := range *shards {
+			n += len(ch)
+			capacity += cap(ch)
+		}
+	}
+	return n, capacity
+}
+
+// wakeDaemon tells the daemon there is something in the shards.
+func (l *Log) wakeDaemon() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// drainShards takes what is queued in the shards, plus the entries in logChan,
+// logged Ordered or left from before sharding, up to the first control
+// message, which it returns. With ctrl already set it leaves logChan alone.
+//
+// A goroutine's entry is in its shard before the goroutine queues the next one,
+// so once the daemon holds an entry, one more pass over all shards picks up
+// every entry the same goroutine queued earlier. Passes go on until one finds
+// nothing, then inOrder restores each goroutine's order. Once it has a control
+// message, it takes everything the shards held then, past the batch limit, so
+// a Flush doesn't return before entries logged ahead of it are written.
+func (l *Log) drainShards(shards []chan logMsg, pending []logMsg, ctrl logMsg) ([]logMsg, logMsg) {
+	limit := maxBatch * len(shards)
+	var owed []int // per shard, entries queued before ctrl and not taken yet
+	for {
+		found := false
+
+	fromLogChan:
+		for !ctrl.isControl() {
+			select {
+			case m := <-l.logChan:
+				if m.isControl() {
+					ctrl = m
+					break fromLogChan
+				}
+				pending = append(pending, m)
+				found = true
+			default:
+				break fromLogChan
+			}
+		}
+
+		if ctrl.isControl() && owed == nil {
+			owed = make([]int, len(shards))
+			for i, ch := range shards {
+				owed[i] = len(ch)
+			}
+		}
+
+		for i, ch := range shards {
+			n := len(ch)
+			if owed != nil {
+				n = owed[i]
+			}
+			// DropOldest may take entries out too, don't block on a shard it just emptied
+		fromShard:
+			for ; n > 0; n-- {
+				select {
+				case m := <-ch:
+					pending = append(pending, m)
+					found = true
+				default:
+					break fromShard
+				}
+			}
+		}
+		// with owed, that one pass took all there is to take before ctrl
+		if owed != nil || !found || len(pending) >= limit {
+			break
+		}
+	}
+	return pending, ctrl
+}
+
+type orderKey struct {
+	order uint64
+	index int
+}
+
+// inOrder appends the entries of pending to batch sorted by when they were
+// queued. It sorts keys rather than moving whole messages around.
+func (l *Log) inOrder(batch []Entry, pending []logMsg) []Entry {
+	keys := l.orderKeys[:0]
+	for i, m := range pending {
+		keys = append(keys, orderKey{m.order, i})
+	}
+	// entries left in logChan from before sharding all have order 0, keep them FIFO
+	slices.SortFunc(keys, func(a, b orderKey) int {
+		if c := cmp.Compare(a.order, b.order); c != 0 {
+			return c
+		}
+		return a.index - b.index
+	})
+
+	for _, k := range keys {
+		batch = append(batch, pending[k.index].entry)
+	}
+	l.orderKeys = keys
+	return batch
+}
@@ -0,0 +1,69 @@
+package gologger
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestDrainShardsBeforeFlush(t *testing.T) {
+	l := newLog()
+	l.SetShards(2)
+	shards := *l.shards.Load()
+
+	// more than one batch, queued alternately so only the order merges them back
+	const n = 600
+	for i := 0; i < 2*n; i++ {
+		shards[i%2] <- logMsg{entry: Entry{Message: fmt.Sprint(i)}, order: queueOrder()}
+	}
+	l.logChan <- logMsg{flushed: make(chan struct{})}
+
+	pending, ctrl := l.drainShards(shards, nil, logMsg{})
+	if ctrl.flushed == nil {
+		t.Fatal("flush marker not returned")
+	}
+	if len(pending) != 2*n {
+		t.Fatalf("took %d entries before the flush marker, want %d", len(pending), 2*n)
+	}
+	for i, entry := range l.inOrder(nil, pending) {
+		if entry.Message != fmt.Sprint(i) {
+			t.Fatalf("entry %d is %s", i, entry.Message)
+		}
+	}
+}
+
+func TestShardsFlush(t *testing.T) {
+	l, sink := newTestLog(t)
+	l.SetShards(4)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				l.INF("%d %d", g, i)
+			}
+			l.Flush()
+		}()
+	}
+	wg.Wait()
+
+	// each goroutine's entries come out in the order it logged them
+	next := make(map[string]int)
+	for _, message := range sink.messages() {
+		var g, i int
+		fmt.Sscan(message, &g, &i)
+		key := fmt.Sprint(g)
+		if i != next[key] {
+			t.Fatalf("goroutine %d: entry %d after %d", g, i, next[key]-1)
+		}
+		next[key]++
+	}
+	for g := 0; g < 8; g++ {
+		if got := next[fmt.Sprint(g)]; got != 500 {
+			t.Errorf("goroutine %d: %d entries written by its Flush, want 500", g, got)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package gologger
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// OnShutdown registers f to run when Shutdown is called, before the logger
+// stops taking entries, so what f logs still gets written. Callbacks run in
+// the reverse order of registration, like defers, each at most once.
+func (l *Log) OnShutdown(f func()) {
+	l = l.core()
+	l.shutdownMu.Lock()
+	l.onShutdown = append(l.onShutdown, f)
+	l.shutdownMu.Unlock()
+}
+
+// runOnShutdown runs the OnShutdown callbacks, giving up on waiting for them when ctx is done.
+func (l *Log) runOnShutdown(ctx context.Context) error {
+	l.shutdownMu.Lock()
+	callbacks := l.onShutdown
+	l.onShutdown = nil
+	l.shutdownMu.Unlock()
+	if len(callbacks) == 0 {
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := len(callbacks) - 1; i >= 0; i-- {
+			callbacks[i]()
+		}
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ShutdownOnSignal calls Shutdown when one of sigs (SIGINT and SIGTERM if none
+// given) is received, allowing it timeout, then exits with the status a shell
+// gives a process killed by the signal, 130 for SIGINT. Call the returned
+// function to stop.
+func (l *Log) ShutdownOnSignal(timeout time.Duration, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	quit := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-c:
+			l.INF("Received %v, shutting down", sig)
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			err := l.Shutdown(ctx)
+			cancel()
+			if err != nil {
+				l.notice('E', "shutdown", Field{"error", err.Error()})
+			}
+			code := 1
+			if s, ok := sig.(syscall.Signal); ok {
+				code = 128 + int(s)
+			}
+			os.Exit(code)
+		case <-quit:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(quit)
+		})
+	}
+}
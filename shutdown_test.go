@@ -0,0 +1,27 @@
+package gologger
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdownSlowCallback(t *testing.T) {
+	l, sink := newTestLog(t)
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	l.OnShutdown(func() { <-release })
+	l.INF("queued")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+	err := l.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "OnShutdown") {
+		t.Errorf("Shutdown() = %v, want the callbacks timing out", err)
+	}
+	if got := strings.Join(sink.messages(), ","); got != "queued" {
+		t.Errorf("wrote %q, want the queued entry", got)
+	}
+}
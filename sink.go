@@ -0,0 +1,523 @@
+package gologger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Sink is a destination for log entries. Write and Close are only ever
+// called from the daemon goroutine, or one logging call at a time with SetInline.
+type Sink interface {
+	Write(entry Entry) error
+	Close() error
+}
+
+// Flusher is implemented by sinks that buffer writes.
+type Flusher interface {
+	Flush() error
+}
+
+// backgroundFlusher is implemented by sinks whose Flush waits on something
+// slow, like a remote service. The periodic flush after FlushInterval calls
+// startFlush instead, which doesn't wait, so one slow sink doesn't hold up
+// the others.
+type backgroundFlusher interface {
+	startFlush() error
+}
+
+// BatchWriter is implemented by sinks that write several entries at once
+// cheaper than one by one, e.g. with a single syscall. The daemon hands them
+// what has queued up while it was busy.
+type BatchWriter interface {
+	WriteBatch(entries []Entry) error
+}
+
+// Syncer is implemented by sinks that can commit writes to stable storage, done on FTL/PANIC.
+type Syncer interface {
+	Sync() error
+}
+
+// How long flushes wait for the daemon before giving up.
+const flushTimeout = 5 * time.Second
+
+// Flush blocks until everything logged so far is written and buffered sinks are flushed.
+func (l *Log) Flush() {
+	l.flush(false)
+}
+
+// flush blocks until every message queued before the call has been written,
+// with sync it also commits files to disk.
+func (l *Log) flush(sync bool) {
+	l = l.core()
+	done := make(chan struct{})
+	l.send(logMsg{flushed: done, sync: sync})
+
+	select {
+	case <-done:
+	case <-time.After(flushTimeout):
+	}
+}
+
+// Reopener is implemented by sinks holding files that external tools like logrotate move away.
+type Reopener interface {
+	Reopen() error
+}
+
+// AddSink registers s next to the built-in stdout/syslog/logfile sinks.
+func (l *Log) AddSink(s Sink) {
+	l = l.core()
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+
+	// copy on write, the daemon iterates over its own snapshot
+	sinks := make([]Sink, 0, len(l.sinks)+1)
+	sinks = append(sinks, l.sinks...)
+	l.sinks = append(sinks, s)
+}
+
+// RemoveSink unregisters s. Once it returns the daemon no longer uses s,
+// closing it is up to the caller.
+func (l *Log) RemoveSink(s Sink) {
+	l = l.core()
+
+	// entries logged before the call still reach s
+	l.flush(false)
+
+	l.sinksMu.Lock()
+	sinks := make([]Sink, 0, len(l.sinks))
+	for _, existing := range l.sinks {
+		if existing != s {
+			sinks = append(sinks, existing)
+		}
+	}
+	l.sinks = sinks
+	l.sinksMu.Unlock()
+
+	// wait for a write with the old snapshot to finish
+	l.flush(false)
+}
+
+func (l *Log) userSinks() []Sink {
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+	return l.sinks
+}
+
+// forEachSink calls fn for every enabled sink, stopping at the first error.
+func (l *Log) forEachSink(fn func(s Sink) error) error {
+	if l.SendToStdout {
+		err := fn(l.stdoutSink)
+		if err != nil {
+			return err
+		}
+	}
+	if l.SendToStderr {
+		err := fn(l.stderrSink)
+		if err != nil {
+			return err
+		}
+	}
+	if l.SendToSyslog {
+		err := fn(l.syslogSink)
+		if err != nil {
+			return err
+		}
+	}
+	if l.SendToLogfile {
+		err := fn(l.fileSink)
+		if err != nil {
+			return err
+		}
+	}
+	for _, s := range l.userSinks() {
+		err := fn(s)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSinks hands entries to every sink, with a single WriteBatch where the
+// sink supports it, and returns the errors per entry, nil if all writes succeeded.
+func (l *Log) writeSinks(entries []Entry) []error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	routed := false
+	for _, entry := range entries {
+		if entry.to != nil {
+			routed = true
+			break
+		}
+	}
+
+	var errs []error
+	var written []Sink
+	_ = l.forEachSink(func(s Sink) error {
+		if routed {
+			written = append(written, s)
+		}
+		errs = l.writeSink(s, entries, routed, true, errs)
+		return nil
+	})
+
+	// sinks entries were sent to with Call.To that aren't enabled
+	if routed {
+		for _, entry := range entries {
+			for _, t := range entry.to {
+				s := l.resolveSink(t)
+				if !slices.Contains(written, s) {
+					written = append(written, s)
+					errs = l.writeSink(s, entries, routed, false, errs)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// writeSink hands s the entries selected for it, returning errs with its failures added.
+func (l *Log) writeSink(s Sink, entries []Entry, routed, enabled bool, errs []error) []error {
+	selected, index := l.selectFor(s, entries, routed, enabled)
+
+	if bw, ok := s.(BatchWriter); ok && len(selected) > 1 {
+		err := bw.WriteBatch(selected)
+		if err != nil {
+			for j, entry := range selected {
+				errs = l.writeFailed(errs, len(entries), index, j, s, err, entry)
+			}
+		}
+		return errs
+	}
+
+	for j, entry := range selected {
+		err := s.Write(entry)
+		if err != nil {
+			errs = l.writeFailed(errs, len(entries), index, j, s, err, entry)
+		}
+	}
+	return errs
+}
+
+// selectFor returns the entries for s, by the level tags of a built-in sink
+// and the destinations of routed entries, and their positions in entries, nil
+// positions if that is all of them. A sink that isn't enabled gets just the
+// entries routed to it. Reuses the daemon's scratch space.
+func (l *Log) selectFor(s Sink, entries []Entry, routed, enabled bool) ([]Entry, []int) {
+	levels := l.builtinLevels(s)
+	if len(levels) == 0 && !routed {
+		return entries, nil
+	}
+
+	l.selected, l.selectedIndex = l.selected[:0], l.selectedIndex[:0]
+	for i, entry := range entries {
+		if entry.to != nil && l.routedTo(entry.to, s) || entry.to == nil && enabled && levelIn(entry.Level, levels) {
+			l.selected = append(l.selected, entry)
+			l.selectedIndex = append(l.selectedIndex, i)
+		}
+	}
+	return l.selected, l.selectedIndex
+}
+
+// writeFailed reports a failed write and records err for the entry at
+// position j of the selection given by index.
+func (l *Log) writeFailed(errs []error, n int, index []int, j int, s Sink, err error, entry Entry) []error {
+	l.sinkFailed(s, err, entry)
+
+	if errs == nil {
+		errs = make([]error, n)
+	}
+	i := j
+	if index != nil {
+		i = index[j]
+	}
+	errs[i] = errors.Join(errs[i], err)
+	return errs
+}
+
+// builtinLevels returns the level mask configured for s if it is one of the built-in sinks.
+func (l *Log) builtinLevels(s Sink) []byte {
+	switch s {
+	case l.stdoutSink:
+		if len(l.StdoutLevels) == 0 && l.SendToStderr {
+			return stdoutOnlyLevels
+		}
+		return l.StdoutLevels
+	case l.stderrSink:
+		if len(l.StderrLevels) == 0 {
+			return stderrDefaultLevels
+		}
+		return l.StderrLevels
+	case l.syslogSink:
+		return l.SyslogLevels
+	case l.fileSink:
+		return l.LogfileLevels
+	}
+	return nil
+}
+
+// With SendToStderr problems go to stderr and the rest to stdout, as container platforms expect.
+var (
+	stderrDefaultLevels = []byte{'F', 'E', 'W'}
+	stdoutOnlyLevels    = []byte{'I', 'D', 'T'}
+)
+
+// levelIn reports whether level is in the mask, an empty mask allowing all.
+// 'F' covers PANIC's 'P' as well, the built-in tags registered levels of
+// the same priority.
+func levelIn(level byte, levels []byte) bool {
+	if len(levels) == 0 {
+		return true
+	}
+	if level == 'P' {
+		level = 'F'
+	}
+	standard := StandardLevel(level)
+	for _, allowed := range levels {
+		if allowed == level || allowed == standard {
+			return true
+		}
+	}
+	return false
+}
+
+// LeveledSink passes the entries at Levels on to Sink, e.g. to keep an error
+// stream apart from debug output. Levels must not change once it is added.
+type LeveledSink struct {
+	Sink
+	Levels []byte
+
+	selected []Entry
+}
+
+func (s *LeveledSink) Write(entry Entry) error {
+	if !levelIn(entry.Level, s.Levels) {
+		return nil
+	}
+	return s.Sink.Write(entry)
+}
+
+func (s *LeveledSink) WriteBatch(entries []Entry) error {
+	s.selected = s.selected[:0]
+	for _, entry := range entries {
+		if levelIn(entry.Level, s.Levels) {
+			s.selected = append(s.selected, entry)
+		}
+	}
+
+	if bw, ok := s.Sink.(BatchWriter); ok {
+		return bw.WriteBatch(s.selected)
+	}
+	var errs []error
+	for _, entry := range s.selected {
+		err := s.Sink.Write(entry)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *LeveledSink) Flush() error {
+	if flusher, ok := s.Sink.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+func (s *LeveledSink) startFlush() error {
+	return startFlush(s.Sink)
+}
+
+func (s *LeveledSink) Sync() error {
+	if syncer, ok := s.Sink.(Syncer); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+func (s *LeveledSink) Reopen() error {
+	if reopener, ok := s.Sink.(Reopener); ok {
+		return reopener.Reopen()
+	}
+	return nil
+}
+
+// flushSinks flushes the buffered sinks. Without wait, those flushing in the
+// background only start to.
+func (l *Log) flushSinks(wait bool) {
+	_ = l.forEachSink(func(s Sink) error {
+		var err error
+		if wait {
+			if flusher, ok := s.(Flusher); ok {
+				err = flusher.Flush()
+			}
+		} else {
+			err = startFlush(s)
+		}
+		if err != nil {
+			l.sinkFailed(s, fmt.Errorf("flushing: %w", err), Entry{})
+		}
+		return nil
+	})
+}
+
+// startFlush flushes s, in the background if it can.
+func startFlush(s Sink) error {
+	if bf, ok := s.(backgroundFlusher); ok {
+		return bf.startFlush()
+	}
+	if flusher, ok := s.(Flusher); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+func (l *Log) syncSinks() {
+	_ = l.forEachSink(func(s Sink) error {
+		if syncer, ok := s.(Syncer); ok {
+			err := syncer.Sync()
+			if err != nil {
+				l.sinkFailed(s, fmt.Errorf("syncing: %w", err), Entry{})
+			}
+		}
+		return nil
+	})
+}
+
+// OnError registers fn to be called from the daemon whenever a sink fails.
+// entry is the zero Entry when the failure was not a write, e.g. a flush.
+func (l *Log) OnError(fn func(err error, entry Entry)) {
+	l = l.core()
+	l.sinksMu.Lock()
+	defer l.sinksMu.Unlock()
+	l.onError = fn
+}
+
+// sinkFailed reports err and hands the entry to FallbackSink, the daemon keeps going either way.
+func (l *Log) sinkFailed(s Sink, err error, entry Entry) {
+	l.counters.sinkErrors.Add(1)
+
+	l.sinksMu.Lock()
+	onError := l.onError
+	l.sinksMu.Unlock()
+
+	if onError != nil {
+		onError(err, entry)
+	}
+
+	fallback := l.FallbackSink
+	if fallback == nil || fallback == s {
+		return
+	}
+	if entry.Time.IsZero() {
+		entry = Entry{
+			Time:    l.now(),
+			Level:   'E',
+			Func:    "gologger",
+			Message: "sink error",
+		}
+	}
+	entry.Fields = append(entry.Fields[:len(entry.Fields):len(entry.Fields)], Field{"sink_error", err.Error()})
+	_ = fallback.Write(entry)
+}
+
+type writerSink struct {
+	w       io.Writer
+	encoder Encoder
+	buf     []byte
+}
+
+// NewWriterSink returns a sink writing entries encoded with encoder to w,
+// TextEncoder if encoder is nil.
+func NewWriterSink(w io.Writer, encoder Encoder) Sink {
+	if encoder == nil {
+		encoder = TextEncoder{}
+	}
+	return &writerSink{w: w, encoder: encoder}
+}
+
+func (s *writerSink) Write(entry Entry) error {
+	s.buf = s.encoder.Encode(s.buf[:0], entry)
+	_, err := s.w.Write(s.buf)
+	return err
+}
+
+func (s *writerSink) WriteBatch(entries []Entry) error {
+	s.buf = s.buf[:0]
+	for _, entry := range entries {
+		s.buf = s.encoder.Encode(s.buf, entry)
+	}
+	_, err := s.w.Write(s.buf)
+	return err
+}
+
+func (s *writerSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok && s.w != os.Stdout && s.w != os.Stderr {
+		return c.Close()
+	}
+	return nil
+}
+
+// consoleSink writes to stdout or stderr, as JSON in MachineMode, with
+// StdoutEncoder if set, else the ConsoleEncoder when PrettyConsole is set and
+// the stream is a terminal.
+type consoleSink struct {
+	l    *Log
+	file *os.File
+
+	ttyOnce sync.Once // on the first write, not on import
+	tty     bool
+	console *ConsoleEncoder
+	buf     []byte
+}
+
+func newConsoleSink(l *Log, file *os.File) *consoleSink {
+	return &consoleSink{
+		l:       l,
+		file:    file,
+		console: &ConsoleEncoder{CallerWidth: 24},
+	}
+}
+
+func (s *consoleSink) Write(entry Entry) error {
+	return s.WriteBatch([]Entry{entry})
+}
+
+func (s *consoleSink) WriteBatch(entries []Entry) error {
+	var encoder Encoder = TextEncoder{TimeFormat: s.l.TimeFormat, UTC: s.l.TimeUTC, Multiline: s.l.Multiline}
+	if s.l.machine.Load() {
+		encoder = JSONEncoder{}
+	} else if s.l.StdoutEncoder != nil {
+		encoder = s.l.StdoutEncoder
+	} else if s.l.PrettyConsole && s.colored() {
+		s.console.TimeFormat, s.console.UTC, s.console.Multiline = s.l.TimeFormat, s.l.TimeUTC, s.l.Multiline
+		encoder = s.console
+	}
+
+	s.buf = s.buf[:0]
+	for _, entry := range entries {
+		s.buf = encoder.Encode(s.buf, entry)
+	}
+	_, err := s.file.Write(s.buf)
+	return err
+}
+
+func (s *consoleSink) colored() bool {
+	s.ttyOnce.Do(func() {
+		_, noColor := os.LookupEnv("NO_COLOR")
+		s.tty = !noColor && enableColor(s.file)
+	})
+	return s.tty
+}
+
+func (s *consoleSink) Close() error {
+	return nil
+}
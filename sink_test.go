@@ -0,0 +1,92 @@
+package gologger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memSink keeps the entries written to it.
+type memSink struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (s *memSink) Write(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memSink) Close() error {
+	return nil
+}
+
+// messages returns the messages written so far, oldest first.
+func (s *memSink) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := make([]string, len(s.entries))
+	for i, entry := range s.entries {
+		messages[i] = entry.Message
+	}
+	return messages
+}
+
+// newTestLog returns a logger of its own, writing only to the sink returned.
+func newTestLog(t *testing.T) (*Log, *memSink) {
+	t.Helper()
+	l := newLog()
+	l.SendToStdout = false
+	s := &memSink{}
+	l.AddSink(s)
+	return l, s
+}
+
+func TestTee(t *testing.T) {
+	l, _ := newTestLog(t)
+	a, b := &memSink{}, &memSink{}
+	tee := Tee(a, b)
+
+	l.AddSink(tee)
+	l.INF("both")
+	l.RemoveSink(tee)
+	l.INF("neither")
+	l.To(tee).Msg("routed")
+	l.Flush()
+
+	for _, s := range []*memSink{a, b} {
+		if got := strings.Join(s.messages(), ","); got != "both,routed" {
+			t.Errorf("tee wrote %s, want both,routed", got)
+		}
+	}
+}
+
+// maskHook masks every field value in place.
+type maskHook struct{}
+
+func (maskHook) Before(entry *Entry) bool {
+	for i := range entry.Fields {
+		entry.Fields[i].Value = "***"
+	}
+	return true
+}
+
+func (maskHook) After(entry Entry, err error) {}
+
+func TestBeforeHookFieldsOwn(t *testing.T) {
+	l, sink := newTestLog(t)
+	l.AddHook(maskHook{})
+	child := l.With("user", "ann")
+	child.INF("first")
+	l.RemoveHook(maskHook{})
+	child.INF("second")
+	l.Flush()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if got := sink.entries[1].Fields[0].Value; got != "ann" {
+		t.Errorf("a hook changed the logger's field to %v", got)
+	}
+}
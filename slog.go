@@ -0,0 +1,99 @@
+package gologger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+type slogHandler struct {
+	l      *Log
+	attrs  []Field
+	prefix string // open groups, joined with dots
+}
+
+// NewSlogHandler returns a slog.Handler that routes records through l's daemon and sinks.
+func NewSlogHandler(l *Log) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+func slogLevelToLevel(level slog.Level) (byte, Priority) {
+	switch {
+	case level >= slog.LevelError:
+		return 'E', LOG_ERR
+	case level >= slog.LevelWarn:
+		return 'W', LOG_WARNING
+	case level >= slog.LevelInfo:
+		return 'I', LOG_INFO
+	case level >= slog.LevelDebug:
+		return 'D', LOG_DEBUG
+	default:
+		return 'T', LOG_TRACE
+	}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	_, priority := slogLevelToLevel(level)
+	return h.l.enabled(priority)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level, _ := slogLevelToLevel(r.Level)
+
+	var pc uintptr
+	var line int
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		pc, line = frame.PC, frame.Line
+	}
+
+	fields := make([]Field, 0, len(h.attrs)+r.NumAttrs())
+	fields = append(fields, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, a)
+		return true
+	})
+
+	h.l.WithContext(ctx).output(pc, line, level, r.Message, fields)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = make([]Field, 0, len(h.attrs)+len(attrs))
+	c.attrs = append(c.attrs, h.attrs...)
+	for _, a := range attrs {
+		c.attrs = appendAttr(c.attrs, h.prefix, a)
+	}
+	return &c
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.prefix = h.prefix + name + "."
+	return &c
+}
+
+// appendAttr flattens groups into dotted keys, e.g. req.method=GET
+func appendAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, groupPrefix, ga)
+		}
+		return fields
+	}
+
+	return append(fields, Field{prefix + a.Key, a.Value.Any()})
+}
@@ -0,0 +1,111 @@
+package gologger
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Files of a split logfile kept open at most. Beyond, the least recently
+// written one is closed, to be reopened when it gets entries again.
+const maxOpenLogfileParts = 64
+
+// logfilePart is what goes to one file of a split logfile: LogfileName with
+// {level} or {logger} keeps a file per level name, e.g. "error", or per named
+// logger, "root" for the root logger. The files share everything else,
+// including their rotation and retention.
+type logfilePart struct {
+	level, logger string
+}
+
+// splitsLogfile reports whether the LogfileName template splits the logfile.
+func splitsLogfile(template string) bool {
+	return strings.Contains(template, "{level}") || strings.Contains(template, "{logger}")
+}
+
+// partOf returns the part of the logfile named by template entry goes to.
+func partOf(template string, entry Entry) logfilePart {
+	var p logfilePart
+	if strings.Contains(template, "{level}") {
+		p.level = levelName(entry.Level)
+	}
+	if strings.Contains(template, "{logger}") {
+		p.logger = "root"
+		if entry.Logger != "" {
+			// named loggers like "net/http" stay in the directory
+			p.logger = strings.NewReplacer("/", "_", `\`, "_").Replace(entry.Logger)
+		}
+	}
+	return p
+}
+
+// expand fills in the {level} and {logger} placeholders of template, which
+// a nil p leaves alone.
+func (p *logfilePart) expand(template string) string {
+	if p == nil {
+		return template
+	}
+	return strings.NewReplacer("{level}", p.level, "{logger}", p.logger).Replace(template)
+}
+
+// writeSplit writes each of entries to the file of its part, opening them
+// as needed.
+func (s *fileSink) writeSplit(entries []Entry) error {
+	var order []logfilePart
+	grouped := map[logfilePart][]Entry{}
+	for _, entry := range entries {
+		p := partOf(s.l.LogfileName, entry)
+		if _, ok := grouped[p]; !ok {
+			order = append(order, p)
+		}
+		grouped[p] = append(grouped[p], entry)
+	}
+
+	if s.parts == nil {
+		s.parts = make(map[logfilePart]*fileSink)
+	}
+	var errs []error
+	now := time.Now()
+	for _, p := range order {
+		part, ok := s.parts[p]
+		if !ok {
+			if len(s.parts) >= maxOpenLogfileParts {
+				errs = append(errs, s.closeLeastUsedPart())
+			}
+			p := p
+			part = &fileSink{l: s.l, part: &p}
+			s.parts[p] = part
+		}
+		part.used = now
+		err := part.WriteBatch(grouped[p])
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeLeastUsedPart closes the file of the part written the longest ago.
+func (s *fileSink) closeLeastUsedPart() error {
+	var oldest *fileSink
+	var key logfilePart
+	for p, part := range s.parts {
+		if oldest == nil || part.used.Before(oldest.used) {
+			oldest, key = part, p
+		}
+	}
+	delete(s.parts, key)
+	return oldest.Close()
+}
+
+// eachPart calls fn for the file of each part, returning their errors.
+func (s *fileSink) eachPart(fn func(*fileSink) error) error {
+	var errs []error
+	for _, part := range s.parts {
+		err := fn(part)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,75 @@
+package gologger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogfilePart(t *testing.T) {
+	tests := []struct {
+		template string
+		entry    Entry
+		want     string
+	}{
+		{"app_{level}_{date}.log", Entry{Level: 'E'}, "app_error_{date}.log"},
+		{"app_{logger}.log", Entry{Level: 'E'}, "app_root.log"},
+		{"app_{logger}.log", Entry{Level: 'D', Logger: "net/http"}, "app_net_http.log"},
+		{"{logger}/{level}.log", Entry{Level: 'W', Logger: "db"}, "db/warning.log"},
+	}
+	for _, tt := range tests {
+		if !splitsLogfile(tt.template) {
+			t.Errorf("%q doesn't split", tt.template)
+		}
+		p := partOf(tt.template, tt.entry)
+		if got := p.expand(tt.template); got != tt.want {
+			t.Errorf("%q for %c %q: %q, want %q", tt.template, tt.entry.Level, tt.entry.Logger, got, tt.want)
+		}
+	}
+	if splitsLogfile(defaultLogfileName) {
+		t.Error("the default logfile name splits")
+	}
+}
+
+func TestSplitLogfileWrite(t *testing.T) {
+	l := newLog()
+	l.LogfileDir = t.TempDir()
+	l.LogfileName = "{logger}/{level}.log"
+	s := &fileSink{l: l}
+
+	entries := []Entry{
+		{Level: 'W', Logger: "db", Message: "slow query"},
+		{Level: 'E', Logger: "db", Message: "connection lost"},
+		{Level: 'I', Message: "started"},
+	}
+	for i := 0; i < maxOpenLogfileParts+2; i++ {
+		entries = append(entries, Entry{Level: 'I', Logger: fmt.Sprintf("worker%d", i), Message: "done"})
+	}
+	if err := s.WriteBatch(entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.parts) > maxOpenLogfileParts {
+		t.Errorf("%d parts open, want at most %d", len(s.parts), maxOpenLogfileParts)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"db/warning.log":   "slow query",
+		"db/error.log":     "connection lost",
+		"root/info.log":    "started",
+		"worker0/info.log": "done",
+	} {
+		data, err := os.ReadFile(filepath.Join(l.LogfileDir, name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s is %q, want %q in it", name, data, want)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package gologger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// spool is the persistent queue behind BatchOptions.SpoolDir: entries whose
+// batch could not be sent, appended as checksummed records to segment files
+// and read back in order from a cursor. Only the batcher goroutine uses it.
+type spool struct {
+	dir      string
+	segSize  int64
+	maxBytes int64
+
+	w     *os.File // segment being appended to, nil until the first append
+	wName string
+	wSize int64
+
+	readSeg string // segment and offset of the next record to send
+	readOff int64
+
+	cacheSeg  string // segment last read by next and its contents
+	cacheData []byte
+
+	dropped int64 // bytes of segments removed for maxBytes since the last report
+	skipped int64 // corrupt bytes skipped since the last report
+}
+
+// spoolPos is where reading stopped, committed once the entries read are sent.
+type spoolPos struct {
+	seg string
+	off int64
+}
+
+const (
+	spoolSegmentSize = 16 << 20
+	spoolMaxBytes    = 1 << 30
+
+	// a record is magic, length and CRC-32C of the payload, then the payload
+	spoolMagic  = 0xa55a
+	spoolHeader = 10
+)
+
+var spoolCRC = crc32.MakeTable(crc32.Castagnoli)
+
+func openSpool(dir string, segSize, maxBytes int64) (*spool, error) {
+	if segSize <= 0 {
+		segSize = spoolSegmentSize
+	}
+	if maxBytes <= 0 {
+		maxBytes = spoolMaxBytes
+	}
+	// several segments, so trimming the oldest keeps most of what fits
+	segSize = max(min(segSize, maxBytes/4), 1)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("creating spool directory: %w", err)
+	}
+
+	s := &spool{dir: dir, segSize: segSize, maxBytes: maxBytes}
+	b, err := os.ReadFile(filepath.Join(dir, "cursor"))
+	if err == nil {
+		seg, off, _ := strings.Cut(strings.TrimSpace(string(b)), " ")
+		s.readSeg = seg
+		s.readOff, _ = strconv.ParseInt(off, 10, 64)
+	}
+	return s, nil
+}
+
+// segments lists the segment files, oldest first as ReadDir sorts by name.
+func (s *spool) segments() []string {
+	files, _ := os.ReadDir(s.dir)
+	var names []string
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".seg") {
+			names = append(names, f.Name())
+		}
+	}
+	return names
+}
+
+// empty reports whether every spooled entry was sent.
+func (s *spool) empty() bool {
+	for _, seg := range s.segments() {
+		if seg > s.readSeg {
+			return false
+		}
+		if seg == s.readSeg {
+			fi, err := os.Stat(filepath.Join(s.dir, seg))
+			if err == nil && fi.Size() > s.readOff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// append writes entries to the current segment, starting a new one when it
+// is full. Segments of an earlier run are never appended to, a torn record
+// at their end is skipped when reading.
+func (s *spool) append(entries []Entry) error {
+	var buf []byte
+	for _, entry := range entries {
+		buf = appendSpoolRecord(buf, entry)
+	}
+
+	if s.w == nil || s.wSize >= s.segSize {
+		if s.w != nil {
+			s.w.Close()
+		}
+		s.wName = fmt.Sprintf("%020d.seg", time.Now().UnixNano())
+		w, err := os.OpenFile(filepath.Join(s.dir, s.wName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			s.w = nil
+			return fmt.Errorf("creating spool segment: %w", err)
+		}
+		s.w, s.wSize = w, 0
+	}
+
+	n, err := s.w.Write(buf)
+	s.wSize += int64(n)
+	if err != nil {
+		return fmt.Errorf("writing spool: %w", err)
+	}
+	s.trim()
+	return nil
+}
+
+// trim removes the oldest segments beyond maxBytes, always keeping the one written to.
+func (s *spool) trim() {
+	segs := s.segments()
+	sizes := make([]int64, len(segs))
+	var total int64
+	for i, seg := range segs {
+		if fi, err := os.Stat(filepath.Join(s.dir, seg)); err == nil {
+			sizes[i] = fi.Size()
+			total += sizes[i]
+		}
+	}
+	for i := 0; i < len(segs) && total > s.maxBytes && segs[i] != s.wName; i++ {
+		os.Remove(filepath.Join(s.dir, segs[i]))
+		total -= sizes[i]
+		s.dropped += sizes[i]
+		if segs[i] == s.readSeg {
+			s.readSeg, s.readOff = "", 0
+		}
+	}
+}
+
+// next reads up to n entries from the cursor on, skipping corrupt records.
+func (s *spool) next(n int) ([]Entry, spoolPos) {
+	var entries []Entry
+	pos := spoolPos{s.readSeg, s.readOff}
+
+	for _, seg := range s.segments() {
+		if seg < pos.seg {
+			continue
+		}
+		if seg > pos.seg {
+			pos = spoolPos{seg, 0}
+		}
+		if s.cacheSeg != seg || int64(len(s.cacheData)) <= pos.off {
+			data, err := os.ReadFile(filepath.Join(s.dir, seg))
+			if err != nil {
+				continue
+			}
+			s.cacheSeg, s.cacheData = seg, data
+		}
+		data := s.cacheData
+
+		for pos.off < int64(len(data)) && len(entries) < n {
+			entry, size, ok := decodeSpoolRecord(data[pos.off:])
+			if !ok {
+				skip := resyncSpool(data[pos.off:])
+				s.skipped += skip
+				pos.off += skip
+				continue
+			}
+			entries = append(entries, entry)
+			pos.off += size
+		}
+		if len(entries) >= n {
+			break
+		}
+	}
+	return entries, pos
+}
+
+// commit moves the cursor to pos, removing the segments read completely.
+func (s *spool) commit(pos spoolPos) {
+	for _, seg := range s.segments() {
+		if seg >= pos.seg {
+			break
+		}
+		os.Remove(filepath.Join(s.dir, seg))
+	}
+	s.readSeg, s.readOff = pos.seg, pos.off
+
+	// remove the segment once read too, unless more is coming
+	if pos.seg != "" && pos.seg != s.wName {
+		fi, err := os.Stat(filepath.Join(s.dir, pos.seg))
+		if err == nil && pos.off >= fi.Size() {
+			os.Remove(filepath.Join(s.dir, pos.seg))
+			s.readSeg, s.readOff, s.cacheSeg, s.cacheData = "", 0, "", nil
+		}
+	}
+	// everything sent, start over with an empty directory
+	if s.w != nil && pos.seg == s.wName && pos.off >= s.wSize {
+		s.w.Close()
+		os.Remove(filepath.Join(s.dir, s.wName))
+		s.w, s.wName, s.wSize = nil, "", 0
+		s.readSeg, s.readOff, s.cacheSeg, s.cacheData = "", 0, "", nil
+	}
+
+	path := filepath.Join(s.dir, "cursor")
+	err := os.WriteFile(path+".tmp", []byte(s.readSeg+" "+strconv.FormatInt(s.readOff, 10)+"\n"), 0600)
+	if err == nil {
+		os.Rename(path+".tmp", path)
+	}
+}
+
+// takeErr reports the entries lost to maxBytes or corruption since the last call.
+func (s *spool) takeErr() error {
+	var errs []error
+	if s.dropped > 0 {
+		errs = append(errs, fmt.Errorf("spool full, dropped %d bytes of the oldest entries", s.dropped))
+	}
+	if s.skipped > 0 {
+		errs = append(errs, fmt.Errorf("spool corrupt, skipped %d bytes", s.skipped))
+	}
+	s.dropped, s.skipped = 0, 0
+	return errors.Join(errs...)
+}
+
+func (s *spool) Close() error {
+	if s.w == nil {
+		return nil
+	}
+	return s.w.Close()
+}
+
+type spoolRecord struct {
+	Seq     uint64             `json:"seq,omitempty"`
+	Time    time.Time          `json:"ts"`
+	Level   byte               `json:"lvl"`
+	Logger  string             `json:"logger,omitempty"`
+	Func    string             `json:"func,omitempty"`
+	File    string             `json:"file,omitempty"`
+	Line    int                `json:"line,omitempty"`
+	Message string             `json:"msg"`
+	Fields  []spoolRecordField `json:"fields,omitempty"`
+	Stack   string             `json:"stack,omitempty"`
+	Err     string             `json:"err,omitempty"`
+}
+
+type spoolRecordField struct {
+	Key   string          `json:"k"`
+	Value json.RawMessage `json:"v"`
+}
+
+// appendSpoolRecord appends entry as a record. Field values come back as
+// what JSON decodes them to, errors as their message.
+func appendSpoolRecord(buf []byte, entry Entry) []byte {
+	r := spoolRecord{
+		Seq: entry.Seq, Time: entry.Time, Level: entry.Level, Logger: entry.Logger,
+		Func: entry.Func, File: entry.File, Line: entry.Line, Message: entry.Message, Stack: entry.Stack,
+	}
+	if entry.Err != nil {
+		r.Err = entry.Err.Error()
+	}
+	for _, f := range entry.Fields {
+		r.Fields = append(r.Fields, spoolRecordField{f.Key, appendJSONValue(nil, f.Value)})
+	}
+	payload, _ := json.Marshal(r)
+
+	buf = binary.BigEndian.AppendUint16(buf, spoolMagic)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(payload)))
+	buf = binary.BigEndian.AppendUint32(buf, crc32.Checksum(payload, spoolCRC))
+	return append(buf, payload...)
+}
+
+// decodeSpoolRecord decodes the record at the start of b and returns its size.
+func decodeSpoolRecord(b []byte) (Entry, int64, bool) {
+	if len(b) < spoolHeader || binary.BigEndian.Uint16(b) != spoolMagic {
+		return Entry{}, 0, false
+	}
+	size := int64(binary.BigEndian.Uint32(b[2:]))
+	if size > int64(len(b)-spoolHeader) {
+		return Entry{}, 0, false
+	}
+	payload := b[spoolHeader : spoolHeader+size]
+	if crc32.Checksum(payload, spoolCRC) != binary.BigEndian.Uint32(b[6:]) {
+		return Entry{}, 0, false
+	}
+
+	var r spoolRecord
+	if json.Unmarshal(payload, &r) != nil {
+		return Entry{}, 0, false
+	}
+	entry := Entry{
+		Seq: r.Seq, Time: r.Time, Level: r.Level, Logger: r.Logger,
+		Func: r.Func, File: r.File, Line: r.Line, Message: r.Message, Stack: r.Stack,
+	}
+	if r.Err != "" {
+		entry.Err = errors.New(r.Err)
+	}
+	for _, f := range r.Fields {
+		var v interface{}
+		json.Unmarshal(f.Value, &v)
+		entry.Fields = append(entry.Fields, Field{f.Key, v})
+	}
+	return entry, spoolHeader + size, true
+}
+
+// resyncSpool finds the next valid record after a corrupt one, returning
+// how many bytes to skip, all of b if there is none.
+func resyncSpool(b []byte) int64 {
+	magic := binary.BigEndian.AppendUint16(nil, spoolMagic)
+	for i := 1; i < len(b); i++ {
+		j := bytes.Index(b[i:], magic)
+		if j < 0 {
+			break
+		}
+		i += j
+		if _, _, ok := decodeSpoolRecord(b[i:]); ok {
+			return int64(i)
+		}
+	}
+	return int64(len(b))
+}
@@ -0,0 +1,193 @@
+package gologger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func spoolEntries(from, to int) []Entry {
+	var entries []Entry
+	for i := from; i <= to; i++ {
+		entries = append(entries, Entry{Level: 'I', Message: fmt.Sprint("entry ", i), Fields: []Field{{"i", i}}})
+	}
+	return entries
+}
+
+func spoolMessages(entries []Entry) string {
+	var msgs []string
+	for _, e := range entries {
+		msgs = append(msgs, strings.TrimPrefix(e.Message, "entry "))
+	}
+	return strings.Join(msgs, ",")
+}
+
+func openTestSpool(t *testing.T, dir string, segSize, maxBytes int64) *spool {
+	t.Helper()
+	s, err := openSpool(dir, segSize, maxBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSpoolRoundTrip(t *testing.T) {
+	s := openTestSpool(t, t.TempDir(), 0, 0)
+	if !s.empty() {
+		t.Fatal("new spool not empty")
+	}
+	if err := s.append(spoolEntries(1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	entries, pos := s.next(10)
+	if got := spoolMessages(entries); got != "1,2,3" {
+		t.Fatalf("next = %s, want 1,2,3", got)
+	}
+	if v := entries[1].Fields[0].Value; v != float64(2) {
+		t.Errorf("field = %#v, want 2", v)
+	}
+	s.commit(pos)
+	if !s.empty() {
+		t.Error("spool not empty after committing everything")
+	}
+	if segs := s.segments(); len(segs) != 0 {
+		t.Errorf("segments left after committing everything: %v", segs)
+	}
+}
+
+func TestSpoolTornTail(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestSpool(t, dir, 0, 0)
+	if err := s.append(spoolEntries(1, 3)); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// a crash in the middle of writing the last record
+	path := filepath.Join(dir, s.wName)
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, fi.Size()-5); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openTestSpool(t, dir, 0, 0)
+	entries, pos := s.next(10)
+	if got := spoolMessages(entries); got != "1,2" {
+		t.Fatalf("next = %s, want 1,2", got)
+	}
+	if err := s.takeErr(); err == nil || !strings.Contains(err.Error(), "spool corrupt, skipped") {
+		t.Errorf("takeErr = %v, want the skipped bytes", err)
+	}
+	s.commit(pos)
+	if !s.empty() {
+		t.Error("spool not empty after committing past the torn record")
+	}
+}
+
+func TestSpoolCorruptRecord(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestSpool(t, dir, 0, 0)
+	if err := s.append(spoolEntries(1, 3)); err != nil {
+		t.Fatal(err)
+	}
+
+	// flip a payload byte of the second record, its CRC no longer matches
+	path := filepath.Join(dir, s.wName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, first, ok := decodeSpoolRecord(data)
+	if !ok {
+		t.Fatal("first record doesn't decode")
+	}
+	data[first+spoolHeader+5] ^= 0xff
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := s.next(10)
+	if got := spoolMessages(entries); got != "1,3" {
+		t.Fatalf("next = %s, want 1,3", got)
+	}
+	err = s.takeErr()
+	if err == nil || !strings.Contains(err.Error(), "spool corrupt, skipped") {
+		t.Errorf("takeErr = %v, want the skipped bytes", err)
+	}
+}
+
+func TestSpoolResumeFromCursor(t *testing.T) {
+	dir := t.TempDir()
+	s := openTestSpool(t, dir, 0, 0)
+	if err := s.append(spoolEntries(1, 5)); err != nil {
+		t.Fatal(err)
+	}
+	entries, pos := s.next(2)
+	if got := spoolMessages(entries); got != "1,2" {
+		t.Fatalf("next = %s, want 1,2", got)
+	}
+	s.commit(pos)
+
+	// read but not committed, sent again after the restart
+	s.next(1)
+	s.Close()
+
+	s = openTestSpool(t, dir, 0, 0)
+	if s.empty() {
+		t.Fatal("reopened spool empty")
+	}
+	entries, pos = s.next(10)
+	if got := spoolMessages(entries); got != "3,4,5" {
+		t.Fatalf("next after reopening = %s, want 3,4,5", got)
+	}
+	s.commit(pos)
+	if segs := s.segments(); len(segs) != 0 {
+		t.Errorf("segments of the earlier run left after committing them: %v", segs)
+	}
+}
+
+func TestSpoolTrim(t *testing.T) {
+	dir := t.TempDir()
+	record := int64(len(appendSpoolRecord(nil, spoolEntries(1, 1)[0])))
+	// two records per segment, at most four segments, a little more for the
+	// longer numbers
+	s := openTestSpool(t, dir, 2*record, 8*record+8)
+
+	for i := 1; i <= 4; i += 2 {
+		if err := s.append(spoolEntries(i, i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, pos := s.next(1)
+	if got := spoolMessages(entries); got != "1" {
+		t.Fatalf("next = %s, want 1", got)
+	}
+	s.commit(pos)
+	if err := s.takeErr(); err != nil {
+		t.Fatalf("takeErr before trimming = %v", err)
+	}
+
+	// six segments of two, the two oldest go, the one being read among them
+	for i := 5; i <= 12; i += 2 {
+		if err := s.append(spoolEntries(i, i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if segs := s.segments(); len(segs) != 4 {
+		t.Errorf("%d segments, want 4", len(segs))
+	}
+	if err := s.takeErr(); err == nil || !strings.Contains(err.Error(), "spool full, dropped") {
+		t.Errorf("takeErr = %v, want the dropped bytes", err)
+	}
+
+	entries, _ = s.next(100)
+	if got := spoolMessages(entries); got != "5,6,7,8,9,10,11,12" {
+		t.Errorf("next after trimming = %s, want 5 to 12", got)
+	}
+}
@@ -0,0 +1,221 @@
+package gologger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLOptions configures NewSQLSink, the zero value works for SQLite.
+type SQLOptions struct {
+	Table   string // "logs" if empty
+	Dialect string // "sqlite", "postgres" or "mysql", for the placeholders and column types. "sqlite" if empty
+
+	// Retention, checked at most once a minute after a batch is inserted.
+	// Zero keeps everything.
+	MaxAge  time.Duration // rows older than this are deleted
+	MaxRows int64         // the oldest rows beyond this are deleted
+
+	Batch BatchOptions
+}
+
+type sqlSink struct {
+	*batcher
+
+	db      *sql.DB
+	opts    SQLOptions
+	insert  string
+	pruned  time.Time
+	closeDB bool // opened for a config
+}
+
+// sqlTimeLayout sorts like the times it stores, so SQLite can compare and
+// index ts as text.
+const sqlTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// NewSQLSink returns a sink inserting entries into a table of db, created with
+// indexes on ts, level and logger if it doesn't exist, one transaction per
+// batch. The columns are id, ts, level, logger, caller, msg, msg_id, fields (a
+// JSON object) and stack, so the log can be searched with plain SQL, e.g.
+//
+//	SELECT ts, msg FROM logs WHERE level = 'error' AND ts > '2024-05-01' ORDER BY ts
+//
+// Register the driver by importing it, any database/sql driver works.
+func NewSQLSink(db *sql.DB, opts *SQLOptions) (Sink, error) {
+	s := &sqlSink{db: db}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Table == "" {
+		s.opts.Table = "logs"
+	}
+	if s.opts.Dialect == "" {
+		s.opts.Dialect = "sqlite"
+	}
+
+	var id, ts string
+	switch s.opts.Dialect {
+	case "sqlite":
+		id, ts = "INTEGER PRIMARY KEY AUTOINCREMENT", "TEXT"
+	case "postgres":
+		id, ts = "BIGSERIAL PRIMARY KEY", "TIMESTAMPTZ"
+	case "mysql":
+		id, ts = "BIGINT AUTO_INCREMENT PRIMARY KEY", "DATETIME(6)"
+	default:
+		return nil, fmt.Errorf("unknown SQL dialect %q, expected sqlite, postgres or mysql", s.opts.Dialect)
+	}
+	for _, c := range s.opts.Table {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.') {
+			return nil, fmt.Errorf("invalid SQL table name %q", s.opts.Table)
+		}
+	}
+
+	table := s.opts.Table
+	index := strings.ReplaceAll(table, ".", "_")
+	statements := []string{
+		"CREATE TABLE IF NOT EXISTS " + table + " (id " + id + ", ts " + ts + " NOT NULL, level VARCHAR(16) NOT NULL, " +
+			"logger VARCHAR(255), caller VARCHAR(255), msg TEXT, msg_id VARCHAR(255), fields TEXT, stack TEXT)",
+	}
+	for _, column := range []string{"ts", "level", "logger"} {
+		statement := "CREATE INDEX IF NOT EXISTS " + index + "_" + column + " ON " + table + " (" + column + ")"
+		if s.opts.Dialect == "mysql" {
+			// MySQL has no IF NOT EXISTS for indexes, an existing one fails below
+			statement = "CREATE INDEX " + index + "_" + column + " ON " + table + " (" + column + ")"
+		}
+		statements = append(statements, statement)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for i, statement := range statements {
+		_, err := db.ExecContext(ctx, statement)
+		if err != nil && !(s.opts.Dialect == "mysql" && i > 0 && strings.Contains(err.Error(), "Duplicate key name")) {
+			return nil, fmt.Errorf("creating log table: %w", err)
+		}
+	}
+
+	s.insert = "INSERT INTO " + table + " (ts, level, logger, caller, msg, msg_id, fields, stack) VALUES (" + s.placeholders(8) + ")"
+	s.batcher = newBatcher(s.opts.Batch, s.push)
+	return s, nil
+}
+
+// placeholders returns n comma separated parameters, numbered for postgres.
+func (s *sqlSink) placeholders(n int) string {
+	p := make([]string, n)
+	for i := range p {
+		p[i] = "?"
+		if s.opts.Dialect == "postgres" {
+			p[i] = "$" + strconv.Itoa(i+1)
+		}
+	}
+	return strings.Join(p, ", ")
+}
+
+func (s *sqlSink) push(entries []Entry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("inserting log entries: %w", err)
+	}
+	stmt, err := tx.PrepareContext(ctx, s.insert)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("inserting log entries: %w", err)
+	}
+	for _, entry := range entries {
+		_, err = stmt.ExecContext(ctx, s.row(entry)...)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("inserting log entries: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("inserting log entries: %w", err)
+	}
+
+	if (s.opts.MaxAge > 0 || s.opts.MaxRows > 0) && time.Since(s.pruned) >= time.Minute {
+		s.pruned = time.Now()
+		s.prune(ctx)
+	}
+	return nil
+}
+
+func (s *sqlSink) row(entry Entry) []interface{} {
+	var caller, fields interface{}
+	if entry.Func != "" {
+		caller = entry.Func + ":" + strconv.Itoa(entry.Line)
+	}
+	if len(entry.Fields) > 0 {
+		buf := []byte{'{'}
+		for i, f := range entry.Fields {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, f.Key)
+			buf = append(buf, ':')
+			buf = appendJSONValue(buf, f.Value)
+		}
+		fields = string(append(buf, '}'))
+	}
+	return []interface{}{s.time(entry.Time), levelName(entry.Level), sqlNull(entry.Logger), caller,
+		entry.Message, sqlNull(entry.MessageID), fields, sqlNull(entry.Stack)}
+}
+
+// time is t as stored in the ts column.
+func (s *sqlSink) time(t time.Time) interface{} {
+	if s.opts.Dialect == "sqlite" {
+		return t.UTC().Format(sqlTimeLayout)
+	}
+	return t.UTC()
+}
+
+// prune deletes what is past MaxAge or MaxRows. A failure is left for the next
+// time, the entries are in.
+func (s *sqlSink) prune(ctx context.Context) {
+	table := s.opts.Table
+	if s.opts.MaxAge > 0 {
+		_, _ = s.db.ExecContext(ctx, "DELETE FROM "+table+" WHERE ts < "+s.placeholders(1), s.time(time.Now().Add(-s.opts.MaxAge)))
+	}
+	if s.opts.MaxRows > 0 {
+		// the id of the newest row to delete, ids grow with every insert
+		var last sql.NullInt64
+		err := s.db.QueryRowContext(ctx, "SELECT id FROM "+table+" ORDER BY id DESC LIMIT 1 OFFSET "+strconv.FormatInt(s.opts.MaxRows, 10)).Scan(&last)
+		if err == nil && last.Valid {
+			_, _ = s.db.ExecContext(ctx, "DELETE FROM "+table+" WHERE id <= "+s.placeholders(1), last.Int64)
+		}
+	}
+}
+
+func (s *sqlSink) Close() error {
+	err := s.batcher.Close()
+	if s.closeDB {
+		if cerr := s.db.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// sqlDialect guesses the dialect of a driver by its registered name.
+func sqlDialect(driver string) string {
+	switch {
+	case strings.Contains(driver, "postgres"), driver == "pgx":
+		return "postgres"
+	case strings.Contains(driver, "mysql"):
+		return "mysql"
+	default:
+		return "sqlite"
+	}
+}
+
+// sqlNull is s, or NULL when empty.
+func sqlNull(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+package gologger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const defaultStackTraceDepth = 32
+
+// captureStack renders the goroutine's stack starting at the frame of pc,
+// in the same "func()\n\tfile:line\n" layout as runtime/debug.Stack.
+func captureStack(pc uintptr, depth int) string {
+	if depth <= 0 {
+		depth = defaultStackTraceDepth
+	}
+
+	// the frames between here and the caller depend on which API was used,
+	// so capture generously and cut at the caller's pc
+	pcs := make([]uintptr, depth+16)
+	n := runtime.Callers(2, pcs)
+	pcs = pcs[:n]
+	pcs = trimToCaller(pcs, pc)
+	if len(pcs) > depth {
+		pcs = pcs[:depth]
+	}
+	return formatStack(pcs)
+}
+
+// panicStack returns the stack of a panicking goroutine starting at the
+// frame that panicked, skipping the deferred calls and the runtime's own frames.
+func panicStack() []uintptr {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+
+	inPanic := false
+	for i, p := range pcs {
+		var name string
+		if fn := runtime.FuncForPC(p - 1); fn != nil {
+			name = fn.Name()
+		}
+		if name == "runtime.gopanic" {
+			inPanic = true
+		} else if inPanic && !strings.HasPrefix(name, "runtime.") {
+			return pcs[i:]
+		}
+	}
+	return pcs
+}
+
+func formatStack(pcs []uintptr) string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			sb.WriteString(frame.Function)
+			sb.WriteString("()\n\t")
+			sb.WriteString(frame.File)
+			sb.WriteByte(':')
+			sb.WriteString(strconv.Itoa(frame.Line))
+			sb.WriteByte('\n')
+		}
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// trimToCaller drops the frames above pc. pc may be a return address or, when it
+// came from runtime.Caller or CallersFrames, the address of the call instruction.
+func trimToCaller(pcs []uintptr, pc uintptr) []uintptr {
+	for i, p := range pcs {
+		if p == pc || p-1 == pc {
+			return pcs[i:]
+		}
+	}
+
+	// not found, e.g. pc was 0: fall back to dropping our own frames
+	for i, p := range pcs {
+		fn := runtime.FuncForPC(p - 1)
+		if fn == nil || funcPackage(fn.Name()) != packagePath {
+			return pcs[i:]
+		}
+	}
+	return pcs
+}
+
+// appendStack indents every line of the stack so it reads as part of the entry above it.
+func appendStack(buf []byte, stack string) []byte {
+	for _, line := range strings.SplitAfter(stack, "\n") {
+		if line == "" {
+			continue
+		}
+		buf = append(buf, "    "...)
+		buf = append(buf, line...)
+	}
+	return buf
+}
@@ -0,0 +1,103 @@
+package gologger
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// counters only exist on the root logger, children are kept small.
+type counters struct {
+	logged        [256]atomic.Uint64 // by level tag
+	dropped       atomic.Uint64
+	sampledOut    atomic.Uint64
+	rateLimited   atomic.Uint64
+	overBudget    atomic.Uint64
+	sinkErrors    atomic.Uint64
+	highWaterMark atomic.Int64
+	syslogDown    atomic.Bool
+	syslogHeld    atomic.Int64
+}
+
+type Stats struct {
+	Logged      map[byte]uint64 // entries queued for the sinks, per level tag
+	Dropped     uint64          // discarded because the channel was full
+	SampledOut  uint64          // discarded by sampling
+	RateLimited uint64          // discarded by CallerRateLimit
+	OverBudget  uint64          // discarded by SetBudget
+	SinkErrors  uint64          // failed sink writes, flushes and syncs
+
+	QueueLength   int // entries waiting for the daemon right now
+	QueueCapacity int
+	HighWaterMark int // the longest the queue has been
+
+	SyslogDown bool // syslog is unreachable, entries are held until it is back
+	SyslogHeld int  // entries held for syslog
+}
+
+func (l *Log) Stats() Stats {
+	l = l.core()
+	c := l.counters
+
+	logged := make(map[byte]uint64)
+	for level := range c.logged {
+		if n := c.logged[level].Load(); n > 0 {
+			logged[byte(level)] = n
+		}
+	}
+
+	queueLength, queueCapacity := l.queueLen()
+	return Stats{
+		Logged:        logged,
+		Dropped:       c.dropped.Load(),
+		SampledOut:    c.sampledOut.Load(),
+		RateLimited:   c.rateLimited.Load(),
+		OverBudget:    c.overBudget.Load(),
+		SinkErrors:    c.sinkErrors.Load(),
+		QueueLength:   queueLength,
+		QueueCapacity: queueCapacity,
+		HighWaterMark: int(c.highWaterMark.Load()),
+		SyslogDown:    c.syslogDown.Load(),
+		SyslogHeld:    int(c.syslogHeld.Load()),
+	}
+}
+
+// queued counts an entry that made it into the channel.
+func (l *Log) queued(level byte) {
+	c := l.counters
+	c.logged[level].Add(1)
+
+	queued, _ := l.queueLen()
+	n := int64(queued)
+	for {
+		hwm := c.highWaterMark.Load()
+		if n <= hwm || c.highWaterMark.CompareAndSwap(hwm, n) {
+			return
+		}
+	}
+}
+
+// PublishExpvar exposes Stats under name on /debug/vars. Like expvar.Publish
+// it panics if name is already in use.
+func (l *Log) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		s := l.Stats()
+
+		// expvar renders through encoding/json, which wants string keys
+		logged := make(map[string]uint64, len(s.Logged))
+		for level, n := range s.Logged {
+			logged[string(level)] = n
+		}
+		return map[string]interface{}{
+			"logged":         logged,
+			"dropped":        s.Dropped,
+			"sampled_out":    s.SampledOut,
+			"rate_limited":   s.RateLimited,
+			"sink_errors":    s.SinkErrors,
+			"queue_length":   s.QueueLength,
+			"queue_capacity": s.QueueCapacity,
+			"high_water":     s.HighWaterMark,
+			"syslog_down":    s.SyslogDown,
+			"syslog_held":    s.SyslogHeld,
+		}
+	}))
+}
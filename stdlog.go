@@ -0,0 +1,84 @@
+package gologger
+
+import (
+	"log"
+	"runtime"
+	"strings"
+)
+
+// StdLogger returns a *log.Logger for legacy code, logging each line at level
+// unless it starts with a level of its own, e.g. "ERROR: ..." or "[warn] ...".
+// Like the standard library's, it returns once the line is written.
+func (l *Log) StdLogger(level byte) *log.Logger {
+	return log.New(&levelWriter{l: l, level: level, stdlog: true}, "", 0)
+}
+
+// RedirectStdLog routes the output of the standard log package's functions
+// through l, at I unless a line says otherwise, each written before the call
+// returns so log.Fatal doesn't lose its message. Call restore to undo it.
+func RedirectStdLog(l *Log) (restore func()) {
+	flags, prefix, w := log.Flags(), log.Prefix(), log.Writer()
+
+	// l adds timestamp and caller itself
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(&levelWriter{l: l, level: 'I', stdlog: true})
+
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(w)
+	}
+}
+
+var stdlogLevels = []struct {
+	prefix string
+	level  byte
+}{
+	{"FATAL", 'F'},
+	{"ERROR", 'E'},
+	{"ERR", 'E'},
+	{"WARNING", 'W'},
+	{"WARN", 'W'},
+	{"INFO", 'I'},
+	{"DEBUG", 'D'},
+	{"TRACE", 'T'},
+}
+
+// detectLevel recognizes a leading level word like "ERROR:", "[WARN]" or
+// "info " and strips it, otherwise it returns def and text unchanged.
+func detectLevel(text string, def byte) (byte, string) {
+	rest := strings.TrimPrefix(text, "[")
+	for _, l := range stdlogLevels {
+		if len(rest) < len(l.prefix) || !strings.EqualFold(rest[:len(l.prefix)], l.prefix) {
+			continue
+		}
+		after := rest[len(l.prefix):]
+		if after != "" && !strings.ContainsRune(":] ", rune(after[0])) {
+			// a word like "INFORMATION"
+			continue
+		}
+		return l.level, strings.TrimLeft(after, ":] ")
+	}
+	return def, text
+}
+
+// stdlogCaller is the first caller outside the log package, e.g. of log.Printf.
+func (l *Log) stdlogCaller() (uintptr, int) {
+	if l.core().disableCaller.Load() {
+		return 0, 0
+	}
+
+	// skip runtime.Callers, stdlogCaller and levelWriter.Write
+	var pcs [8]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "log.") {
+			return frame.PC, frame.Line
+		}
+		if !more {
+			return 0, 0
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package gologger
+
+import "sync"
+
+// How many entries a subscriber may fall behind before it misses some.
+const subscriptionBuffer = 1000
+
+// subscription is the hook delivering entries to a Subscribe channel.
+type subscription struct {
+	filter Filter
+	ch     chan Entry
+
+	// RemoveHook may give up waiting for the daemon, which then still calls
+	// After, so ch is only closed under mu
+	mu     sync.Mutex
+	closed bool
+}
+
+func (s *subscription) Before(entry *Entry) bool {
+	return true
+}
+
+func (s *subscription) After(entry Entry, err error) {
+	if s.filter != nil && !s.filter(entry) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- entry:
+	default:
+		// a slow subscriber must not hold up the daemon
+	}
+}
+
+func (s *subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
+
+// Subscribe streams the entries written from now on that filter keeps, all if
+// nil, to an admin endpoint or a TUI in the same process. The channel buffers
+// 1000 entries, while it is full the subscriber misses new ones rather than
+// slowing down logging. Call cancel when done, which closes the channel.
+func (l *Log) Subscribe(filter Filter) (<-chan Entry, func()) {
+	s := &subscription{filter: filter, ch: make(chan Entry, subscriptionBuffer)}
+	l.AddHook(s)
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			l.RemoveHook(s)
+			s.close()
+		})
+	}
+	return s.ch, cancel
+}
@@ -0,0 +1,30 @@
+package gologger
+
+import "testing"
+
+func TestSubscribe(t *testing.T) {
+	l, _ := newTestLog(t)
+	ch, cancel := l.Subscribe(func(entry Entry) bool { return entry.Level == 'W' })
+	l.INF("skipped")
+	l.WRN("kept")
+	l.Flush()
+	cancel()
+
+	var got []string
+	for entry := range ch {
+		got = append(got, entry.Message)
+	}
+	if len(got) != 1 || got[0] != "kept" {
+		t.Errorf("received %v, want [kept]", got)
+	}
+}
+
+func TestSubscriptionAfterClose(t *testing.T) {
+	s := &subscription{ch: make(chan Entry, 1)}
+	s.close()
+	// as the daemon may, when cancel gave up waiting for it
+	s.After(Entry{Message: "late"}, nil)
+	if _, ok := <-s.ch; ok {
+		t.Error("entry sent after close")
+	}
+}
@@ -0,0 +1,446 @@
+package gologger
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithSyslogTag returns a child logger whose entries go to syslog with tag
+// as their tag, the APP-NAME of RFC 5424, instead of SyslogTag, and to
+// journald with it as SYSLOG_IDENTIFIER, so the components of one binary
+// show up as programs of their own in the system log. On Windows it is the
+// Event Log source, see InstallEventSource.
+func (l *Log) WithSyslogTag(tag string) *Log {
+	c := l.child(nil)
+	c.syslogTag = tag
+	return c
+}
+
+// syslogWriter is implemented by *syslog.Writer, the Windows Event Log and the network client below.
+type syslogWriter interface {
+	Write(b []byte) (int, error)
+	Emerg(m string) error
+	Alert(m string) error
+	Crit(m string) error
+	Err(m string) error
+	Warning(m string) error
+	Notice(m string) error
+	Info(m string) error
+	Debug(m string) error
+	Close() error
+}
+
+const (
+	severityMask = 0x07
+	facilityMask = 0xf8
+)
+
+// writeSyslog sends message with the severity matching its level, so filtering on the server works.
+// Custom levels keep theirs, as on the structured path and in journald.
+func writeSyslog(w syslogWriter, level byte, message string) error {
+	switch levelSeverity(level) {
+	case LOG_EMERG:
+		return w.Emerg(message)
+	case LOG_ALERT:
+		return w.Alert(message)
+	case LOG_CRIT:
+		return w.Crit(message)
+	case LOG_ERR:
+		return w.Err(message)
+	case LOG_WARNING:
+		return w.Warning(message)
+	case LOG_NOTICE:
+		return w.Notice(message)
+	case LOG_INFO:
+		return w.Info(message)
+	default:
+		return w.Debug(message)
+	}
+}
+
+// syslogSink connects on first use with the Syslog* settings of its logger.
+// When the connection breaks it holds up to SyslogBufferSize entries and
+// redials with backoff on later writes and flushes. Held entries aren't
+// errors, so a FallbackSink doesn't write them too; only dropped ones are.
+type syslogSink struct {
+	l      *Log
+	writer syslogWriter
+	tagged map[string]syslogWriter // of WithSyslogTag, dialed on first use
+
+	held    []Entry // waiting for the connection, oldest first
+	dropped int     // entries held beyond SyslogBufferSize since the last report
+	cause   error   // why the connection last broke
+	backoff time.Duration
+	retryAt time.Time
+}
+
+const (
+	syslogMinBackoff = 100 * time.Millisecond
+	syslogMaxBackoff = 30 * time.Second
+)
+
+func (s *syslogSink) Write(entry Entry) error {
+	if s.writer != nil && len(s.held) == 0 {
+		err := s.send(entry)
+		if err == nil {
+			return nil
+		}
+		s.disconnected(err)
+		s.hold(entry)
+		return s.takeDropped()
+	}
+
+	s.hold(entry)
+	return s.drain()
+}
+
+// Flush sends the held entries if it is time to redial.
+func (s *syslogSink) Flush() error {
+	if len(s.held) == 0 {
+		return nil
+	}
+	return s.drain()
+}
+
+// drain connects unless still backing off and sends the held entries.
+func (s *syslogSink) drain() error {
+	if s.writer == nil {
+		if time.Now().Before(s.retryAt) {
+			return s.takeDropped()
+		}
+		writer, err := s.l.dialSyslog(s.l.SyslogTag)
+		if err != nil {
+			s.disconnected(fmt.Errorf("creating syslog: %w", err))
+			return s.takeDropped()
+		}
+		s.writer = writer
+		s.backoff = 0
+		s.l.counters.syslogDown.Store(false)
+	}
+
+	for i, entry := range s.held {
+		err := s.send(entry)
+		if err != nil {
+			s.held = append(s.held[:0], s.held[i:]...)
+			s.l.counters.syslogHeld.Store(int64(len(s.held)))
+			s.disconnected(err)
+			return s.takeDropped()
+		}
+	}
+	clear(s.held)
+	s.held = s.held[:0]
+	s.l.counters.syslogHeld.Store(0)
+	return s.takeDropped()
+}
+
+// hold keeps entry for when syslog is back, dropping the oldest beyond SyslogBufferSize.
+func (s *syslogSink) hold(entry Entry) {
+	limit := s.l.SyslogBufferSize
+	if limit == 0 {
+		limit = 1000
+	}
+	s.held = append(s.held, entry)
+	if n := len(s.held) - max(limit, 0); n > 0 {
+		s.held = append(s.held[:0], s.held[n:]...)
+		s.dropped += n
+	}
+	s.l.counters.syslogHeld.Store(int64(len(s.held)))
+}
+
+func (s *syslogSink) takeDropped() error {
+	if s.dropped == 0 {
+		return nil
+	}
+	err := fmt.Errorf("syslog unreachable, dropped %d entries: %w", s.dropped, s.cause)
+	s.dropped = 0
+	return err
+}
+
+// disconnected closes the connection broken by cause and schedules the next
+// attempt.
+func (s *syslogSink) disconnected(cause error) {
+	s.cause = cause
+	s.closeWriters()
+	s.backoff = min(max(2*s.backoff, syslogMinBackoff), syslogMaxBackoff)
+	s.retryAt = time.Now().Add(s.backoff)
+	s.l.counters.syslogDown.Store(true)
+}
+
+// redial closes the connection, the next write dials with the current settings.
+func (s *syslogSink) redial() {
+	s.closeWriters()
+	s.backoff, s.retryAt = 0, time.Time{}
+}
+
+// closeWriters closes the connection and those of the tags of WithSyslogTag.
+func (s *syslogSink) closeWriters() error {
+	var err error
+	if s.writer != nil {
+		err = s.writer.Close()
+		s.writer = nil
+	}
+	for tag, w := range s.tagged {
+		err = errors.Join(err, w.Close())
+		delete(s.tagged, tag)
+	}
+	return err
+}
+
+// send writes entry with the current connection.
+func (s *syslogSink) send(entry Entry) error {
+	tag := entry.SyslogTag
+	if tag == s.l.SyslogTag {
+		tag = ""
+	}
+	nw, ok := s.writer.(*netSyslogWriter)
+	if ok && s.l.SyslogStructured {
+		err := nw.writeStructured(levelSeverity(entry.Level), entry.Time, tag, entry.Logger,
+			string(appendStructuredData(nil, s.l.SyslogSDID, entry.Fields)), s.structuredMessage(entry))
+		if err != nil {
+			return fmt.Errorf("writing to syslog: %w", err)
+		}
+		return nil
+	}
+
+	var message string
+	if s.l.SyslogEncoder != nil {
+		message = strings.TrimSuffix(string(s.l.SyslogEncoder.Encode(nil, entry)), "\n")
+	} else {
+		message = string(entry.appendText(nil, s.l.SyslogMultiline))
+	}
+	var err error
+	switch {
+	case tag == "":
+		err = writeSyslog(s.writer, entry.Level, message)
+	case ok:
+		// the APP-NAME is per message, one connection does for every tag
+		err = nw.writeStructured(levelSeverity(entry.Level), entry.Time, tag, "", "-", message)
+	default:
+		// log/syslog and the Event Log take the tag when dialing
+		w := s.tagged[tag]
+		if w == nil {
+			w, err = s.l.dialSyslog(tag)
+			if err != nil {
+				return fmt.Errorf("creating syslog for tag %q: %w", tag, err)
+			}
+			if s.tagged == nil {
+				s.tagged = make(map[string]syslogWriter)
+			}
+			s.tagged[tag] = w
+		}
+		err = writeSyslog(w, entry.Level, message)
+	}
+	if err != nil {
+		return fmt.Errorf("writing to syslog: %w", err)
+	}
+	return nil
+}
+
+// structuredMessage is the MSG part of an RFC 5424 message, the fields going
+// in the structured data instead.
+func (s *syslogSink) structuredMessage(entry Entry) string {
+	if s.l.SyslogEncoder != nil {
+		return strings.TrimSuffix(string(s.l.SyslogEncoder.Encode(nil, entry)), "\n")
+	}
+	entry.Logger, entry.Fields = "", nil
+	return strings.TrimSuffix(string(entry.appendText(nil, s.l.SyslogMultiline)), "\n")
+}
+
+// appendStructuredData renders fields as one RFC 5424 SD-ELEMENT, "-" without fields.
+func appendStructuredData(buf []byte, id string, fields []Field) []byte {
+	if len(fields) == 0 {
+		return append(buf, '-')
+	}
+	if id == "" {
+		id = "fields@32473"
+	}
+	buf = append(buf, '[')
+	buf = appendSDName(buf, id)
+	for _, f := range fields {
+		buf = append(buf, ' ')
+		buf = appendSDName(buf, f.Key)
+		buf = append(buf, `="`...)
+		value := string(appendFieldValue(nil, f.Value))
+		if s, ok := f.Value.(string); ok {
+			value = s // unquoted, the param value has its own quotes
+		}
+		buf = append(buf, sdValueEscaper.Replace(value)...)
+		buf = append(buf, '"')
+	}
+	return append(buf, ']')
+}
+
+var sdValueEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
+
+// appendSDName writes name as an SD-NAME, printable ASCII up to 32
+// characters without '=', ' ', ']' and '"', replacing the others with '_'.
+func appendSDName(buf []byte, name string) []byte {
+	if name == "" {
+		name = "_"
+	}
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c <= ' ' || c >= 0x7f || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
+
+func (s *syslogSink) Close() error {
+	var err error
+	if len(s.held) > 0 {
+		s.retryAt = time.Time{}
+		err = s.drain()
+	}
+	return errors.Join(err, s.closeWriters())
+}
+
+// netSyslogWriter sends RFC 5424 messages, over TCP and TLS with RFC 5425
+// octet-counting framing. log/syslog has no TLS support, no structured data
+// and doesn't exist on Windows.
+type netSyslogWriter struct {
+	network  string // "udp", "tcp", "tls" or "unixgram" for the local daemon
+	addr     string
+	config   *tls.Config
+	priority Priority
+	tag      string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func dialNetSyslog(network, addr string, config *tls.Config, priority Priority, tag string) (*netSyslogWriter, error) {
+	if network == "tcp+tls" {
+		network = "tls"
+	}
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	w := &netSyslogWriter{
+		network:  network,
+		addr:     addr,
+		config:   config,
+		priority: priority,
+		tag:      tag,
+		hostname: hostname,
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.connect()
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *netSyslogWriter) connect() error {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if w.network == "tls" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", w.addr, w.config)
+	} else {
+		conn, err = dialer.Dial(w.network, w.addr)
+	}
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *netSyslogWriter) Write(b []byte) (int, error) {
+	return len(b), w.writeAndRetry(w.priority&severityMask, string(b))
+}
+
+func (w *netSyslogWriter) Emerg(m string) error   { return w.writeAndRetry(LOG_EMERG, m) }
+func (w *netSyslogWriter) Alert(m string) error   { return w.writeAndRetry(LOG_ALERT, m) }
+func (w *netSyslogWriter) Crit(m string) error    { return w.writeAndRetry(LOG_CRIT, m) }
+func (w *netSyslogWriter) Err(m string) error     { return w.writeAndRetry(LOG_ERR, m) }
+func (w *netSyslogWriter) Warning(m string) error { return w.writeAndRetry(LOG_WARNING, m) }
+func (w *netSyslogWriter) Notice(m string) error  { return w.writeAndRetry(LOG_NOTICE, m) }
+func (w *netSyslogWriter) Info(m string) error    { return w.writeAndRetry(LOG_INFO, m) }
+func (w *netSyslogWriter) Debug(m string) error   { return w.writeAndRetry(LOG_DEBUG, m) }
+
+func (w *netSyslogWriter) writeAndRetry(severity Priority, msg string) error {
+	return w.writeStructured(severity, time.Now(), "", "", "-", msg)
+}
+
+// RFC 5424 allows at most microseconds
+const syslogTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// writeStructured sends an RFC 5424 message with the APP-NAME tag (the
+// writer's if empty), msgid ("-" if empty) and the structured data sd,
+// reconnecting once if that fails.
+func (w *netSyslogWriter) writeStructured(severity Priority, t time.Time, tag, msgid, sd, msg string) error {
+	p := (w.priority & facilityMask) | (severity & severityMask)
+	if tag == "" {
+		tag = w.tag
+	}
+	if msgid == "" {
+		msgid = "-"
+	}
+	frame := fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		p, t.Format(syslogTimeFormat), w.hostname, tag, os.Getpid(), string(appendSDName(nil, msgid)), sd, strings.TrimSuffix(msg, "\n"))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		err := w.write(frame)
+		if err == nil {
+			return nil
+		}
+	}
+	err := w.connect()
+	if err != nil {
+		return err
+	}
+	return w.write(frame)
+}
+
+func (w *netSyslogWriter) write(frame string) error {
+	if w.network == "udp" || w.network == "unixgram" {
+		_, err := io.WriteString(w.conn, frame)
+		return err
+	}
+	_, err := fmt.Fprintf(w.conn, "%d %s", len(frame), frame)
+	return err
+}
+
+func (w *netSyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
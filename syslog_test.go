@@ -0,0 +1,123 @@
+package gologger
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSyslogWriter records the severity and message of every write, or fails
+// them with err.
+type fakeSyslogWriter struct {
+	writes []string
+	err    error
+}
+
+func (w *fakeSyslogWriter) record(severity, m string) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.writes = append(w.writes, severity+" "+m)
+	return nil
+}
+
+func (w *fakeSyslogWriter) Write(b []byte) (int, error) { return len(b), w.record("write", string(b)) }
+func (w *fakeSyslogWriter) Emerg(m string) error        { return w.record("emerg", m) }
+func (w *fakeSyslogWriter) Alert(m string) error        { return w.record("alert", m) }
+func (w *fakeSyslogWriter) Crit(m string) error         { return w.record("crit", m) }
+func (w *fakeSyslogWriter) Err(m string) error          { return w.record("err", m) }
+func (w *fakeSyslogWriter) Warning(m string) error      { return w.record("warning", m) }
+func (w *fakeSyslogWriter) Notice(m string) error       { return w.record("notice", m) }
+func (w *fakeSyslogWriter) Info(m string) error         { return w.record("info", m) }
+func (w *fakeSyslogWriter) Debug(m string) error        { return w.record("debug", m) }
+func (w *fakeSyslogWriter) Close() error                { return nil }
+
+func TestWriteSyslogSeverity(t *testing.T) {
+	for _, tt := range []struct {
+		level byte
+		want  string
+	}{
+		{'F', "crit"},
+		{'E', "err"},
+		{'W', "warning"},
+		{AuditLevel, "notice"},
+		{'I', "info"},
+		{'D', "debug"},
+		{'T', "debug"},
+	} {
+		w := &fakeSyslogWriter{}
+		if err := writeSyslog(w, tt.level, "m"); err != nil || w.writes[0] != tt.want+" m" {
+			t.Errorf("level %c written as %v, want %s", tt.level, w.writes, tt.want)
+		}
+	}
+}
+
+func TestSyslogSinkHeldNotErrors(t *testing.T) {
+	l := newLog()
+	l.SyslogBufferSize = 1
+	s := &syslogSink{l: l, writer: &fakeSyslogWriter{err: errors.New("connection reset")}}
+
+	// held for the redial, backing off meanwhile
+	if err := s.Write(Entry{Level: 'I', Message: "first"}); err != nil {
+		t.Errorf("held entry reported as %v", err)
+	}
+	err := s.Write(Entry{Level: 'I', Message: "second"})
+	if err == nil || !strings.Contains(err.Error(), "dropped 1 entries: writing to syslog: connection reset") {
+		t.Errorf("dropped entry reported as %v", err)
+	}
+	if len(s.held) != 1 || s.held[0].Message != "second" {
+		t.Errorf("held %v, want the second entry", s.held)
+	}
+}
+
+// recordConn records what is written to it.
+type recordConn struct {
+	net.Conn
+	frames []string
+}
+
+func (c *recordConn) Write(b []byte) (int, error) {
+	c.frames = append(c.frames, string(b))
+	return len(b), nil
+}
+
+func TestSyslogTagAppName(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	entry := Entry{Time: at, Level: 'I', Message: "served", SyslogTag: "web"}
+	want := "<6>1 2024-06-01T12:00:00.000000Z host web "
+
+	for _, structured := range []bool{true, false} {
+		l := newLog()
+		l.SyslogTag, l.SyslogStructured = "prog", structured
+		conn := &recordConn{}
+		s := &syslogSink{l: l, writer: &netSyslogWriter{network: "udp", tag: "prog", hostname: "host", conn: conn}}
+
+		if err := s.Write(entry); err != nil {
+			t.Fatal(err)
+		}
+		if len(conn.frames) != 1 || !strings.HasPrefix(conn.frames[0], want) {
+			t.Errorf("structured %v: sent %q, want it to start with %q", structured, conn.frames, want)
+		}
+	}
+}
+
+func TestSyslogTagDialed(t *testing.T) {
+	l := newLog()
+	l.SyslogTag = "prog"
+	untagged, tagged := &fakeSyslogWriter{}, &fakeSyslogWriter{}
+	s := &syslogSink{l: l, writer: untagged, tagged: map[string]syslogWriter{"web": tagged}}
+
+	for _, tag := range []string{"web", "", "prog"} {
+		if err := s.Write(Entry{Level: 'W', Message: "tag " + tag, SyslogTag: tag}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(tagged.writes) != 1 || !strings.Contains(tagged.writes[0], "tag web") {
+		t.Errorf("written with the tag: %q", tagged.writes)
+	}
+	if len(untagged.writes) != 2 || !strings.HasPrefix(untagged.writes[0], "warning ") {
+		t.Errorf("written without: %q", untagged.writes)
+	}
+}
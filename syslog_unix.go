@@ -0,0 +1,43 @@
+//go:build !windows
+
+package gologger
+
+import (
+	"errors"
+	"log/syslog"
+	"os"
+)
+
+func (l *Log) dialSyslog(tag string) (syslogWriter, error) {
+	priority := (l.SyslogFacility & facilityMask) | LOG_INFO
+
+	switch {
+	case l.SyslogStructured && l.SyslogNetwork == "":
+		addr, err := localSyslogSocket()
+		if err != nil {
+			return nil, err
+		}
+		return dialNetSyslog("unixgram", addr, nil, priority, tag)
+	case l.SyslogStructured:
+		return dialNetSyslog(l.SyslogNetwork, l.SyslogAddr, l.SyslogTLSConfig, priority, tag)
+	}
+
+	switch l.SyslogNetwork {
+	case "":
+		return syslog.New(priority, tag)
+	case "tls", "tcp+tls":
+		return dialNetSyslog(l.SyslogNetwork, l.SyslogAddr, l.SyslogTLSConfig, priority, tag)
+	default:
+		return syslog.Dial(l.SyslogNetwork, l.SyslogAddr, priority, tag)
+	}
+}
+
+// localSyslogSocket finds the local daemon's socket where log/syslog looks for it.
+func localSyslogSocket() (string, error) {
+	for _, path := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", errors.New("no local syslog socket found")
+}
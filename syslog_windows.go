@@ -0,0 +1,62 @@
+package gologger
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// Event IDs reported to the Event Log, one per severity.
+const (
+	eventIDError   = 1
+	eventIDWarning = 2
+	eventIDInfo    = 3
+)
+
+// The local "syslog" on Windows is the Event Log, with tag, SyslogTag or that
+// of WithSyslogTag, as the event source.
+func (l *Log) dialSyslog(tag string) (syslogWriter, error) {
+	if l.SyslogNetwork != "" {
+		priority := (l.SyslogFacility & facilityMask) | LOG_INFO
+		return dialNetSyslog(l.SyslogNetwork, l.SyslogAddr, l.SyslogTLSConfig, priority, tag)
+	}
+
+	source := tag
+	if source == "" {
+		source = filepath.Base(os.Args[0])
+	}
+	el, err := eventlog.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	return &eventLogWriter{el}, nil
+}
+
+// InstallEventSource registers source (usually SyslogTag) with the Event Log so
+// its messages render without "description not found" noise. Needs administrator rights.
+func InstallEventSource(source string) error {
+	return eventlog.InstallAsEventCreate(source, eventlog.Error|eventlog.Warning|eventlog.Info)
+}
+
+// eventLogWriter maps syslog severities onto the three Event Log types.
+type eventLogWriter struct {
+	el *eventlog.Log
+}
+
+func (w *eventLogWriter) Write(b []byte) (int, error) {
+	return len(b), w.el.Info(eventIDInfo, string(b))
+}
+
+func (w *eventLogWriter) Emerg(m string) error   { return w.el.Error(eventIDError, m) }
+func (w *eventLogWriter) Alert(m string) error   { return w.el.Error(eventIDError, m) }
+func (w *eventLogWriter) Crit(m string) error    { return w.el.Error(eventIDError, m) }
+func (w *eventLogWriter) Err(m string) error     { return w.el.Error(eventIDError, m) }
+func (w *eventLogWriter) Warning(m string) error { return w.el.Warning(eventIDWarning, m) }
+func (w *eventLogWriter) Notice(m string) error  { return w.el.Info(eventIDInfo, m) }
+func (w *eventLogWriter) Info(m string) error    { return w.el.Info(eventIDInfo, m) }
+func (w *eventLogWriter) Debug(m string) error   { return w.el.Info(eventIDInfo, m) }
+
+func (w *eventLogWriter) Close() error {
+	return w.el.Close()
+}
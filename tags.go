@@ -0,0 +1,43 @@
+package gologger
+
+// Tagged returns a child logger tagging its entries for routing, e.g.
+// l.Tagged("billing").INF(...) to send business events to a sink wrapped in
+// AcceptTags. Tags aren't written, they only decide where entries go.
+func (l *Log) Tagged(tags ...string) *Log {
+	c := l.child(nil)
+	c.tags = make([]string, 0, len(l.tags)+len(tags))
+	c.tags = append(c.tags, l.tags...)
+	c.tags = append(c.tags, tags...)
+	return c
+}
+
+// HasTag reports whether the entry was logged by a Tagged logger with tag.
+func (e Entry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AcceptTags returns a sink writing only the entries tagged with one of tags
+// to s, e.g. a file just for billing events.
+func AcceptTags(s Sink, tags ...string) Sink {
+	return WithFilter(s, func(entry Entry) bool { return hasAnyTag(entry, tags) })
+}
+
+// RejectTags returns a sink writing the entries tagged with none of tags to
+// s, e.g. to keep audit events out of the general log.
+func RejectTags(s Sink, tags ...string) Sink {
+	return WithFilter(s, func(entry Entry) bool { return !hasAnyTag(entry, tags) })
+}
+
+func hasAnyTag(entry Entry, tags []string) bool {
+	for _, tag := range tags {
+		if entry.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
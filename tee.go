@@ -0,0 +1,93 @@
+package gologger
+
+import "errors"
+
+// Tee returns a sink writing every entry to all of sinks, for routing trees
+// built at setup, e.g.
+//
+//	l.AddSink(gologger.Tee(fileSink, gologger.FilteredSink(lokiSink, gologger.LOG_WARNING)))
+func Tee(sinks ...Sink) Sink {
+	return &teeSink{sinks: sinks}
+}
+
+// FilteredSink returns a sink writing the entries at priority minLevel or
+// more severe to s, e.g. FilteredSink(s, LOG_WARNING) for W, E and F.
+func FilteredSink(s Sink, minLevel Priority) Sink {
+	return WithFilter(s, func(entry Entry) bool {
+		return levelPriority(entry.Level) <= minLevel
+	})
+}
+
+// teeSink is a struct rather than the slice so sinks can be compared, e.g.
+// by RemoveSink.
+type teeSink struct {
+	sinks []Sink
+}
+
+func (t *teeSink) Write(entry Entry) error {
+	var errs []error
+	for _, s := range t.sinks {
+		errs = append(errs, s.Write(entry))
+	}
+	return errors.Join(errs...)
+}
+
+func (t *teeSink) WriteBatch(entries []Entry) error {
+	var errs []error
+	for _, s := range t.sinks {
+		if bw, ok := s.(BatchWriter); ok {
+			errs = append(errs, bw.WriteBatch(entries))
+			continue
+		}
+		for _, entry := range entries {
+			errs = append(errs, s.Write(entry))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *teeSink) Flush() error {
+	var errs []error
+	for _, s := range t.sinks {
+		if flusher, ok := s.(Flusher); ok {
+			errs = append(errs, flusher.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *teeSink) startFlush() error {
+	var errs []error
+	for _, s := range t.sinks {
+		errs = append(errs, startFlush(s))
+	}
+	return errors.Join(errs...)
+}
+
+func (t *teeSink) Sync() error {
+	var errs []error
+	for _, s := range t.sinks {
+		if syncer, ok := s.(Syncer); ok {
+			errs = append(errs, syncer.Sync())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *teeSink) Reopen() error {
+	var errs []error
+	for _, s := range t.sinks {
+		if reopener, ok := s.(Reopener); ok {
+			errs = append(errs, reopener.Reopen())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *teeSink) Close() error {
+	var errs []error
+	for _, s := range t.sinks {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,115 @@
+package gologger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Fields passed as the only argument after the message make it a template:
+// l.INF("user {user} logged in from {ip}", Fields{"user": u, "ip": ip}).
+// Text sinks show the message with the placeholders filled in, encoders
+// like JSONEncoder get it as well and every value as a field of its own.
+// "{{" and "}}" stand for literal braces, a placeholder without a value is
+// left as it is.
+type Fields map[string]interface{}
+
+// templateFields returns the Fields of a log call's arguments, if they are just that.
+func templateFields(v []interface{}) (Fields, bool) {
+	if len(v) != 1 {
+		return nil, false
+	}
+	fields, ok := v[0].(Fields)
+	return fields, ok
+}
+
+// formatMessage renders a log call's message, printf style or as a template.
+func formatMessage(prompt string, v []interface{}) (message, template string, fields []Field) {
+	if values, ok := templateFields(v); ok {
+		message, fields = renderTemplate(prompt, values)
+		return message, prompt, fields
+	}
+	if v != nil {
+		prompt = fmt.Sprintf(prompt, v...)
+	}
+	return prompt, "", nil
+}
+
+// renderTemplate fills the placeholders of template in with values and returns
+// them as fields, in the order they appear, followed by the unused ones by key.
+func renderTemplate(template string, values Fields) (string, []Field) {
+	var b strings.Builder
+	fields := make([]Field, 0, len(values))
+	used := make(map[string]bool, len(values))
+	for s := template; s != ""; {
+		i := strings.IndexAny(s, "{}")
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+		if strings.HasPrefix(s, "{{") || strings.HasPrefix(s, "}}") {
+			b.WriteByte(s[0])
+			s = s[2:]
+			continue
+		}
+		end := strings.IndexByte(s, '}')
+		if s[0] == '}' || end < 0 {
+			b.WriteByte(s[0])
+			s = s[1:]
+			continue
+		}
+
+		key := s[1:end]
+		value, ok := values[key]
+		if !ok {
+			b.WriteString(s[:end+1])
+		} else {
+			if str, isString := value.(string); isString {
+				b.WriteString(str)
+			} else {
+				fmt.Fprint(&b, value)
+			}
+			if !used[key] {
+				used[key] = true
+				fields = append(fields, Field{key, value})
+			}
+		}
+		s = s[end+1:]
+	}
+
+	start := len(fields)
+	for key, value := range values {
+		if !used[key] {
+			fields = append(fields, Field{key, value})
+		}
+	}
+	rest := fields[start:]
+	sort.Slice(rest, func(i, j int) bool { return rest[i].Key < rest[j].Key })
+	return b.String(), fields
+}
+
+// textFields returns the fields text renders after the message, leaving out
+// those already filled into it.
+func (e *Entry) textFields() []Field {
+	if e.Template == "" {
+		return e.Fields
+	}
+	fields := make([]Field, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		if !strings.Contains(e.Template, "{"+f.Key+"}") {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// logTemplate is Log for a message that may be a template, with the caller
+// at the same depth.
+func (l *Log) logTemplate(level byte, prompt string, v []interface{}) {
+	pc, line := l.caller(2)
+	entry := Entry{Level: level, Line: line}
+	entry.Message, entry.Template, entry.Fields = formatMessage(prompt, v)
+	l.outputEntry(pc, entry)
+}
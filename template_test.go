@@ -0,0 +1,49 @@
+package gologger
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		values   Fields
+		want     string
+		fields   []Field
+	}{
+		{"user {user} logged in from {ip}", Fields{"ip": "10.0.0.1", "user": "bob"},
+			"user bob logged in from 10.0.0.1", []Field{{"user", "bob"}, {"ip", "10.0.0.1"}}},
+		{"{n} of {n} done", Fields{"n": 3}, "3 of 3 done", []Field{{"n", 3}}},
+		// unused values follow, by key
+		{"retrying {op}", Fields{"op": "read", "try": 2, "after": "1s"},
+			"retrying read", []Field{{"op", "read"}, {"after", "1s"}, {"try", 2}}},
+		// placeholders without a value and stray braces stay
+		{"{missing} {op", Fields{"op": "x"}, "{missing} {op", []Field{{"op", "x"}}},
+		{"{{op}} is {op}}", Fields{"op": "x"}, "{op} is x}", []Field{{"op", "x"}}},
+		{"", Fields{}, "", []Field{}},
+	}
+	for _, tt := range tests {
+		got, fields := renderTemplate(tt.template, tt.values)
+		if got != tt.want || !reflect.DeepEqual(fields, tt.fields) {
+			t.Errorf("renderTemplate(%q) = %q, %v, want %q, %v", tt.template, got, fields, tt.want, tt.fields)
+		}
+	}
+}
+
+func TestTemplateText(t *testing.T) {
+	message, template, fields := formatMessage("user {user} failed", []interface{}{Fields{"user": "bob", "attempt": 2}})
+	e := Entry{Level: 'W', Message: message, Template: template, Fields: fields}
+	if got, want := e.Text(), "|W| user bob failed attempt=2\n"; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	if got, want := string(JSONEncoder{}.Encode(nil, e)), `"user":"bob","attempt":2`; !strings.Contains(got, want) {
+		t.Errorf("JSON = %s, want it to hold %s", got, want)
+	}
+
+	// anything else is printf
+	if message, template, _ := formatMessage("%d%%", []interface{}{5}); message != "5%" || template != "" {
+		t.Errorf("formatMessage printf = %q, %q", message, template)
+	}
+}
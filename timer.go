@@ -0,0 +1,52 @@
+package gologger
+
+import "time"
+
+// SetTimerLevel sets the level Timed and Since log at, 'D' by default.
+func (l *Log) SetTimerLevel(level byte) {
+	l.core().timerLevel.Store(uint32(level))
+}
+
+func (l *Log) timerEnabled() (byte, bool) {
+	level := byte(l.core().timerLevel.Load())
+	if level == 0 {
+		level = 'D'
+	}
+	return level, alwaysLogged(level) || l.enabled(levelPriority(level))
+}
+
+// Timed logs how long it was until the returned function is called, e.g.
+//
+//	defer l.Timed("load config %s", path)()
+//
+// as "load config app.yaml took 1.204s", with the duration in the elapsed field.
+func (l *Log) Timed(prompt string, v ...interface{}) func() {
+	level, ok := l.timerEnabled()
+	if !ok {
+		return func() {}
+	}
+	start := l.now()
+	pc, line := l.caller(1)
+	return func() {
+		l.logElapsed(pc, line, level, l.now().Sub(start), prompt, v)
+	}
+}
+
+// Since logs the time passed since start like Timed, e.g.
+//
+//	l.Since(start, "query %s", name)
+func (l *Log) Since(start time.Time, prompt string, v ...interface{}) {
+	elapsed := l.now().Sub(start)
+	level, ok := l.timerEnabled()
+	if !ok {
+		return
+	}
+	pc, line := l.caller(1)
+	l.logElapsed(pc, line, level, elapsed, prompt, v)
+}
+
+func (l *Log) logElapsed(pc uintptr, line int, level byte, elapsed time.Duration, prompt string, v []interface{}) {
+	message, template, fields := formatMessage(prompt, v)
+	fields = append(fields, Field{"elapsed", elapsed})
+	l.outputEntry(pc, Entry{Level: level, Line: line, Message: message + " took " + elapsed.String(), Template: template, Fields: fields})
+}
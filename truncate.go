@@ -0,0 +1,71 @@
+package gologger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"unicode/utf8"
+)
+
+// SetMaxMessageSize sets the longest message or string field value in bytes,
+// 0 for no limit. Longer ones are cut and marked, so one accidental dump
+// doesn't break downstream parsers. withHash adds a hash of the cut off part
+// to the marker.
+func (l *Log) SetMaxMessageSize(limit int, withHash bool) {
+	l = l.core()
+	l.truncateHash.Store(withHash)
+	l.maxMessageSize.Store(int64(limit))
+}
+
+// MaxMessageSize returns the limit set by SetMaxMessageSize.
+func (l *Log) MaxMessageSize() int {
+	return int(l.core().maxMessageSize.Load())
+}
+
+// truncate cuts s to limit bytes, on a rune boundary, and appends a marker
+// saying how much was dropped, with withHash a short SHA-256 of the dropped
+// part to tell apart payloads that start alike.
+func truncate(s string, limit int, withHash bool) string {
+	if limit <= 0 || len(s) <= limit {
+		return s
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	dropped := s[cut:]
+
+	b := make([]byte, 0, cut+64)
+	b = append(b, s[:cut]...)
+	b = append(b, "... [truncated "...)
+	b = strconv.AppendInt(b, int64(len(dropped)), 10)
+	b = append(b, " bytes"...)
+	if withHash {
+		sum := sha256.Sum256([]byte(dropped))
+		b = append(b, ", sha256 "...)
+		b = append(b, hex.EncodeToString(sum[:8])...)
+	}
+	b = append(b, ']')
+	return string(b)
+}
+
+// truncateEntry applies SetMaxMessageSize to the message and string field values.
+func (l *Log) truncateEntry(entry *Entry) {
+	limit, withHash := int(l.maxMessageSize.Load()), l.truncateHash.Load()
+	entry.Message = truncate(entry.Message, limit, withHash)
+
+	copied := false
+	for i, f := range entry.Fields {
+		s, ok := f.Value.(string)
+		if !ok || len(s) <= limit {
+			continue
+		}
+		if !copied {
+			// the fields may be the logger's own, shared by every entry
+			entry.Fields = append([]Field(nil), entry.Fields...)
+			copied = true
+		}
+		entry.Fields[i].Value = truncate(s, limit, withHash)
+	}
+}
@@ -0,0 +1,67 @@
+package gologger
+
+import "context"
+
+// given up on right away by the Try calls
+var closedDone = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// TryERR is ERR giving up on the entry, counting it in Stats.Dropped, rather
+// than waiting for room when the queue is full, whatever the OverflowPolicy,
+// for request paths that must never stall on logging. Fatal and Always
+// entries wait regardless.
+func (l *Log) TryERR(e interface{}, prompt string, v ...interface{}) {
+	if !l.enabled(LOG_ERR) {
+		return
+	}
+	message, template, fields := formatMessage(prompt, v)
+
+	err, _ := e.(error)
+	pc, line := l.caller(1)
+	l.outputEntry(pc, Entry{Level: 'E', Line: line, Message: l.anyErrToString(e, message), Template: template, Fields: fields, Err: err, done: closedDone})
+}
+
+// TryWRN is WRN giving up on a full queue, see TryERR.
+func (l *Log) TryWRN(prompt string, v ...interface{}) {
+	if !l.enabled(LOG_WARNING) {
+		return
+	}
+	l.logUntil('W', closedDone, prompt, v)
+}
+
+// TryINF is INF giving up on a full queue, see TryERR.
+func (l *Log) TryINF(prompt string, v ...interface{}) {
+	if !l.enabled(LOG_INFO) {
+		return
+	}
+	l.logUntil('I', closedDone, prompt, v)
+}
+
+// TryDBG is DBG giving up on a full queue, see TryERR.
+func (l *Log) TryDBG(prompt string, v ...interface{}) {
+	if !l.enabled(LOG_DEBUG) {
+		return
+	}
+	l.logUntil('D', closedDone, prompt, v)
+}
+
+// LogWithTimeout is Logf waiting for room on a full queue only until ctx is
+// done, e.g. the deadline of the request being served, then giving up on the
+// entry as TryERR does.
+func (l *Log) LogWithTimeout(ctx context.Context, level byte, prompt string, v ...interface{}) {
+	if !alwaysLogged(level) && !l.enabled(levelPriority(level)) {
+		return
+	}
+	l.logUntil(level, ctx.Done(), prompt, v)
+}
+
+// logUntil is logTemplate giving up on a full queue once done is closed.
+func (l *Log) logUntil(level byte, done <-chan struct{}, prompt string, v []interface{}) {
+	pc, line := l.caller(2)
+	entry := Entry{Level: level, Line: line, done: done}
+	entry.Message, entry.Template, entry.Fields = formatMessage(prompt, v)
+	l.outputEntry(pc, entry)
+}
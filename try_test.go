@@ -0,0 +1,84 @@
+package gologger
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fullLog returns a logger whose queue and priority lane are full, with no
+// daemon to empty them.
+func fullLog(t *testing.T) *Log {
+	t.Helper()
+	l, _ := newTestLog(t)
+	l.logChan = make(chan logMsg, 1)
+	l.urgent = make(chan logMsg)
+	l.started.Store(true)
+	l.INF("fill")
+	return l
+}
+
+// returns reports whether f returns within a second.
+func returns(f func()) bool {
+	done := make(chan struct{})
+	go func() {
+		f()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+func TestTryFullQueue(t *testing.T) {
+	l := fullLog(t)
+
+	if !returns(func() { l.TryERR(nil, "error") }) || !returns(func() { l.TryINF("info") }) {
+		t.Fatal("Try call waited for room")
+	}
+	if dropped := l.Stats().Dropped; dropped != 2 {
+		t.Errorf("%d dropped, want 2", dropped)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if !returns(func() { l.LogWithTimeout(ctx, 'I', "info") }) {
+		t.Fatal("LogWithTimeout waited past the deadline")
+	}
+	if waited := time.Since(start); waited < 50*time.Millisecond {
+		t.Errorf("LogWithTimeout gave up after %s, before the deadline", waited)
+	}
+	if dropped := l.Stats().Dropped; dropped != 3 {
+		t.Errorf("%d dropped, want 3", dropped)
+	}
+}
+
+func TestTryAlwaysWaits(t *testing.T) {
+	l := fullLog(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		l.LogWithTimeout(ctx, AuditLevel, "audit")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Always entry given up on")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	<-l.logChan
+	<-done
+	if m := <-l.logChan; m.entry.Message != "audit" {
+		t.Errorf("queued %q, want the audit entry", m.entry.Message)
+	}
+	if dropped := l.Stats().Dropped; dropped != 0 {
+		t.Errorf("%d dropped, want 0", dropped)
+	}
+}
@@ -0,0 +1,256 @@
+package gologger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// archiveHTTPClient has no timeout, uploads of large files are bounded by their context.
+var archiveHTTPClient = &http.Client{}
+
+// S3Options configures NewS3Uploader, the zero value works on AWS.
+type S3Options struct {
+	Region string // AWS_REGION or AWS_DEFAULT_REGION if empty
+
+	// "AES256" or "aws:kms" for server-side encryption other than the
+	// bucket's default, with KMSKeyID for a key of your own.
+	ServerSideEncryption string
+	KMSKeyID             string
+	StorageClass         string // e.g. "STANDARD_IA", the bucket's default if empty
+
+	// Credentials overrides the chain of the AWS SDKs: the environment, the
+	// shared credentials file, EKS web identity, ECS and EC2 metadata.
+	Credentials func() (AWSCredentials, error)
+	Endpoint    string // path-style, e.g. http://localhost:9000 for MinIO; https://<bucket>.s3.<region>.amazonaws.com if empty
+
+	Client *http.Client // one without timeout if nil
+}
+
+type s3Uploader struct {
+	url         string // the objects' keys are appended
+	region      string
+	opts        S3Options
+	credentials func() (AWSCredentials, error)
+}
+
+// NewS3Uploader returns an Uploader putting files into an S3 bucket, or one of
+// a service compatible with it, for LogfileArchive.
+func NewS3Uploader(bucket string, opts *S3Options) (Uploader, error) {
+	u := &s3Uploader{}
+	if opts != nil {
+		u.opts = *opts
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	u.region = awsRegion(u.opts.Region)
+	if u.region == "" {
+		return nil, fmt.Errorf("no AWS region, set Region or AWS_REGION")
+	}
+	if u.opts.Client == nil {
+		u.opts.Client = archiveHTTPClient
+	}
+	if u.opts.Endpoint != "" {
+		u.url = strings.TrimSuffix(u.opts.Endpoint, "/") + "/" + url.PathEscape(bucket) + "/"
+	} else {
+		u.url = "https://" + bucket + ".s3." + u.region + ".amazonaws.com/"
+	}
+	u.credentials = u.opts.Credentials
+	if u.credentials == nil {
+		u.credentials = (&awsCredentialChain{client: defaultHTTPClient}).get
+	}
+	return u, nil
+}
+
+func (u *s3Uploader) Upload(ctx context.Context, key string, body io.Reader, size int64) error {
+	creds, err := u.credentials()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.url+escapeKey(key), body)
+	if err != nil {
+		return Permanent(err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", archiveContentType(key))
+	if u.opts.ServerSideEncryption != "" {
+		req.Header.Set("X-Amz-Server-Side-Encryption", u.opts.ServerSideEncryption)
+	}
+	if u.opts.KMSKeyID != "" {
+		req.Header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", u.opts.KMSKeyID)
+	}
+	if u.opts.StorageClass != "" {
+		req.Header.Set("X-Amz-Storage-Class", u.opts.StorageClass)
+	}
+	// streamed rather than read twice for the hash of the body
+	signAWSRequestHash(req, "UNSIGNED-PAYLOAD", creds, u.region, "s3", time.Now())
+
+	resp, err := u.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	return checkHTTPResponse(resp)
+}
+
+// GCSOptions configures NewGCSUploader, the zero value works on GCP.
+type GCSOptions struct {
+	// Service account key file, GOOGLE_APPLICATION_CREDENTIALS if empty. The
+	// metadata server's account is used without one.
+	CredentialsFile string
+
+	KMSKeyName   string // Cloud KMS key encrypting the objects, the bucket's default encryption if empty
+	StorageClass string // e.g. "NEARLINE", the bucket's default if empty
+
+	Endpoint string       // https://storage.googleapis.com if empty
+	Client   *http.Client // one without timeout if nil
+}
+
+type gcsUploader struct {
+	url   string
+	opts  GCSOptions
+	token *gcpTokenSource
+}
+
+// NewGCSUploader returns an Uploader putting files into a Google Cloud Storage
+// bucket, for LogfileArchive.
+func NewGCSUploader(bucket string, opts *GCSOptions) (Uploader, error) {
+	u := &gcsUploader{}
+	if opts != nil {
+		u.opts = *opts
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("GCS bucket is required")
+	}
+	if u.opts.Client == nil {
+		u.opts.Client = archiveHTTPClient
+	}
+	endpoint := u.opts.Endpoint
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	u.url = strings.TrimSuffix(endpoint, "/") + "/upload/storage/v1/b/" + url.PathEscape(bucket) + "/o"
+
+	credentials := u.opts.CredentialsFile
+	if credentials == "" {
+		credentials = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	u.token = &gcpTokenSource{client: defaultHTTPClient, scope: "https://www.googleapis.com/auth/devstorage.read_write"}
+	if credentials != "" {
+		err := u.token.loadServiceAccount(credentials)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+func (u *gcsUploader) Upload(ctx context.Context, key string, body io.Reader, size int64) error {
+	token, err := u.token.get()
+	if err != nil {
+		return err
+	}
+	query := url.Values{"uploadType": {"media"}, "name": {key}}
+	if u.opts.KMSKeyName != "" {
+		query.Set("kmsKeyName", u.opts.KMSKeyName)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.url+"?"+query.Encode(), body)
+	if err != nil {
+		return Permanent(err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", archiveContentType(key))
+	req.Header.Set("Authorization", "Bearer "+token)
+	if u.opts.StorageClass != "" {
+		req.Header.Set("X-Goog-Storage-Class", u.opts.StorageClass)
+	}
+
+	resp, err := u.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		u.token.reset()
+	}
+	return checkHTTPResponse(resp)
+}
+
+// AzureBlobOptions configures NewAzureBlobUploader, the zero value works.
+type AzureBlobOptions struct {
+	EncryptionScope string // of the storage account, its default encryption if empty
+	AccessTier      string // "Hot", "Cool", "Cold" or "Archive", the account's default if empty
+
+	Client *http.Client // one without timeout if nil
+}
+
+type azureBlobUploader struct {
+	container *url.URL
+	opts      AzureBlobOptions
+}
+
+// NewAzureBlobUploader returns an Uploader putting files into an Azure Blob
+// Storage container, for LogfileArchive. containerURL carries a SAS token
+// allowing to create blobs, e.g.
+// "https://account.blob.core.windows.net/logs?sv=...&sp=cw&sig=...".
+func NewAzureBlobUploader(containerURL string, opts *AzureBlobOptions) (Uploader, error) {
+	u := &azureBlobUploader{}
+	if opts != nil {
+		u.opts = *opts
+	}
+	var err error
+	u.container, err = url.Parse(containerURL)
+	if err != nil || u.container.Host == "" {
+		return nil, fmt.Errorf("invalid Azure container URL %q", containerURL)
+	}
+	if u.opts.Client == nil {
+		u.opts.Client = archiveHTTPClient
+	}
+	return u, nil
+}
+
+func (u *azureBlobUploader) Upload(ctx context.Context, key string, body io.Reader, size int64) error {
+	blob := *u.container
+	blob.Path = strings.TrimSuffix(blob.Path, "/") + "/" + key
+	blob.RawPath = ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, blob.String(), body)
+	if err != nil {
+		return Permanent(err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", archiveContentType(key))
+	req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+	req.Header.Set("X-Ms-Version", "2021-12-02")
+	if u.opts.EncryptionScope != "" {
+		req.Header.Set("X-Ms-Encryption-Scope", u.opts.EncryptionScope)
+	}
+	if u.opts.AccessTier != "" {
+		req.Header.Set("X-Ms-Access-Tier", u.opts.AccessTier)
+	}
+
+	resp, err := u.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	return checkHTTPResponse(resp)
+}
+
+// escapeKey escapes an object key for a URL path, keeping its slashes.
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+func archiveContentType(key string) string {
+	if strings.HasSuffix(key, ".gz") {
+		return "application/gzip"
+	}
+	return "text/plain; charset=utf-8"
+}
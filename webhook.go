@@ -0,0 +1,158 @@
+package gologger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// HTTPOptions configures NewHTTPSink, the zero value works.
+type HTTPOptions struct {
+	Format  string  // "json" for a JSON array of entries (the default), "ndjson" or "msgpack"
+	Encoder Encoder // renders each entry, JSONEncoder or MsgpackEncoder if nil. Has to be JSON for "json"
+
+	Headers            map[string]string // added to every request, e.g. an API key
+	BearerToken        string
+	Username, Password string // basic auth
+	Gzip               bool   // short for Compression{Codec: "gzip"}
+
+	// Compression of the request bodies, sent as their Content-Encoding.
+	Compression Compression
+
+	Client *http.Client // one with a 30s timeout if nil
+
+	// Set Batch.SpoolDir to keep the entries on disk while the endpoint is down.
+	Batch BatchOptions
+}
+
+type httpSink struct {
+	*batcher
+
+	url  string
+	opts HTTPOptions
+	zip  *compressor // nil without Compression
+	buf  bytes.Buffer
+	line []byte
+}
+
+// NewHTTPSink returns a sink POSTing batches of entries to rawURL, e.g. a
+// webhook or a collector gologger doesn't ship a sink for.
+func NewHTTPSink(rawURL string, opts *HTTPOptions) (Sink, error) {
+	s := &httpSink{url: rawURL}
+	if opts != nil {
+		s.opts = *opts
+	}
+	switch s.opts.Format {
+	case "":
+		s.opts.Format = "json"
+	case "json", "ndjson":
+	case "msgpack":
+		if s.opts.Encoder == nil {
+			s.opts.Encoder = MsgpackEncoder{}
+		}
+	default:
+		return nil, fmt.Errorf("unknown HTTP sink format %q, expected json, ndjson or msgpack", s.opts.Format)
+	}
+	if s.opts.Encoder == nil {
+		s.opts.Encoder = JSONEncoder{}
+	}
+	if s.opts.Client == nil {
+		s.opts.Client = defaultHTTPClient
+	}
+
+	_, err := url.Parse(s.url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP sink URL: %w", err)
+	}
+	if s.opts.Gzip && s.opts.Compression.Codec == "" {
+		s.opts.Compression.Codec = "gzip"
+	}
+	s.zip, err = newCompressor(s.opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	s.batcher = newBatcher(s.opts.Batch, s.push)
+	return s, nil
+}
+
+func (s *httpSink) push(entries []Entry) error {
+	payload, err := s.payload(entries)
+	if err != nil {
+		return permanentError{err}
+	}
+	return s.post(payload)
+}
+
+// payload renders entries as the request body, compressed with Compression.
+func (s *httpSink) payload(entries []Entry) ([]byte, error) {
+	s.buf.Reset()
+	var w io.Writer = &s.buf
+	var zw io.WriteCloser
+	if s.zip != nil {
+		var err error
+		zw, err = s.zip.writer(&s.buf)
+		if err != nil {
+			return nil, err
+		}
+		w = zw
+	}
+
+	if s.opts.Format == "json" {
+		w.Write([]byte{'['})
+	}
+	for i, entry := range entries {
+		if i > 0 && s.opts.Format == "json" {
+			w.Write([]byte{','})
+		}
+		s.line = s.opts.Encoder.Encode(s.line[:0], entry)
+		if s.opts.Format == "json" {
+			s.line = bytes.TrimSuffix(s.line, []byte{'\n'})
+		}
+		w.Write(s.line)
+	}
+	if s.opts.Format == "json" {
+		w.Write([]byte{']'})
+	}
+	if zw != nil {
+		err := zw.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.buf.Bytes(), nil
+}
+
+func (s *httpSink) post(payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return permanentError{err}
+	}
+	switch s.opts.Format {
+	case "json":
+		req.Header.Set("Content-Type", "application/json")
+	case "msgpack":
+		req.Header.Set("Content-Type", "application/msgpack")
+	default:
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	}
+	if s.zip != nil {
+		req.Header.Set("Content-Encoding", s.zip.c.Codec)
+	}
+	if s.opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.opts.BearerToken)
+	} else if s.opts.Username != "" {
+		req.SetBasicAuth(s.opts.Username, s.opts.Password)
+	}
+	for k, v := range s.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	return checkHTTPResponse(resp)
+}
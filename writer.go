@@ -0,0 +1,77 @@
+package gologger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Lines longer than this are logged in pieces instead of waiting for the newline.
+const maxWriterLine = 64 * 1024
+
+type levelWriter struct {
+	l      *Log
+	level  byte
+	stdlog bool // lines may carry their own level, see StdLogger
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Writer returns an io.Writer logging every line written to it at the given level,
+// e.g. for http.Server.ErrorLog via log.New(l.Writer('E'), "", 0).
+// Incomplete lines are held back until their newline arrives.
+func (l *Log) Writer(level byte) io.Writer {
+	return &levelWriter{l: l, level: level}
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	var pc uintptr
+	var line int
+	if w.stdlog {
+		pc, line = w.l.stdlogCaller()
+	} else {
+		if !w.l.enabled(levelPriority(w.level)) {
+			return len(p), nil
+		}
+
+		// attribute the lines to whoever wrote them, e.g. log.(*Logger).output
+		pc, line = w.l.caller(1)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	rest := w.buf
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			if len(rest) < maxWriterLine {
+				break
+			}
+			i = maxWriterLine
+		}
+
+		text := bytes.TrimRight(rest[:i], "\r")
+		if len(text) > 0 {
+			level, message := w.level, string(text)
+			if w.stdlog {
+				level, message = detectLevel(message, level)
+			}
+			w.l.output(pc, line, level, message, nil)
+		}
+		if i < len(rest) && rest[i] == '\n' {
+			i++
+		}
+		rest = rest[i:]
+	}
+	w.buf = append(w.buf[:0], rest...)
+
+	if w.stdlog {
+		// log.Fatal exits right after, and log.Logger users expect the
+		// line written once Print returns anyway
+		w.l.Flush()
+	}
+	return len(p), nil
+}